@@ -1,24 +1,42 @@
 package main
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 
+	"github.com/NickRI/wallets-task/infrastructure/services"
 	"github.com/NickRI/wallets-task/transport/restapi"
 	"github.com/go-chi/docgen"
 	"github.com/go-kit/kit/log"
 )
 
 const apiInto = `# Wallet service
-This service provide three api endpoints for account and payments actions
+This service provide api endpoints for account and payments actions
 `
 
 func main() {
 	logger := log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
-	routes := restapi.MakeRoutes(nil, logger)
+	routes := restapi.MakeRoutes(nil, new(services.WebhookService), new(services.ReconciliationService), restapi.NewHealth(0), nil, &restapi.Auth{}, logger)
 	doc := docgen.MarkdownRoutesDoc(routes, docgen.MarkdownOpts{ProjectPath: "github.com/NickRI/wallets-task", Intro: apiInto})
 
 	if err := ioutil.WriteFile("./docs/api.md", []byte(doc), os.ModePerm); err != nil {
 		logger.Log("error", err)
 	}
+
+	spec, err := restapi.Spec(routes)
+	if err != nil {
+		logger.Log("error", err)
+		return
+	}
+
+	openapi, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		logger.Log("error", err)
+		return
+	}
+
+	if err := ioutil.WriteFile("./docs/openapi.json", openapi, os.ModePerm); err != nil {
+		logger.Log("error", err)
+	}
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/NickRI/wallets-task/infrastructure/auth"
+	"github.com/NickRI/wallets-task/transport/restapi"
+	"github.com/spf13/viper"
+	"golang.org/x/xerrors"
+)
+
+//newAuth builds authenticators of API keys and JWT from config, kind of credentials without keys is disabled
+func newAuth() (*restapi.Auth, error) {
+	var (
+		a       restapi.Auth
+		apiKeys []auth.APIKey
+		jwtKeys []auth.JWTKey
+	)
+
+	if err := viper.UnmarshalKey("AUTH_API_KEYS", &apiKeys); err != nil {
+		return nil, xerrors.Errorf("read api keys error: %w", err)
+	}
+
+	if err := viper.UnmarshalKey("AUTH_JWT_KEYS", &jwtKeys); err != nil {
+		return nil, xerrors.Errorf("read jwt keys error: %w", err)
+	}
+
+	if len(apiKeys) > 0 {
+		keys, err := auth.NewAPIKeys(apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		a.APIKeys = keys
+	}
+
+	if len(jwtKeys) > 0 {
+		tokens, err := auth.NewJWT(jwtKeys, viper.GetString("AUTH_JWT_ISSUER"), viper.GetString("AUTH_JWT_AUDIENCE"))
+		if err != nil {
+			return nil, err
+		}
+		a.Tokens = tokens
+	}
+
+	if a.APIKeys == nil && a.Tokens == nil {
+		return nil, xerrors.New("neither api keys nor jwt keys are configured")
+	}
+
+	return &a, nil
+}
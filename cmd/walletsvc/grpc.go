@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/NickRI/wallets-task/domain/services"
+	grpctransport "github.com/NickRI/wallets-task/transport/grpc"
+	"github.com/go-kit/kit/log"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+)
+
+//serveGRPC starts gRPC transport next to the REST one and returns its shutdown
+func serveGRPC(addr string, ws services.Wallet, logger log.Logger) (func(), error) {
+	handlers := grpctransport.MakeHandlers(ws, kitgrpc.ServerErrorLogger(logger))
+	server, err := grpctransport.NewServer(addr, handlers).Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return server.Shutdown, nil
+}
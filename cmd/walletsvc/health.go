@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NickRI/wallets-task/db"
+	"github.com/NickRI/wallets-task/transport/restapi"
+	"github.com/go-kit/kit/log"
+)
+
+const healthcheckCommand = "healthcheck"
+
+//newHealth makes probes ready while the database answers and its schema is migrated
+func newHealth(dbConn *sql.DB, timeout time.Duration) *restapi.Health {
+	return restapi.NewHealth(timeout).
+		AddCheck("database", func(ctx context.Context) (string, error) {
+			if err := dbConn.PingContext(ctx); err != nil {
+				return "", err
+			}
+			return "ok", nil
+		}).
+		AddCheck("migrations", func(ctx context.Context) (string, error) {
+			version, err := db.CheckMigrations(ctx, dbConn)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(version, 10), nil
+		})
+}
+
+//healthcheck requests readiness of the instance listening on port, it's the probe of the scratch image
+//which has no http client, exit code is 1 when instance isn't ready
+func healthcheck(port string, timeout time.Duration, logger log.Logger) int {
+	client := &http.Client{Timeout: timeout}
+
+	resp, err := client.Get("http://localhost:" + port + "/readyz")
+	if err != nil {
+		logger.Log("error", "readiness request failed", "reason", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Log("error", "instance is not ready", "status", resp.StatusCode)
+		return 1
+	}
+
+	return 0
+}
@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/NickRI/wallets-task/db"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/infrastructure/fxrates"
 	"github.com/NickRI/wallets-task/infrastructure/services"
+	"github.com/NickRI/wallets-task/infrastructure/webhooks"
 	"github.com/NickRI/wallets-task/transport/restapi"
 	"github.com/go-kit/kit/log"
 	_ "github.com/lib/pq"
-	"github.com/shopspring/decimal"
 	"github.com/spf13/viper"
 )
 
@@ -26,8 +31,6 @@ const (
 
 func main() {
 
-	decimal.MarshalJSONWithoutQuotes = true
-
 	logger := log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
 
 	viper.SetConfigType(configFormat)
@@ -47,6 +50,15 @@ func main() {
 		return
 	}
 
+	if err := entities.SetMoneyFormat(entities.MoneyFormat(viper.GetString("MONEY_FORMAT"))); err != nil {
+		logger.Log("error", "wrong money format", "reason", err)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == healthcheckCommand {
+		os.Exit(healthcheck(viper.GetString("PORT"), viper.GetDuration("HEALTH_TIMEOUT"), logger))
+	}
+
 	dbConn, err := db.Init()
 	if err != nil {
 		logger.Log("error", "failed init database", "reason", err)
@@ -55,23 +67,129 @@ func main() {
 
 	hostAddress := fmt.Sprintf("%s:%s", viper.GetString("HOST"), viper.GetString("PORT"))
 
-	wSvc, err := services.NewWalletService(dbConn)
+	options := []services.Option{
+		services.WithIdempotencyTTL(viper.GetDuration("IDEMPOTENCY_TTL")),
+		services.WithQuoteTTL(viper.GetDuration("FX_QUOTE_TTL")),
+		services.WithHoldTTL(viper.GetDuration("HOLD_TTL")),
+		services.WithRetryPolicy(services.RetryPolicy{
+			MaxAttempts: viper.GetInt("RETRY_MAX_ATTEMPTS"),
+			Backoff:     viper.GetDuration("RETRY_BACKOFF"),
+			MaxBackoff:  viper.GetDuration("RETRY_MAX_BACKOFF"),
+		}),
+	}
+
+	if viper.GetBool("SINGLE_STATEMENT_TRANSFERS") {
+		options = append(options, services.WithSingleStatementTransfers())
+	}
+
+	switch {
+	case viper.GetString("FX_RATES_URL") != "":
+		options = append(options, services.WithFXRates(fxrates.NewHTTP(viper.GetString("FX_RATES_URL"), viper.GetDuration("FX_RATES_TIMEOUT"))))
+	case viper.GetString("FX_RATES_FILE") != "":
+		rates, err := fxrates.NewFile(viper.GetString("FX_RATES_FILE"))
+		if err != nil {
+			logger.Log("error", "failed read fx rates", "reason", err)
+			return
+		}
+		options = append(options, services.WithFXRates(rates))
+	}
+
+	var metrics *restapi.Metrics
+	if viper.GetBool("METRICS") {
+		var option services.Option
+		if metrics, option, err = newMetrics(dbConn); err != nil {
+			logger.Log("error", "failed init metrics", "reason", err)
+			return
+		}
+		options = append(options, option)
+	}
+
+	var authentication *restapi.Auth
+	if viper.GetBool("AUTH") {
+		if authentication, err = newAuth(); err != nil {
+			logger.Log("error", "failed init authentication", "reason", err)
+			return
+		}
+		options = append(options, services.WithAuthorization())
+	}
+
+	wSvc, err := services.NewWalletService(dbConn, options...)
 	if err != nil {
 		logger.Log("error", "failed init wallet service", "reason", err)
 		return
 	}
 
-	routes := restapi.MakeRoutes(wSvc, logger)
+	reconciler, err := services.NewReconciliationService(dbConn)
+	if err != nil {
+		logger.Log("error", "failed init reconciliation service", "reason", err)
+		return
+	}
+
+	webhookSvc, err := services.NewWebhookService(dbConn)
+	if err != nil {
+		logger.Log("error", "failed init webhook service", "reason", err)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == reconcileCommand {
+		os.Exit(reconcile(context.Background(), reconciler, logger))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if interval := viper.GetDuration("RECONCILE_INTERVAL"); interval > 0 {
+		go reconcileEvery(ctx, reconciler, interval, logger)
+	}
+
+	if interval := viper.GetDuration("WEBHOOK_DISPATCH_INTERVAL"); interval > 0 {
+		dispatcher, err := webhooks.NewDispatcher(dbConn, viper.GetDuration("WEBHOOK_TIMEOUT"),
+			webhooks.WithMaxAttempts(viper.GetInt64("WEBHOOK_MAX_ATTEMPTS")),
+			webhooks.WithBackoff(viper.GetDuration("WEBHOOK_BACKOFF")),
+			webhooks.WithLease(viper.GetDuration("WEBHOOK_LEASE")),
+		)
+		if err != nil {
+			logger.Log("error", "failed init webhooks dispatcher", "reason", err)
+			return
+		}
+		go dispatchEvery(ctx, dispatcher, interval, logger)
+	}
+
+	adminReconciler := reconciler
+	if !viper.GetBool("RECONCILE_ENDPOINT") {
+		adminReconciler = nil
+	}
+
+	health := newHealth(dbConn, viper.GetDuration("HEALTH_TIMEOUT"))
+
+	routes := restapi.MakeRoutes(wSvc, webhookSvc, adminReconciler, health, metrics, authentication, logger)
 	server := restapi.NewServer(hostAddress, routes)
 
 	go server.Run()
 
 	logger.Log("branch", branch, "tag", tag, "commit", commit, "msg", "server is running", "host", hostAddress)
 
-	stop := make(chan os.Signal)
-	signal.Notify(stop, os.Interrupt)
-	<-stop
-	logger.Log("stop", "interrupt signal")
+	if port := viper.GetString("GRPC_PORT"); port != "" {
+		grpcAddress := fmt.Sprintf("%s:%s", viper.GetString("HOST"), port)
+
+		shutdownGRPC, err := serveGRPC(grpcAddress, wSvc, logger)
+		if err != nil {
+			logger.Log("error", "failed start grpc server", "reason", err)
+			return
+		}
+		defer shutdownGRPC()
+
+		logger.Log("msg", "grpc server is running", "host", grpcAddress)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	logger.Log("stop", <-stop)
+
+	//readiness fails first, so load balancer stops routing to the instance before it stops accepting connections
+	health.Drain()
+	logger.Log("msg", "draining", "timeout", viper.GetDuration("DRAIN_TIMEOUT"))
+	time.Sleep(viper.GetDuration("DRAIN_TIMEOUT"))
 
-	server.Shutdown()
+	server.Shutdown(viper.GetDuration("SHUTDOWN_TIMEOUT"))
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/infrastructure/prometheus"
+	"github.com/NickRI/wallets-task/infrastructure/services"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"github.com/NickRI/wallets-task/transport/restapi"
+)
+
+const metricsNamespace = "walletsvc"
+
+//newMetrics registers metrics of the service, returned option makes wallet service count retries and transfer volume
+func newMetrics(dbConn *sql.DB) (*restapi.Metrics, services.Option, error) {
+	registry := prometheus.NewRegistry()
+
+	if err := prometheus.RegisterDBStats(registry, metricsNamespace, dbConn.Stats); err != nil {
+		return nil, nil, err
+	}
+
+	duration, err := registry.NewHistogram(metricsNamespace+"_request_duration_seconds",
+		"Duration of endpoint calls in seconds.", prometheus.DefBuckets, "method", "success")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	errs, err := registry.NewCounter(metricsNamespace+"_endpoint_errors_total",
+		"Failed endpoint calls by kind of the error.", "method", "kind")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	retries, err := registry.NewCounter(metricsNamespace+"_serialization_retries_total",
+		"Transactions repeated after serialization failure or deadlock (SQLSTATE 40001, 40P01).", "operation", "code")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	volume, err := registry.NewCounter(metricsNamespace+"_transfer_volume_total",
+		"Amount of committed payments in units of the currency.", "currency", "type")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &restapi.Metrics{Instrument: endpoints.Instrumenting(duration, errs), Handler: registry}, services.WithMetrics(retries, volume), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/log"
+)
+
+const reconcileCommand = "reconcile"
+
+//reconcile runs the checks once and prints report as json,
+//exit code is 1 when discrepancies are found and 2 when checks failed
+func reconcile(ctx context.Context, rc services.Reconciler, logger log.Logger) int {
+	report, err := rc.Reconcile(ctx)
+	if err != nil {
+		logger.Log("error", "reconciliation failed", "reason", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Log("error", "failed write report", "reason", err)
+		return 2
+	}
+
+	if !report.Consistent {
+		return 1
+	}
+
+	return 0
+}
+
+//reconcileEvery runs the checks at start and then each interval until ctx is done
+func reconcileEvery(ctx context.Context, rc services.Reconciler, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := rc.Reconcile(ctx)
+		switch {
+		case err != nil:
+			logger.Log("error", "reconciliation failed", "reason", err)
+		case !report.Consistent:
+			for _, d := range report.Discrepancies {
+				logger.Log("error", "reconciliation discrepancy", "kind", d.Kind, "account", d.Account,
+					"ledger", d.Ledger, "currency", d.Currency, "legs", d.Legs, "expected", d.Expected, "actual", d.Actual)
+			}
+		default:
+			logger.Log("msg", "reconciliation is consistent", "duration", report.FinishedAt.Sub(report.StartedAt))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/NickRI/wallets-task/infrastructure/webhooks"
+	"github.com/go-kit/kit/log"
+)
+
+//dispatchEvery delivers outbox events to webhooks each interval until ctx is done
+func dispatchEvery(ctx context.Context, d *webhooks.Dispatcher, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := d.Dispatch(ctx); err != nil {
+			logger.Log("error", "webhooks dispatch failed", "reason", err)
+		}
+	}
+}
@@ -306,3 +306,43 @@ func (n NullUUID) Value() (driver.Value, error) {
 
 	return (*n.V).Bytes(), nil
 }
+
+// NullBytes represents a []byte that may be null.
+// NullBytes implements the Scanner interface so
+// it can be used as a scan destination, similar to NullString.
+type NullBytes struct {
+	V *[]byte
+}
+
+// Scan implements the Scanner interface.
+func (n *NullBytes) Scan(value interface{}) (err error) {
+	if n.V == nil {
+		return
+	}
+
+	if value == nil {
+		*n.V = nil
+
+		return
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		*n.V = append([]byte(nil), v...)
+	case string:
+		*n.V = []byte(v)
+	default:
+		return errors.New("value isn't a []byte")
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (n NullBytes) Value() (driver.Value, error) {
+	if n.V == nil || *n.V == nil {
+		return nil, nil
+	}
+
+	return *n.V, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/xerrors"
+)
+
+//SchemaVersion is the latest migration of db/migrations the service depends on, bump it along with a new migration
+const SchemaVersion int64 = 20190816000000
+
+//MigrationVersion returns the version goose considers applied, the latest record
+//of a version decides whether it was applied or rolled back
+func MigrationVersion(ctx context.Context, d *sql.DB) (int64, error) {
+	rows, err := d.QueryContext(ctx, "SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC")
+	if err != nil {
+		return 0, xerrors.Errorf("query migrations error: %w", err)
+	}
+	defer rows.Close()
+
+	rolledBack := map[int64]bool{}
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, xerrors.Errorf("scan migration error: %w", err)
+		}
+
+		if rolledBack[version] {
+			continue
+		}
+
+		if applied {
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, xerrors.Errorf("read migrations error: %w", err)
+	}
+
+	return 0, nil
+}
+
+//CheckMigrations fails when the database schema is behind SchemaVersion
+func CheckMigrations(ctx context.Context, d *sql.DB) (int64, error) {
+	version, err := MigrationVersion(ctx, d)
+	if err != nil {
+		return 0, err
+	}
+
+	if version < SchemaVersion {
+		return version, xerrors.Errorf("schema version %d is behind %d", version, SchemaVersion)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  id              serial PRIMARY KEY,
+  idempotency_key varchar(255) NOT NULL,
+  fingerprint     varchar(64) NOT NULL,
+  ledger          jsonb NOT NULL,
+  created_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  updated_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (idempotency_key)
+);
+
+CREATE INDEX ON idempotency_keys(created_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP TABLE IF EXISTS idempotency_keys;
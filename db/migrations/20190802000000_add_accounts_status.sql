@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'active';
+
+ALTER TABLE accounts ADD CONSTRAINT accounts_status_check CHECK (status IN ('active', 'frozen', 'closed'));
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_status_check;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS status;
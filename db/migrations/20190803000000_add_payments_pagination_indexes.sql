@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE INDEX IF NOT EXISTS payments_guid_idx ON payments(guid);
+
+CREATE INDEX IF NOT EXISTS payments_created_at_id_idx ON payments(created_at DESC, id DESC) WHERE amount > 0;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS payments_created_at_id_idx;
+
+DROP INDEX IF EXISTS payments_guid_idx;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS type varchar(16) NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE payments ADD CONSTRAINT payments_type_check CHECK (type IN ('transfer', 'deposit', 'withdrawal'));
+
+INSERT INTO accounts (user_name, balance, currency, status)
+    SELECT DISTINCT 'settlement:' || currency, 0, currency, 'active' FROM accounts
+ON CONFLICT (user_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_type_check;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS type;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+  id              serial PRIMARY KEY,
+  guid            bytea NOT NULL,
+  source_currency varchar(4) NOT NULL,
+  target_currency varchar(4) NOT NULL,
+  rate            decimal NOT NULL,
+  expires_at      timestamp with time zone NOT NULL,
+  created_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  updated_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (guid)
+);
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS fx_rate decimal;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS source_amount decimal;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS target_amount decimal;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE payments DROP COLUMN IF EXISTS target_amount;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS source_amount;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS fx_rate;
+
+DROP TABLE IF EXISTS fx_quotes;
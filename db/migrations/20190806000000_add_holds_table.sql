@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS holds (
+  id              serial PRIMARY KEY,
+  guid            bytea NOT NULL,
+  account_id      integer NOT NULL,
+  to_account_id   integer NOT NULL,
+  amount          decimal NOT NULL,
+  captured_amount decimal,
+  status          varchar(16) NOT NULL DEFAULT 'active',
+  expires_at      timestamp with time zone NOT NULL,
+  created_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  updated_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (guid),
+  FOREIGN KEY (account_id) REFERENCES accounts (id),
+  FOREIGN KEY (to_account_id) REFERENCES accounts (id),
+  CONSTRAINT holds_status_check CHECK (status IN ('active', 'captured', 'voided'))
+);
+
+CREATE INDEX ON holds(account_id, expires_at) WHERE status = 'active';
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP TABLE IF EXISTS holds;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refund_of bytea;
+
+CREATE INDEX IF NOT EXISTS payments_refund_of_idx ON payments(refund_of) WHERE refund_of IS NOT NULL;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_type_check;
+
+ALTER TABLE payments ADD CONSTRAINT payments_type_check CHECK (type IN ('transfer', 'deposit', 'withdrawal', 'refund'));
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_type_check;
+
+ALTER TABLE payments ADD CONSTRAINT payments_type_check CHECK (type IN ('transfer', 'deposit', 'withdrawal'));
+
+DROP INDEX IF EXISTS payments_refund_of_idx;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS refund_of;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS batch_id bytea;
+
+CREATE INDEX IF NOT EXISTS payments_batch_id_idx ON payments(batch_id) WHERE batch_id IS NOT NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS payments_batch_id_idx;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS batch_id;
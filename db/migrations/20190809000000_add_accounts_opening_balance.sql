@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS opening_balance decimal NOT NULL DEFAULT 0;
+
+UPDATE accounts a SET opening_balance = a.balance - COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.account_id = a.id), 0);
+
+CREATE INDEX IF NOT EXISTS payments_account_id_created_at_idx ON payments(account_id, created_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS payments_account_id_created_at_idx;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS opening_balance;
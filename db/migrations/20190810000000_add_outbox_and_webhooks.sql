@@ -0,0 +1,54 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id            serial PRIMARY KEY,
+  guid          bytea NOT NULL,
+  type          varchar(64) NOT NULL,
+  payload       jsonb NOT NULL,
+  dispatched_at timestamp with time zone,
+  created_at    timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (guid)
+);
+
+CREATE INDEX ON outbox_events(id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+  id         serial PRIMARY KEY,
+  guid       bytea NOT NULL,
+  url        text NOT NULL,
+  secret     varchar(128) NOT NULL,
+  active     boolean NOT NULL DEFAULT true,
+  created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+  updated_at timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (guid)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id              serial PRIMARY KEY,
+  event_id        integer NOT NULL,
+  subscription_id integer NOT NULL,
+  status          varchar(16) NOT NULL DEFAULT 'pending',
+  attempts        integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamp with time zone NOT NULL DEFAULT NOW(),
+  last_error      text,
+  created_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  updated_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (event_id, subscription_id),
+  FOREIGN KEY (event_id) REFERENCES outbox_events (id),
+  FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id),
+  CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+CREATE INDEX ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX ON webhook_deliveries(subscription_id, status, id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhook_subscriptions;
+
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- subject of the principal who created account, accounts without owner are used only by admins
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS owner varchar(255);
+
+CREATE INDEX IF NOT EXISTS accounts_owner_idx ON accounts(owner);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS accounts_owner_idx;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS owner;
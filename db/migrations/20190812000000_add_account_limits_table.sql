@@ -0,0 +1,29 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- limits of the account row take precedence over the row of its currency, null column means no limit
+CREATE TABLE IF NOT EXISTS account_limits (
+  id             serial PRIMARY KEY,
+  account_id     integer,
+  currency       varchar(4),
+  max_amount     decimal,
+  daily_amount   decimal,
+  monthly_amount decimal,
+  hourly_count   integer,
+  created_at     timestamp with time zone NOT NULL DEFAULT NOW(),
+  updated_at     timestamp with time zone NOT NULL DEFAULT NOW(),
+  UNIQUE (account_id),
+  FOREIGN KEY (account_id) REFERENCES accounts (id),
+  CONSTRAINT account_limits_target_check CHECK ((account_id IS NULL) <> (currency IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS account_limits_currency_idx ON account_limits(currency) WHERE account_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS payments_outgoing_idx ON payments(account_id, created_at) WHERE amount < 0;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS payments_outgoing_idx;
+
+DROP TABLE IF EXISTS account_limits;
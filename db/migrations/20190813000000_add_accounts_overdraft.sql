@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- credit line lets account spend below zero down to -overdraft_limit until overdraft_expires_at, NULL never expires
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit decimal NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD CONSTRAINT accounts_overdraft_limit_check CHECK (overdraft_limit >= 0);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_expires_at timestamptz;
+
+-- outgoing leg which left account below zero
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS overdraft boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE payments DROP COLUMN IF EXISTS overdraft;
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_overdraft_limit_check;
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_expires_at;
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_limit;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- registry of currencies with number of decimal places of minor unit, the same list is kept by entities.Currency
+CREATE TABLE IF NOT EXISTS currencies (
+  code     varchar(4) PRIMARY KEY,
+  exponent smallint NOT NULL,
+  CONSTRAINT currencies_exponent_check CHECK (exponent >= 0)
+);
+
+INSERT INTO currencies (code, exponent) VALUES
+    ('AUD', 2), ('BHD', 3), ('CAD', 2), ('CHF', 2), ('CNY', 2),
+    ('EUR', 2), ('GBP', 2), ('HKD', 2), ('JPY', 0), ('KWD', 3),
+    ('NOK', 2), ('RUB', 2), ('SEK', 2), ('SGD', 2), ('USD', 2)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE accounts ALTER COLUMN currency DROP DEFAULT;
+ALTER TABLE accounts ADD CONSTRAINT accounts_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+ALTER TABLE account_limits ADD CONSTRAINT account_limits_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+ALTER TABLE account_limits DROP CONSTRAINT IF EXISTS account_limits_currency_fkey;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_currency_fkey;
+ALTER TABLE accounts ALTER COLUMN currency SET DEFAULT 0;
+
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- balances seeded before the currencies registry could be more precise than minor unit, they are rounded half away
+-- from zero and the difference is taken from opening balance, so balances still reconcile with payments
+UPDATE accounts a SET
+    balance = ROUND(a.balance, c.exponent),
+    opening_balance = a.opening_balance - (a.balance - ROUND(a.balance, c.exponent))
+FROM currencies c
+WHERE c.code = a.currency AND a.balance != ROUND(a.balance, c.exponent);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+;
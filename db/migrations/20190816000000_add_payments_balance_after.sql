@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- balance of the account right after the leg is written along with it, so history of the account is read page by page
+-- instead of summing up all the legs made after the page, legs made before are filled in from the current balances
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS balance_after decimal;
+
+UPDATE payments p SET balance_after = h.balance_after
+FROM (
+    SELECT p.id, a.balance - COALESCE(SUM(p.amount) OVER (PARTITION BY p.account_id ORDER BY p.created_at DESC, p.id DESC
+        ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0) AS balance_after
+    FROM payments p
+    JOIN accounts a ON p.account_id = a.id
+) h
+WHERE p.id = h.id;
+
+ALTER TABLE payments ALTER COLUMN balance_after SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS payments_account_id_created_at_id_idx ON payments(account_id, created_at DESC, id DESC);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DROP INDEX IF EXISTS payments_account_id_created_at_id_idx;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS balance_after;
@@ -0,0 +1,108 @@
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/xerrors"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	files, err := ioutil.ReadDir("migrations")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	var versions []string
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".sql") {
+			versions = append(versions, strings.SplitN(f.Name(), "_", 2)[0])
+		}
+	}
+	sort.Strings(versions)
+
+	latest, err := strconv.ParseInt(versions[len(versions)-1], 10, 64)
+	if err != nil {
+		t.Fatalf("ParseInt() error = %v", err)
+	}
+
+	if SchemaVersion != latest {
+		t.Errorf("SchemaVersion = %d, latest migration is %d", SchemaVersion, latest)
+	}
+}
+
+func TestCheckMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	queryError := xerrors.New("query_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    int64
+		wantErr error
+	}{
+		{
+			name: "query returns error",
+			before: func() {
+				mock.ExpectQuery("SELECT version_id, is_applied FROM goose_db_version").WillReturnError(queryError)
+			},
+			wantErr: xerrors.Errorf("query migrations error: %w", queryError),
+		},
+		{
+			name: "nothing is applied",
+			before: func() {
+				mock.ExpectQuery("SELECT version_id, is_applied FROM goose_db_version").
+					WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied"}))
+			},
+			wantErr: xerrors.Errorf("schema version 0 is behind %d", SchemaVersion),
+		},
+		{
+			name: "latest is rolled back",
+			before: func() {
+				mock.ExpectQuery("SELECT version_id, is_applied FROM goose_db_version").
+					WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied"}).
+						AddRow(SchemaVersion, false).
+						AddRow(SchemaVersion, true).
+						AddRow(20190809000000, true))
+			},
+			want:    20190809000000,
+			wantErr: xerrors.Errorf("schema version 20190809000000 is behind %d", SchemaVersion),
+		},
+		{
+			name: "schema is migrated",
+			before: func() {
+				mock.ExpectQuery("SELECT version_id, is_applied FROM goose_db_version").
+					WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied"}).
+						AddRow(SchemaVersion, true).
+						AddRow(20190809000000, true))
+			},
+			want: SchemaVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+
+			got, err := CheckMigrations(context.Background(), db)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("CheckMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("CheckMigrations() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,12 +11,18 @@ import (
 )
 
 type Account struct {
-	Id        int64
-	UserName  string
-	Balance   decimal.Decimal
-	Currency  string
-	UpdatedAt time.Time
-	CreatedAt time.Time
+	Id               int64
+	UserName         string
+	Balance          decimal.Decimal
+	AvailableBalance decimal.Decimal
+	Currency         string
+	Status           string
+	UpdatedAt        time.Time
+	CreatedAt        time.Time
+	Owner            string
+
+	OverdraftLimit     decimal.Decimal
+	OverdraftExpiresAt time.Time
 }
 
 func (a *Account) Bind() []interface{} {
@@ -24,19 +30,37 @@ func (a *Account) Bind() []interface{} {
 		&common.NullInt64{V: &a.Id},
 		&common.NullString{V: &a.UserName},
 		&common.NullDecimal{V: &a.Balance},
+		&common.NullDecimal{V: &a.AvailableBalance},
 		&common.NullString{V: &a.Currency},
+		&common.NullString{V: &a.Status},
 		&common.NullTime{V: &a.UpdatedAt},
 		&common.NullTime{V: &a.CreatedAt},
+		&common.NullString{V: &a.Owner},
+		&common.NullDecimal{V: &a.OverdraftLimit},
+		&common.NullTime{V: &a.OverdraftExpiresAt},
 	}
 }
 
 func (a *Account) ToDomain() *entities.Account {
 	acc := &entities.Account{
-		AccountId: entities.AccountId(a.UserName),
-		Balance:   a.Balance,
-		Currency:  entities.Currency(a.Currency),
+		AccountId:        entities.AccountId(a.UserName),
+		Balance:          a.Balance,
+		AvailableBalance: a.AvailableBalance,
+		Currency:         entities.Currency(a.Currency),
+		Status:           entities.AccountStatus(a.Status),
+		Owner:            a.Owner,
 	}
 	acc.SetId(a.Id)
+
+	if a.OverdraftLimit.IsPositive() {
+		acc.OverdraftLimit = &a.OverdraftLimit
+		if !a.OverdraftExpiresAt.IsZero() {
+			acc.OverdraftExpiresAt = &a.OverdraftExpiresAt
+		}
+	}
+
+	//settlement accounts are negative by design
+	acc.Overdrawn = a.Balance.IsNegative() && !acc.IsSystem()
 	return acc
 }
 
@@ -47,3 +47,99 @@ func (ve ValidationError) Error() string {
 func (ve ValidationError) Unwrap() error {
 	return ve.Err
 }
+
+type IdempotencyConflictError struct {
+	Err error
+}
+
+func (ice IdempotencyConflictError) Error() string {
+	return ice.Err.Error()
+}
+
+func (ice IdempotencyConflictError) Unwrap() error {
+	return ice.Err
+}
+
+type AccountStateError struct {
+	Err error
+}
+
+func (ase AccountStateError) Error() string {
+	return ase.Err.Error()
+}
+
+func (ase AccountStateError) Unwrap() error {
+	return ase.Err
+}
+
+type QuoteError struct {
+	Err error
+}
+
+func (qe QuoteError) Error() string {
+	return qe.Err.Error()
+}
+
+func (qe QuoteError) Unwrap() error {
+	return qe.Err
+}
+
+type UnavailableError struct {
+	Err error
+}
+
+func (ue UnavailableError) Error() string {
+	return ue.Err.Error()
+}
+
+func (ue UnavailableError) Unwrap() error {
+	return ue.Err
+}
+
+type UnauthenticatedError struct {
+	Err error
+}
+
+func (ue UnauthenticatedError) Error() string {
+	return ue.Err.Error()
+}
+
+func (ue UnauthenticatedError) Unwrap() error {
+	return ue.Err
+}
+
+type ForbiddenError struct {
+	Err error
+}
+
+func (fe ForbiddenError) Error() string {
+	return fe.Err.Error()
+}
+
+func (fe ForbiddenError) Unwrap() error {
+	return fe.Err
+}
+
+type LimitExceededError struct {
+	Err error
+}
+
+func (lee LimitExceededError) Error() string {
+	return lee.Err.Error()
+}
+
+func (lee LimitExceededError) Unwrap() error {
+	return lee.Err
+}
+
+type ConflictError struct {
+	Err error
+}
+
+func (ce ConflictError) Error() string {
+	return ce.Err.Error()
+}
+
+func (ce ConflictError) Unwrap() error {
+	return ce.Err
+}
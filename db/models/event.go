@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+type Event struct {
+	Id        int64
+	Guid      uuid.UUID
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+func NewEvent(eventType entities.EventType, data interface{}) (*Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		Guid:    uuid.NewV4(),
+		Type:    string(eventType),
+		Payload: payload,
+	}, nil
+}
+
+func (e *Event) Bind() []interface{} {
+	return []interface{}{
+		&common.NullUUID{V: &e.Guid},
+		&common.NullString{V: &e.Type},
+		&common.NullBytes{V: &e.Payload},
+	}
+}
+
+func (e *Event) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullUUID{V: &e.Guid},
+		&common.NullString{V: &e.Type},
+		&common.NullBytes{V: &e.Payload},
+		&common.NullTime{V: &e.CreatedAt},
+	}
+}
+
+func (e *Event) ToDomain() *entities.Event {
+	return &entities.Event{
+		Id:        e.Guid.String(),
+		Type:      entities.EventType(e.Type),
+		CreatedAt: e.CreatedAt,
+		Data:      json.RawMessage(e.Payload),
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Hold struct {
+	Id             int64
+	Guid           uuid.UUID
+	AccountId      int64
+	ToAccountId    int64
+	Amount         decimal.Decimal
+	CapturedAmount decimal.Decimal
+	Status         string
+	ExpiresAt      time.Time
+	UpdatedAt      time.Time
+	CreatedAt      time.Time
+	Account        string
+	ToAccount      string
+	Currency       string
+}
+
+func NewHold(credit, debit *entities.Account, amount decimal.Decimal, expiresAt time.Time) *Hold {
+	return &Hold{
+		Guid:        uuid.NewV4(),
+		AccountId:   credit.GetId(),
+		ToAccountId: debit.GetId(),
+		Amount:      amount,
+		Status:      string(entities.HoldActive),
+		ExpiresAt:   expiresAt,
+		Account:     string(credit.AccountId),
+		ToAccount:   string(debit.AccountId),
+		Currency:    string(credit.Currency),
+	}
+}
+
+func (h *Hold) Bind() []interface{} {
+	return []interface{}{
+		&common.NullUUID{V: &h.Guid},
+		&common.NullInt64{V: &h.AccountId},
+		&common.NullInt64{V: &h.ToAccountId},
+		&common.NullDecimal{V: &h.Amount},
+		&common.NullString{V: &h.Status},
+		&common.NullTime{V: &h.ExpiresAt},
+	}
+}
+
+func (h *Hold) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullInt64{V: &h.Id},
+		&common.NullUUID{V: &h.Guid},
+		&common.NullInt64{V: &h.AccountId},
+		&common.NullInt64{V: &h.ToAccountId},
+		&common.NullDecimal{V: &h.Amount},
+		&common.NullDecimal{V: &h.CapturedAmount},
+		&common.NullString{V: &h.Status},
+		&common.NullTime{V: &h.ExpiresAt},
+		&common.NullTime{V: &h.CreatedAt},
+		&common.NullTime{V: &h.UpdatedAt},
+		&common.NullString{V: &h.Account},
+		&common.NullString{V: &h.ToAccount},
+		&common.NullString{V: &h.Currency},
+	}
+}
+
+//ToDomain reports active hold past its expiration moment as expired
+func (h *Hold) ToDomain() *entities.Hold {
+	hold := &entities.Hold{
+		Id:        h.Guid.String(),
+		Account:   entities.AccountId(h.Account),
+		ToAccount: entities.AccountId(h.ToAccount),
+		Amount:    entities.NewMoney(h.Amount, entities.Currency(h.Currency)),
+		Status:    entities.HoldStatus(h.Status),
+		ExpiresAt: h.ExpiresAt,
+	}
+
+	if hold.Status == entities.HoldCaptured {
+		captured := entities.NewMoney(h.CapturedAmount, entities.Currency(h.Currency))
+		hold.CapturedAmount = &captured
+	}
+
+	if hold.Status == entities.HoldActive && !time.Now().Before(h.ExpiresAt) {
+		hold.Status = entities.HoldExpired
+	}
+
+	return hold
+}
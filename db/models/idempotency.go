@@ -0,0 +1,70 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+type IdempotencyKey struct {
+	Id          int64
+	Key         string
+	Fingerprint string
+	Ledger      []byte
+	UpdatedAt   time.Time
+	CreatedAt   time.Time
+}
+
+func NewIdempotencyKey(ik *entities.IdempotencyKey) (*IdempotencyKey, error) {
+	ledger, err := json.Marshal(ik.Ledger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IdempotencyKey{
+		Key:         ik.Key,
+		Fingerprint: ik.Fingerprint,
+		Ledger:      ledger,
+	}, nil
+}
+
+func (ik *IdempotencyKey) Bind() []interface{} {
+	return []interface{}{
+		&common.NullString{V: &ik.Key},
+		&common.NullString{V: &ik.Fingerprint},
+		&common.NullBytes{V: &ik.Ledger},
+	}
+}
+
+func (ik *IdempotencyKey) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullInt64{V: &ik.Id},
+		&common.NullString{V: &ik.Key},
+		&common.NullString{V: &ik.Fingerprint},
+		&common.NullBytes{V: &ik.Ledger},
+		&common.NullTime{V: &ik.CreatedAt},
+		&common.NullTime{V: &ik.UpdatedAt},
+	}
+}
+
+func (ik *IdempotencyKey) ToDomain() (*entities.IdempotencyKey, error) {
+	ledger := new(entities.Ledger)
+	//keys stored before ledgers got their own id keep a bare array of payments
+	if bytes.HasPrefix(bytes.TrimSpace(ik.Ledger), []byte("[")) {
+		if err := json.Unmarshal(ik.Ledger, &ledger.Payments); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(ik.Ledger, ledger); err != nil {
+		return nil, err
+	}
+
+	return &entities.IdempotencyKey{
+		Key:         ik.Key,
+		Fingerprint: ik.Fingerprint,
+		Ledger:      ledger,
+		CreatedAt:   ik.CreatedAt,
+	}, nil
+}
@@ -1,20 +1,34 @@
 package models
 
 import (
+	"time"
+
 	"github.com/NickRI/wallets-task/db/common"
 	"github.com/NickRI/wallets-task/domain/entities"
 	uuid "github.com/satori/go.uuid"
 	"github.com/shopspring/decimal"
 )
 
+//Ledger keeps outgoing leg of the sender NameA in Pays[0] and incoming leg of the receiver NameB in Pays[1],
+//legs are in currencies of their accounts CurrencyA and CurrencyB
 type Ledger struct {
-	Pays  [2]*Payment
-	NameA string
-	NameB string
+	Pays      [2]*Payment
+	NameA     string
+	NameB     string
+	CurrencyA string
+	CurrencyB string
 }
 
-func NewLedgerFromAccount(credit, debit *entities.Account, amount decimal.Decimal) *Ledger {
+//NewLedgerFromAccount makes both legs of payment, debit leg receives
+//converted amount when fx is set
+func NewLedgerFromAccount(credit, debit *entities.Account, money entities.Money, pt entities.PaymentType, fx *entities.Conversion) *Ledger {
 	guid := uuid.NewV4()
+	conversion := NewConversion(fx)
+
+	amount, target := money.Amount, money.Amount
+	if fx != nil {
+		target = fx.TargetAmount
+	}
 
 	return &Ledger{
 		Pays: [2]*Payment{
@@ -22,51 +36,108 @@ func NewLedgerFromAccount(credit, debit *entities.Account, amount decimal.Decima
 				Guid:      guid,
 				AccountId: credit.GetId(),
 				Amount:    amount.Neg(),
+				Type:      string(pt),
+				FX:        conversion,
 			},
 			{
 				Guid:      guid,
 				AccountId: debit.GetId(),
-				Amount:    amount,
+				Amount:    target,
+				Type:      string(pt),
+				FX:        conversion,
 			},
 		},
-		NameA: string(credit.AccountId),
-		NameB: string(debit.AccountId),
+		NameA:     string(credit.AccountId),
+		NameB:     string(debit.AccountId),
+		CurrencyA: string(money.Currency),
+		CurrencyB: string(debit.Currency),
 	}
 }
 
 func (l *Ledger) Bind() []interface{} {
-	return []interface{}{
+	bind := []interface{}{
 		&common.NullUUID{V: &l.Pays[0].Guid},
 		&common.NullInt64{V: &l.Pays[0].AccountId},
 		&common.NullDecimal{V: &l.Pays[0].Amount},
 		&common.NullUUID{V: &l.Pays[1].Guid},
 		&common.NullInt64{V: &l.Pays[1].AccountId},
 		&common.NullDecimal{V: &l.Pays[1].Amount},
+		&common.NullString{V: &l.Pays[0].Type},
+	}
+
+	return append(append(bind, l.Pays[0].FX.Bind()...),
+		&common.NullUUID{V: &l.Pays[0].RefundOf},
+		&common.NullUUID{V: &l.Pays[0].BatchId},
+	)
+}
+
+//SetBatch marks both legs as a part of the batch
+func (l *Ledger) SetBatch(batchId uuid.UUID) {
+	for _, p := range l.Pays {
+		p.BatchId = batchId
+	}
+}
+
+//SetGuid sets guid the legs were written with
+func (l *Ledger) SetGuid(guid uuid.UUID) {
+	for _, p := range l.Pays {
+		p.Guid = guid
+	}
+}
+
+//SetRefundOf links both legs to the ledger they refund
+func (l *Ledger) SetRefundOf(guid uuid.UUID) {
+	for _, p := range l.Pays {
+		p.RefundOf = guid
 	}
 }
 
+//BindScan reads rows which start with incoming leg and its account like the ones of ledgerColumns
 func (l *Ledger) BindScan() []interface{} {
-	bind := append(l.Pays[0].Bind(), l.Pays[1].Bind()...)
-	return append(bind, &common.NullString{V: &l.NameA}, &common.NullString{V: &l.NameB})
+	bind := append(l.Pays[1].Bind(), l.Pays[0].Bind()...)
+	return append(bind, &common.NullString{V: &l.NameB}, &common.NullString{V: &l.NameA},
+		&common.NullString{V: &l.CurrencyB}, &common.NullString{V: &l.CurrencyA})
 }
 
 func (l Ledger) ToDomain() *entities.Ledger {
 	return &entities.Ledger{
-		&entities.Payment{
-			Account:   entities.AccountId(l.NameB),
-			Amount:    l.Pays[1].Amount,
-			Direction: entities.Outgoing,
-			ToAccount: entities.AccountId(l.NameA),
-		},
-		&entities.Payment{
-			Account:     entities.AccountId(l.NameA),
-			Amount:      l.Pays[0].Amount,
-			Direction:   entities.Incoming,
-			FromAccount: entities.AccountId(l.NameB),
+		Id:        l.Pays[0].Guid.String(),
+		CreatedAt: l.Pays[0].CreatedAt,
+		Payments: [2]*entities.Payment{
+			{
+				Ledger:    l.Pays[0].Guid.String(),
+				Account:   entities.AccountId(l.NameA),
+				Amount:    entities.NewMoney(l.Pays[0].Amount, entities.Currency(l.CurrencyA)),
+				Direction: entities.Outgoing,
+				Type:      entities.PaymentType(l.Pays[0].Type),
+				FX:        l.Pays[0].FX.ToDomain(),
+				RefundOf:  l.Pays[0].refundOf(),
+				Batch:     l.Pays[0].batch(),
+				Overdraft: l.Pays[0].Overdraft,
+				ToAccount: entities.AccountId(l.NameB),
+			},
+			{
+				Ledger:      l.Pays[1].Guid.String(),
+				Account:     entities.AccountId(l.NameB),
+				Amount:      entities.NewMoney(l.Pays[1].Amount, entities.Currency(l.CurrencyB)),
+				Direction:   entities.Incoming,
+				Type:        entities.PaymentType(l.Pays[1].Type),
+				FX:          l.Pays[1].FX.ToDomain(),
+				RefundOf:    l.Pays[1].refundOf(),
+				Batch:       l.Pays[1].batch(),
+				FromAccount: entities.AccountId(l.NameA),
+			},
 		},
 	}
 }
 
+//SetCreatedAt sets creation time of both legs written in one statement
+func (l *Ledger) SetCreatedAt(t time.Time) {
+	for _, p := range l.Pays {
+		p.CreatedAt, p.UpdatedAt = t, t
+	}
+}
+
 type LedgerList []*Ledger
 
 func (ls *LedgerList) Add(l *Ledger) {
@@ -80,3 +151,86 @@ func (ls LedgerList) ToDomain() (v entities.Ledgers) {
 
 	return
 }
+
+//Cursor returns position of the ledger in the list ordered by incoming legs
+func (l *Ledger) Cursor() *entities.LedgerCursor {
+	return &entities.LedgerCursor{CreatedAt: l.Pays[1].CreatedAt, Id: l.Pays[1].Id}
+}
+
+type LedgerFilter struct {
+	Account      *string
+	Counterparty *string
+	Direction    *string
+	Type         *string
+	MinAmount    *decimal.Decimal
+	MaxAmount    *decimal.Decimal
+	From         *time.Time
+	To           *time.Time
+	CursorTime   *time.Time
+	CursorId     *int64
+	Limit        int64
+}
+
+//NewLedgerFilter maps domain filter to query arguments, limit is increased by one to detect the next page
+func NewLedgerFilter(f *entities.LedgerFilter) *LedgerFilter {
+	lf := &LedgerFilter{Limit: int64(f.PageSize()) + 1}
+
+	if f.Account != "" {
+		account := string(f.Account)
+		lf.Account = &account
+	}
+
+	if f.Counterparty != "" {
+		counterparty := string(f.Counterparty)
+		lf.Counterparty = &counterparty
+	}
+
+	if f.Direction != nil {
+		direction := f.Direction.String()
+		lf.Direction = &direction
+	}
+
+	if f.Type != nil {
+		pt := string(*f.Type)
+		lf.Type = &pt
+	}
+
+	if f.MinAmount.Valid {
+		lf.MinAmount = &f.MinAmount.Decimal
+	}
+
+	if f.MaxAmount.Valid {
+		lf.MaxAmount = &f.MaxAmount.Decimal
+	}
+
+	if !f.From.IsZero() {
+		lf.From = &f.From
+	}
+
+	if !f.To.IsZero() {
+		lf.To = &f.To
+	}
+
+	if f.Cursor != nil {
+		lf.CursorTime = &f.Cursor.CreatedAt
+		lf.CursorId = &f.Cursor.Id
+	}
+
+	return lf
+}
+
+func (lf *LedgerFilter) Bind() []interface{} {
+	return []interface{}{
+		&common.NullString{V: lf.Account},
+		&common.NullString{V: lf.Counterparty},
+		&common.NullString{V: lf.Direction},
+		&common.NullDecimal{V: lf.MinAmount},
+		&common.NullDecimal{V: lf.MaxAmount},
+		&common.NullTime{V: lf.From},
+		&common.NullTime{V: lf.To},
+		&common.NullTime{V: lf.CursorTime},
+		&common.NullInt64{V: lf.CursorId},
+		&common.NullString{V: lf.Type},
+		lf.Limit,
+	}
+}
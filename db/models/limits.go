@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/shopspring/decimal"
+)
+
+//Limits keeps null columns apart from zero ones, zero limit forbids payments while null doesn't limit them
+type Limits struct {
+	MaxAmount     decimal.NullDecimal
+	DailyAmount   decimal.NullDecimal
+	MonthlyAmount decimal.NullDecimal
+	HourlyCount   sql.NullInt64
+}
+
+func (l *Limits) BindScan() []interface{} {
+	return []interface{}{
+		&l.MaxAmount,
+		&l.DailyAmount,
+		&l.MonthlyAmount,
+		&l.HourlyCount,
+	}
+}
+
+func (l *Limits) ToDomain() *entities.Limits {
+	limits := &entities.Limits{}
+
+	if l.MaxAmount.Valid {
+		limits.MaxAmount = &l.MaxAmount.Decimal
+	}
+
+	if l.DailyAmount.Valid {
+		limits.DailyAmount = &l.DailyAmount.Decimal
+	}
+
+	if l.MonthlyAmount.Valid {
+		limits.MonthlyAmount = &l.MonthlyAmount.Decimal
+	}
+
+	if l.HourlyCount.Valid {
+		limits.HourlyCount = &l.HourlyCount.Int64
+	}
+
+	return limits
+}
+
+type LimitsUsage struct {
+	DailyAmount   decimal.Decimal
+	MonthlyAmount decimal.Decimal
+	HourlyCount   int64
+}
+
+func (u *LimitsUsage) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullDecimal{V: &u.DailyAmount},
+		&common.NullDecimal{V: &u.MonthlyAmount},
+		&common.NullInt64{V: &u.HourlyCount},
+	}
+}
+
+func (u *LimitsUsage) ToDomain() *entities.LimitsUsage {
+	return &entities.LimitsUsage{
+		DailyAmount:   u.DailyAmount,
+		MonthlyAmount: u.MonthlyAmount,
+		HourlyCount:   u.HourlyCount,
+	}
+}
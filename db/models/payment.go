@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
 	uuid "github.com/satori/go.uuid"
 	"github.com/shopspring/decimal"
 )
@@ -13,17 +14,168 @@ type Payment struct {
 	Guid      uuid.UUID
 	AccountId int64
 	Amount    decimal.Decimal
+	Type      string
+	FX        Conversion
+	RefundOf  uuid.UUID
+	BatchId   uuid.UUID
 	UpdatedAt time.Time
 	CreatedAt time.Time
+	Overdraft bool
 }
 
-func (p *Payment) Bind() []interface{} {
+//Conversion columns are null for payments in a single currency
+type Conversion struct {
+	Rate         decimal.Decimal
+	SourceAmount decimal.Decimal
+	TargetAmount decimal.Decimal
+}
+
+func NewConversion(c *entities.Conversion) Conversion {
+	if c == nil {
+		return Conversion{}
+	}
+
+	return Conversion{Rate: c.Rate, SourceAmount: c.SourceAmount, TargetAmount: c.TargetAmount}
+}
+
+func (c *Conversion) Bind() []interface{} {
+	if c.Rate.IsZero() {
+		return []interface{}{&common.NullDecimal{}, &common.NullDecimal{}, &common.NullDecimal{}}
+	}
+
+	return c.BindScan()
+}
+
+func (c *Conversion) BindScan() []interface{} {
 	return []interface{}{
+		&common.NullDecimal{V: &c.Rate},
+		&common.NullDecimal{V: &c.SourceAmount},
+		&common.NullDecimal{V: &c.TargetAmount},
+	}
+}
+
+func (c *Conversion) ToDomain() *entities.Conversion {
+	if c.Rate.IsZero() {
+		return nil
+	}
+
+	return &entities.Conversion{Rate: c.Rate, SourceAmount: c.SourceAmount, TargetAmount: c.TargetAmount}
+}
+
+//refundOf returns guid of the refunded ledger or empty string for the other payments
+func (p *Payment) refundOf() string {
+	return guidString(p.RefundOf)
+}
+
+//batch returns id of the batch or empty string for the single payments
+func (p *Payment) batch() string {
+	return guidString(p.BatchId)
+}
+
+func guidString(guid uuid.UUID) string {
+	if uuid.Equal(guid, uuid.Nil) {
+		return ""
+	}
+
+	return guid.String()
+}
+
+func (p *Payment) Bind() []interface{} {
+	bind := []interface{}{
 		&common.NullInt64{V: &p.Id},
 		&common.NullUUID{V: &p.Guid},
 		&common.NullInt64{V: &p.AccountId},
 		&common.NullDecimal{V: &p.Amount},
+		&common.NullString{V: &p.Type},
+	}
+
+	return append(append(bind, p.FX.BindScan()...),
+		&common.NullUUID{V: &p.RefundOf},
+		&common.NullUUID{V: &p.BatchId},
 		&common.NullTime{V: &p.UpdatedAt},
 		&common.NullTime{V: &p.CreatedAt},
+		&common.NullBool{V: &p.Overdraft},
+	)
+}
+
+//AccountPayment is an account's payment leg with its counterparty and balance after the leg in currency of the account
+type AccountPayment struct {
+	Payment
+	Account      string
+	Counterparty string
+	Currency     string
+	BalanceAfter decimal.Decimal
+}
+
+func (p *AccountPayment) BindScan() []interface{} {
+	return append(p.Payment.Bind(),
+		&common.NullString{V: &p.Account},
+		&common.NullString{V: &p.Counterparty},
+		&common.NullString{V: &p.Currency},
+		&common.NullDecimal{V: &p.BalanceAfter},
+	)
+}
+
+func (p *AccountPayment) ToDomain() *entities.Payment {
+	balanceAfter := entities.NewMoney(p.BalanceAfter, entities.Currency(p.Currency))
+
+	payment := &entities.Payment{
+		Ledger:       p.Guid.String(),
+		Account:      entities.AccountId(p.Account),
+		Amount:       entities.NewMoney(p.Amount, entities.Currency(p.Currency)),
+		Type:         entities.PaymentType(p.Type),
+		FX:           p.FX.ToDomain(),
+		RefundOf:     p.refundOf(),
+		Batch:        p.batch(),
+		Overdraft:    p.Overdraft,
+		BalanceAfter: &balanceAfter,
+	}
+
+	if p.Amount.IsNegative() {
+		payment.Direction = entities.Outgoing
+		payment.ToAccount = entities.AccountId(p.Counterparty)
+	} else {
+		payment.Direction = entities.Incoming
+		payment.FromAccount = entities.AccountId(p.Counterparty)
+	}
+
+	return payment
+}
+
+//Cursor returns position of the payment in the ordered list
+func (p *AccountPayment) Cursor() *entities.LedgerCursor {
+	return &entities.LedgerCursor{CreatedAt: p.CreatedAt, Id: p.Id}
+}
+
+type AccountPaymentList []*AccountPayment
+
+func (ps *AccountPaymentList) Add(p *AccountPayment) {
+	*ps = append(*ps, p)
+}
+
+func (ps AccountPaymentList) ToDomain() (v entities.Payments) {
+	for _, p := range ps {
+		v.Add(p.ToDomain())
+	}
+
+	return
+}
+
+//PaginationArgs maps domain pagination to query arguments, limit is increased by one to detect the next page
+func PaginationArgs(p *entities.Pagination) []interface{} {
+	var (
+		cursorTime *time.Time
+		cursorId   *int64
+	)
+
+	if p.Cursor != nil {
+		cursorTime = &p.Cursor.CreatedAt
+		cursorId = &p.Cursor.Id
+	}
+
+	return []interface{}{
+		&common.NullTime{V: cursorTime},
+		&common.NullInt64{V: cursorId},
+		int64(p.PageSize()) + 1,
 	}
 }
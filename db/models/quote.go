@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+type FXQuote struct {
+	Id        int64
+	Guid      uuid.UUID
+	From      string
+	To        string
+	Rate      decimal.Decimal
+	ExpiresAt time.Time
+	UpdatedAt time.Time
+	CreatedAt time.Time
+}
+
+func NewFXQuote(q *entities.FXQuote) *FXQuote {
+	return &FXQuote{
+		Guid:      uuid.NewV4(),
+		From:      string(q.From),
+		To:        string(q.To),
+		Rate:      q.Rate,
+		ExpiresAt: q.ExpiresAt,
+	}
+}
+
+func (q *FXQuote) Bind() []interface{} {
+	return []interface{}{
+		&common.NullUUID{V: &q.Guid},
+		&common.NullString{V: &q.From},
+		&common.NullString{V: &q.To},
+		&common.NullDecimal{V: &q.Rate},
+		&common.NullTime{V: &q.ExpiresAt},
+	}
+}
+
+func (q *FXQuote) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullInt64{V: &q.Id},
+		&common.NullUUID{V: &q.Guid},
+		&common.NullString{V: &q.From},
+		&common.NullString{V: &q.To},
+		&common.NullDecimal{V: &q.Rate},
+		&common.NullTime{V: &q.ExpiresAt},
+		&common.NullTime{V: &q.CreatedAt},
+		&common.NullTime{V: &q.UpdatedAt},
+	}
+}
+
+func (q *FXQuote) ToDomain() *entities.FXQuote {
+	return &entities.FXQuote{
+		Id: q.Guid.String(),
+		FXRate: entities.FXRate{
+			From: entities.Currency(q.From),
+			To:   entities.Currency(q.To),
+			Rate: q.Rate,
+		},
+		ExpiresAt: q.ExpiresAt,
+	}
+}
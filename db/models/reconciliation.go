@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+)
+
+//Discrepancy is a row of any reconciliation query, columns which don't apply to the check are NULL
+type Discrepancy struct {
+	Account  string
+	Ledger   uuid.UUID
+	Currency string
+	Legs     int64
+	Expected decimal.Decimal
+	Actual   decimal.Decimal
+}
+
+func (d *Discrepancy) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullString{V: &d.Account},
+		&common.NullUUID{V: &d.Ledger},
+		&common.NullString{V: &d.Currency},
+		&common.NullInt64{V: &d.Legs},
+		&common.NullDecimal{V: &d.Expected},
+		&common.NullDecimal{V: &d.Actual},
+	}
+}
+
+func (d *Discrepancy) ToDomain(kind entities.DiscrepancyKind) *entities.Discrepancy {
+	return &entities.Discrepancy{
+		Kind:     kind,
+		Account:  entities.AccountId(d.Account),
+		Ledger:   guidString(d.Ledger),
+		Currency: entities.Currency(d.Currency),
+		Legs:     d.Legs,
+		Expected: d.Expected,
+		Actual:   d.Actual,
+	}
+}
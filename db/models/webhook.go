@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+type Webhook struct {
+	Id        int64
+	Guid      uuid.UUID
+	URL       string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+}
+
+func NewWebhook(wh *entities.Webhook) *Webhook {
+	return &Webhook{
+		Guid:   uuid.NewV4(),
+		URL:    wh.URL,
+		Secret: wh.Secret,
+		Active: true,
+	}
+}
+
+func (wh *Webhook) Bind() []interface{} {
+	return []interface{}{
+		&common.NullUUID{V: &wh.Guid},
+		&common.NullString{V: &wh.URL},
+		&common.NullString{V: &wh.Secret},
+	}
+}
+
+//BindScan reads webhook without its secret
+func (wh *Webhook) BindScan() []interface{} {
+	return []interface{}{
+		&common.NullUUID{V: &wh.Guid},
+		&common.NullString{V: &wh.URL},
+		&common.NullBool{V: &wh.Active},
+		&common.NullTime{V: &wh.CreatedAt},
+	}
+}
+
+func (wh *Webhook) ToDomain() *entities.Webhook {
+	return &entities.Webhook{
+		Id:        wh.Guid.String(),
+		URL:       wh.URL,
+		Secret:    wh.Secret,
+		Active:    wh.Active,
+		CreatedAt: wh.CreatedAt,
+	}
+}
+
+type Delivery struct {
+	Id            int64
+	Status        string
+	Attempts      int64
+	NextAttemptAt time.Time
+	LastError     string
+	Event         Event
+	Webhook       Webhook
+}
+
+func NewDelivery(d *entities.Delivery) *Delivery {
+	return &Delivery{
+		Id:            d.GetId(),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+	}
+}
+
+func (d *Delivery) Bind() []interface{} {
+	return []interface{}{
+		&common.NullInt64{V: &d.Id},
+		&common.NullString{V: &d.Status},
+		&common.NullInt64{V: &d.Attempts},
+		&common.NullTime{V: &d.NextAttemptAt},
+		&common.NullString{V: &d.LastError},
+	}
+}
+
+//BindScan reads delivery with its event and target webhook including secret to sign the payload
+func (d *Delivery) BindScan() []interface{} {
+	bind := []interface{}{
+		&common.NullInt64{V: &d.Id},
+		&common.NullString{V: &d.Status},
+		&common.NullInt64{V: &d.Attempts},
+		&common.NullTime{V: &d.NextAttemptAt},
+		&common.NullString{V: &d.LastError},
+	}
+
+	return append(append(bind, d.Event.BindScan()...),
+		&common.NullUUID{V: &d.Webhook.Guid},
+		&common.NullString{V: &d.Webhook.URL},
+		&common.NullString{V: &d.Webhook.Secret},
+	)
+}
+
+func (d *Delivery) ToDomain() *entities.Delivery {
+	delivery := &entities.Delivery{
+		Event:         d.Event.ToDomain(),
+		Webhook:       &entities.Webhook{Id: d.Webhook.Guid.String(), URL: d.Webhook.URL, Secret: d.Webhook.Secret, Active: true},
+		Status:        entities.DeliveryStatus(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+	}
+	delivery.SetId(d.Id)
+	return delivery
+}
@@ -1,13 +1,30 @@
 package entities
 
 import (
+	"encoding/json"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
 
 type AccountId string
 
+//SettlementAccountPrefix is reserved for system accounts, they are counterparties
+//of deposits and withdrawals and may have negative balance
+const SettlementAccountPrefix = "settlement:"
+
+//SettlementAccountId returns id of the system account for currency
+func SettlementAccountId(currency Currency) AccountId {
+	return AccountId(SettlementAccountPrefix + string(currency))
+}
+
+//IsSystem reports whether id belongs to system account
+func (aid AccountId) IsSystem() bool {
+	return strings.HasPrefix(string(aid), SettlementAccountPrefix)
+}
+
 func (aid *AccountId) String() string {
 	return string(*aid)
 }
@@ -20,11 +37,61 @@ func (aid *AccountId) MarshalJSON() ([]byte, error) {
 	return []byte(aid.Quoted()), nil
 }
 
+//AccountStatus is a lifecycle state of account
+type AccountStatus string
+
+const (
+	AccountActive AccountStatus = "active"
+	AccountFrozen AccountStatus = "frozen"
+	AccountClosed AccountStatus = "closed"
+)
+
+//Valid reports whether status is known
+func (as AccountStatus) Valid() bool {
+	switch as {
+	case AccountActive, AccountFrozen, AccountClosed:
+		return true
+	default:
+		return false
+	}
+}
+
+//CanChangeTo reports whether account may be moved from as to v status, closed account is final
+func (as AccountStatus) CanChangeTo(v AccountStatus) bool {
+	switch as {
+	case AccountActive:
+		return v == AccountFrozen || v == AccountClosed
+	case AccountFrozen:
+		return v == AccountActive || v == AccountClosed
+	default:
+		return false
+	}
+}
+
+//Account's AvailableBalance is Balance reduced by active holds, account with OverdraftLimit may go below zero
+//down to -OverdraftLimit until OverdraftExpiresAt and Overdrawn reports its negative Balance
 type Account struct {
-	id        int64
-	AccountId AccountId       `json:"id"`
-	Balance   decimal.Decimal `json:"balance"`
-	Currency  Currency        `json:"currency"`
+	id                 int64
+	AccountId          AccountId        `json:"id"`
+	Balance            decimal.Decimal  `json:"balance"`
+	AvailableBalance   decimal.Decimal  `json:"available_balance"`
+	Currency           Currency         `json:"currency"`
+	Status             AccountStatus    `json:"status"`
+	Owner              string           `json:"owner,omitempty"`
+	OverdraftLimit     *decimal.Decimal `json:"overdraft_limit,omitempty"`
+	OverdraftExpiresAt *time.Time       `json:"overdraft_expires_at,omitempty"`
+	Overdrawn          bool             `json:"overdrawn"`
+}
+
+//MarshalJSON writes balances and overdraft limit in the money format
+func (a Account) MarshalJSON() ([]byte, error) {
+	type account Account
+	return json.Marshal(&struct {
+		account
+		Balance          jsonAmount  `json:"balance"`
+		AvailableBalance jsonAmount  `json:"available_balance"`
+		OverdraftLimit   *jsonAmount `json:"overdraft_limit,omitempty"`
+	}{account(a), jsonAmount(a.Balance), jsonAmount(a.AvailableBalance), optionalAmount(a.OverdraftLimit)})
 }
 
 func (a *Account) GetId() int64 {
@@ -35,10 +102,51 @@ func (a *Account) SetId(id int64) {
 	a.id = id
 }
 
+//IsActive reports whether account may be debited or credited
+func (a *Account) IsActive() bool {
+	return a.Status == AccountActive
+}
+
+//IsSystem reports whether account is a settlement one
+func (a *Account) IsSystem() bool {
+	return a.AccountId.IsSystem()
+}
+
+//Overdraft returns credit line account may use at the moment, missing or expired one is zero
+func (a *Account) Overdraft(at time.Time) decimal.Decimal {
+	if a.OverdraftLimit == nil || a.OverdraftExpiresAt != nil && !at.Before(*a.OverdraftExpiresAt) {
+		return decimal.Zero
+	}
+
+	return *a.OverdraftLimit
+}
+
+//Spendable returns money account may pay at the moment, available balance along with overdraft
+func (a *Account) Spendable(at time.Time) Money {
+	return NewMoney(a.AvailableBalance.Add(a.Overdraft(at)), a.Currency)
+}
+
 func (a *Account) Equal(v *Account) bool {
 	return a.id == v.id
 }
 
+//AccountBalance is the balance account had at the moment At
+type AccountBalance struct {
+	AccountId AccountId       `json:"id"`
+	Balance   decimal.Decimal `json:"balance"`
+	Currency  Currency        `json:"currency"`
+	At        time.Time       `json:"at"`
+}
+
+//MarshalJSON writes balance in the money format
+func (ab AccountBalance) MarshalJSON() ([]byte, error) {
+	type accountBalance AccountBalance
+	return json.Marshal(&struct {
+		accountBalance
+		Balance jsonAmount `json:"balance"`
+	}{accountBalance(ab), jsonAmount(ab.Balance)})
+}
+
 type Accounts []*Account
 
 func (as *Accounts) Add(a *Account) {
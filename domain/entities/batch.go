@@ -0,0 +1,14 @@
+package entities
+
+//TransferLeg is a single transfer of the batch, amount without currency is taken in the one of From account
+type TransferLeg struct {
+	From   AccountId `json:"from"`
+	To     AccountId `json:"to"`
+	Amount Money     `json:"amount"`
+}
+
+//Batch is a set of transfers made all-or-nothing, ledgers follow the order of legs
+type Batch struct {
+	Id      string  `json:"id"`
+	Ledgers Ledgers `json:"ledgers"`
+}
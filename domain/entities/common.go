@@ -1,4 +1,57 @@
 package entities
 
-//Currency is a value object
+import (
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+//Currency is a value object, ISO 4217 code of known currency
 type Currency string
+
+//currencies keeps number of decimal places of minor unit by the code, migration of currencies table has the same list
+var currencies = map[Currency]int32{
+	"AUD": 2,
+	"BHD": 3,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"JPY": 0,
+	"KWD": 3,
+	"NOK": 2,
+	"RUB": 2,
+	"SEK": 2,
+	"SGD": 2,
+	"USD": 2,
+}
+
+//Valid reports whether currency is registered
+func (c Currency) Valid() bool {
+	_, ok := currencies[c]
+	return ok
+}
+
+//Exponent returns number of decimal places of minor unit, cents of USD are 2 and yen has none
+func (c Currency) Exponent() int32 {
+	return currencies[c]
+}
+
+//ValidateAmount checks that amount is positive and isn't more precise than minor unit of currency
+func (c Currency) ValidateAmount(amount decimal.Decimal) error {
+	exp, ok := currencies[c]
+	if !ok {
+		return xerrors.Errorf("currency %s is unknown", c)
+	}
+
+	if !amount.IsPositive() {
+		return xerrors.New("amount should be positive")
+	}
+
+	if !amount.Equal(amount.Truncate(exp)) {
+		return xerrors.Errorf("amount %s has more than %d decimal places of %s", amount, exp, c)
+	}
+
+	return nil
+}
@@ -14,6 +14,29 @@ const (
 	Incoming
 )
 
+func (d Direction) String() string {
+	switch d {
+	case Outgoing:
+		return "outgoing"
+	case Incoming:
+		return "incoming"
+	default:
+		return ""
+	}
+}
+
+//ParseDirection converts outgoing/incoming string to Direction
+func ParseDirection(v string) (Direction, error) {
+	switch v {
+	case "outgoing":
+		return Outgoing, nil
+	case "incoming":
+		return Incoming, nil
+	default:
+		return 0, xerrors.New("wrong type of Direction only outgoing/incoming values allowed")
+	}
+}
+
 func (d Direction) MarshalJSON() ([]byte, error) {
 	switch d {
 	case Outgoing:
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//EventType names what happened to the wallet
+type EventType string
+
+const (
+	//PaymentCompleted is published with the ledger when money was moved
+	PaymentCompleted EventType = "payment.completed"
+)
+
+//Event is a record of outbox written in the same transaction as the change it tells about
+type Event struct {
+	Id        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
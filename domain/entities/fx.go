@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+//FXRate is a price of one From unit in To currency
+type FXRate struct {
+	From Currency        `json:"from"`
+	To   Currency        `json:"to"`
+	Rate decimal.Decimal `json:"rate"`
+}
+
+//MarshalJSON writes rate in the money format
+func (r FXRate) MarshalJSON() ([]byte, error) {
+	type fxRate FXRate
+	return json.Marshal(&struct {
+		fxRate
+		Rate jsonAmount `json:"rate"`
+	}{fxRate(r), jsonAmount(r.Rate)})
+}
+
+//Convert applies rate to amount of From currency, target amount is rounded half away from zero to minor unit of
+//To currency and the rate of conversion is the one of the rounded amounts. Amount too small to get a minor unit
+//of To currency keeps the rate and converts to zero
+func (r *FXRate) Convert(amount decimal.Decimal) *Conversion {
+	target := amount.Mul(r.Rate).Round(r.To.Exponent())
+
+	rate := r.Rate
+	if target.IsPositive() && !amount.IsZero() {
+		rate = target.Div(amount)
+	}
+
+	return &Conversion{
+		Rate:         rate,
+		SourceAmount: amount,
+		TargetAmount: target,
+	}
+}
+
+//FXQuote locks the rate for a client until ExpiresAt
+type FXQuote struct {
+	Id string `json:"id"`
+	FXRate
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+//MarshalJSON writes the whole quote, otherwise the method of embedded FXRate would write the rate alone
+func (q FXQuote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Id        string     `json:"id"`
+		From      Currency   `json:"from"`
+		To        Currency   `json:"to"`
+		Rate      jsonAmount `json:"rate"`
+		ExpiresAt time.Time  `json:"expires_at"`
+	}{q.Id, q.From, q.To, jsonAmount(q.Rate), q.ExpiresAt})
+}
+
+//Expired reports whether quote can't be used at the moment
+func (q *FXQuote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+//Conversion is applied to cross-currency payment, source amount is taken
+//in sender's currency and target amount is put in receiver's one
+type Conversion struct {
+	Rate         decimal.Decimal `json:"rate"`
+	SourceAmount decimal.Decimal `json:"source_amount"`
+	TargetAmount decimal.Decimal `json:"target_amount"`
+}
+
+//MarshalJSON writes rate and amounts in the money format
+func (c Conversion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Rate         jsonAmount `json:"rate"`
+		SourceAmount jsonAmount `json:"source_amount"`
+		TargetAmount jsonAmount `json:"target_amount"`
+	}{jsonAmount(c.Rate), jsonAmount(c.SourceAmount), jsonAmount(c.TargetAmount)})
+}
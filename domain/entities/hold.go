@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+//HoldStatus is a lifecycle state of authorization hold
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+//Hold reserves amount of account's funds for the payment to ToAccount,
+//reserved funds aren't available for other payments until hold is captured,
+//voided or expired
+type Hold struct {
+	Id             string     `json:"id"`
+	Account        AccountId  `json:"account"`
+	ToAccount      AccountId  `json:"to_account"`
+	Amount         Money      `json:"amount"`
+	CapturedAmount *Money     `json:"captured_amount,omitempty"`
+	Status         HoldStatus `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+}
+
+//IsActive reports whether hold may be captured or voided
+func (h *Hold) IsActive() bool {
+	return h.Status == HoldActive
+}
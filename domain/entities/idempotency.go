@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+//IdempotencyKey binds client's key to the ledger created by the first request with it
+type IdempotencyKey struct {
+	Key         string
+	Fingerprint string
+	Ledger      *Ledger
+	CreatedAt   time.Time
+}
+
+//NewIdempotencyKey makes key with fingerprint of the payment request, equal amounts written
+//with different number of decimal places ("10" and "10.00") make the same fingerprint
+func NewIdempotencyKey(key, quoteId, creditId, debitId string, amount Money) *IdempotencyKey {
+	sum := sha256.Sum256([]byte(strings.Join([]string{creditId, debitId, fingerprintAmount(amount), string(amount.Currency), quoteId}, "\n")))
+
+	return &IdempotencyKey{
+		Key:         key,
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}
+}
+
+//fingerprintAmount writes amount with decimal places of minor unit of its currency, amounts of unknown
+//currency and more precise than minor unit are written without trailing zeros so they never match valid ones
+func fingerprintAmount(amount Money) string {
+	exp := amount.Currency.Exponent()
+	if !amount.Currency.Valid() || !amount.Amount.Equal(amount.Amount.Truncate(exp)) {
+		return amount.Amount.String()
+	}
+
+	return amount.Amount.StringFixed(exp)
+}
+
+//Match reports whether both keys were made for the same payment request
+func (ik *IdempotencyKey) Match(v *IdempotencyKey) bool {
+	return ik.Key == v.Key && ik.Fingerprint == v.Fingerprint
+}
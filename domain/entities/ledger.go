@@ -1,9 +1,24 @@
 package entities
 
-type Ledger [2]*Payment
+import "time"
+
+//Ledger is a single transfer made of outgoing and incoming payments sharing its Id
+type Ledger struct {
+	Id        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Payments  [2]*Payment `json:"payments"`
+}
 
 type Ledgers []*Ledger
 
+//TransferAttempt is outcome of transfer made with a single statement, Credit and Debit are accounts as they were
+//before it and nil when they don't exist, Ledger is nil when the statement didn't make the transfer
+type TransferAttempt struct {
+	Credit *Account
+	Debit  *Account
+	Ledger *Ledger
+}
+
 func (ls *Ledgers) Add(l *Ledger) {
 	*ls = append(*ls, l)
 }
@@ -11,3 +26,23 @@ func (ls *Ledgers) Add(l *Ledger) {
 func (ls *Ledgers) Append(v Ledgers) {
 	*ls = append(*ls, v...)
 }
+
+//Outgoing returns the leg of sender
+func (l *Ledger) Outgoing() *Payment {
+	return l.leg(Outgoing)
+}
+
+//Incoming returns the leg of receiver
+func (l *Ledger) Incoming() *Payment {
+	return l.leg(Incoming)
+}
+
+func (l *Ledger) leg(d Direction) *Payment {
+	for _, p := range l.Payments {
+		if p != nil && p.Direction == d {
+			return p
+		}
+	}
+
+	return nil
+}
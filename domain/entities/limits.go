@@ -0,0 +1,133 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+//Limits restrict outgoing payments of account, nil limit means there isn't one.
+//Daily and monthly totals are counted from the start of UTC day and month, transfers count for the last hour
+type Limits struct {
+	MaxAmount     *decimal.Decimal `json:"max_amount"`
+	DailyAmount   *decimal.Decimal `json:"daily_amount"`
+	MonthlyAmount *decimal.Decimal `json:"monthly_amount"`
+	HourlyCount   *int64           `json:"hourly_count"`
+}
+
+//MarshalJSON writes amounts in the money format
+func (l Limits) MarshalJSON() ([]byte, error) {
+	type limits Limits
+	return json.Marshal(&struct {
+		limits
+		MaxAmount     *jsonAmount `json:"max_amount"`
+		DailyAmount   *jsonAmount `json:"daily_amount"`
+		MonthlyAmount *jsonAmount `json:"monthly_amount"`
+	}{limits(l), optionalAmount(l.MaxAmount), optionalAmount(l.DailyAmount), optionalAmount(l.MonthlyAmount)})
+}
+
+//LimitsWindows returns starts of the day, the month and the hour windows of limits at the moment
+func LimitsWindows(at time.Time) (day, month, hour time.Time) {
+	at = at.UTC()
+	day = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	hour = at.Add(-time.Hour)
+	return
+}
+
+//LimitsUsage is what account has already sent during the windows of limits
+type LimitsUsage struct {
+	DailyAmount   decimal.Decimal
+	MonthlyAmount decimal.Decimal
+	HourlyCount   int64
+}
+
+//Allowance is what is left of account's limits, nil remainder means it isn't limited
+type Allowance struct {
+	AccountId        AccountId        `json:"account_id"`
+	Currency         Currency         `json:"currency"`
+	Limits           Limits           `json:"limits"`
+	DailyRemaining   *decimal.Decimal `json:"daily_remaining"`
+	MonthlyRemaining *decimal.Decimal `json:"monthly_remaining"`
+	HourlyRemaining  *int64           `json:"hourly_remaining"`
+}
+
+//MarshalJSON writes remainders in the money format
+func (a Allowance) MarshalJSON() ([]byte, error) {
+	type allowance Allowance
+	return json.Marshal(&struct {
+		allowance
+		DailyRemaining   *jsonAmount `json:"daily_remaining"`
+		MonthlyRemaining *jsonAmount `json:"monthly_remaining"`
+	}{allowance(a), optionalAmount(a.DailyRemaining), optionalAmount(a.MonthlyRemaining)})
+}
+
+//NewAllowance subtracts usage from the limits of account, remainders never go below zero
+func NewAllowance(account *Account, limits *Limits, usage *LimitsUsage) *Allowance {
+	a := &Allowance{AccountId: account.AccountId, Currency: account.Currency, Limits: *limits}
+
+	if limits.DailyAmount != nil {
+		a.DailyRemaining = remainder(*limits.DailyAmount, usage.DailyAmount)
+	}
+
+	if limits.MonthlyAmount != nil {
+		a.MonthlyRemaining = remainder(*limits.MonthlyAmount, usage.MonthlyAmount)
+	}
+
+	if limits.HourlyCount != nil {
+		left := *limits.HourlyCount - usage.HourlyCount
+		if left < 0 {
+			left = 0
+		}
+		a.HourlyRemaining = &left
+	}
+
+	return a
+}
+
+//Check returns error naming the first limit one more payment of amount exceeds, limits are in currency of the account
+func (a *Allowance) Check(money Money) error {
+	if money.Currency != a.Currency {
+		return xerrors.Errorf("%s is checked against limits in %s: %w", money, a.Currency, ErrCurrencyMismatch)
+	}
+
+	amount := money.Amount
+	switch {
+	case a.Limits.MaxAmount != nil && amount.GreaterThan(*a.Limits.MaxAmount):
+		return xerrors.Errorf("amount %s exceeds single payment limit %s", amount, a.Limits.MaxAmount)
+	case a.DailyRemaining != nil && amount.GreaterThan(*a.DailyRemaining):
+		return xerrors.Errorf("amount %s exceeds daily limit, %s is left", amount, a.DailyRemaining)
+	case a.MonthlyRemaining != nil && amount.GreaterThan(*a.MonthlyRemaining):
+		return xerrors.Errorf("amount %s exceeds monthly limit, %s is left", amount, a.MonthlyRemaining)
+	case a.HourlyRemaining != nil && *a.HourlyRemaining < 1:
+		return xerrors.Errorf("hourly limit of %d transfers is reached", *a.Limits.HourlyCount)
+	}
+
+	return nil
+}
+
+//Spend takes one payment of amount from the remainders
+func (a *Allowance) Spend(amount Money) {
+	if a.DailyRemaining != nil {
+		a.DailyRemaining = remainder(*a.DailyRemaining, amount.Amount)
+	}
+
+	if a.MonthlyRemaining != nil {
+		a.MonthlyRemaining = remainder(*a.MonthlyRemaining, amount.Amount)
+	}
+
+	if a.HourlyRemaining != nil && *a.HourlyRemaining > 0 {
+		left := *a.HourlyRemaining - 1
+		a.HourlyRemaining = &left
+	}
+}
+
+func remainder(limit, used decimal.Decimal) *decimal.Decimal {
+	left := limit.Sub(used)
+	if left.IsNegative() {
+		left = decimal.Zero
+	}
+	return &left
+}
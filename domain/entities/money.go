@@ -0,0 +1,181 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+//ErrCurrencyMismatch is returned by operations on money of different currencies
+var ErrCurrencyMismatch = xerrors.New("currency mismatch")
+
+//MoneyFormat is JSON representation of money amount
+type MoneyFormat string
+
+const (
+	MoneyAsNumber MoneyFormat = "number"
+	MoneyAsString MoneyFormat = "string"
+)
+
+//moneyFormat is the format of amounts written by MarshalJSON, amounts are read in both of them
+var moneyFormat = MoneyAsNumber
+
+//SetMoneyFormat makes money and the rest of decimals of entities like rates and limits be written as format,
+//decimals are written by entities themselves, so the format of decimal package is left as it is
+func SetMoneyFormat(format MoneyFormat) error {
+	switch format {
+	case MoneyAsNumber, MoneyAsString:
+	default:
+		return xerrors.Errorf("money format %s is unknown", format)
+	}
+
+	moneyFormat = format
+	return nil
+}
+
+//CurrentMoneyFormat returns the format amounts are written in
+func CurrentMoneyFormat() MoneyFormat {
+	return moneyFormat
+}
+
+//jsonAmount is decimal written in the money format
+type jsonAmount decimal.Decimal
+
+func (a jsonAmount) MarshalJSON() ([]byte, error) {
+	amount := decimal.Decimal(a).String()
+	if moneyFormat == MoneyAsString {
+		return []byte(`"` + amount + `"`), nil
+	}
+
+	return []byte(amount), nil
+}
+
+//optionalAmount keeps missing decimal missing
+func optionalAmount(d *decimal.Decimal) *jsonAmount {
+	if d == nil {
+		return nil
+	}
+
+	a := jsonAmount(*d)
+	return &a
+}
+
+//Money is a value object of amount in currency, arithmetic fails on money of different currencies,
+//money without currency is an amount taken in the currency of account it's paid from
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency Currency        `json:"currency,omitempty"`
+}
+
+func NewMoney(amount decimal.Decimal, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+//In returns money in currency, money without currency takes it and money in another one is mismatch
+func (m Money) In(currency Currency) (Money, error) {
+	if m.Currency != "" && m.Currency != currency {
+		return m, xerrors.Errorf("%s is paid in %s: %w", m, currency, ErrCurrencyMismatch)
+	}
+
+	return NewMoney(m.Amount, currency), nil
+}
+
+func (m Money) same(v Money) error {
+	if m.Currency != v.Currency {
+		return xerrors.Errorf("%s and %s: %w", m, v, ErrCurrencyMismatch)
+	}
+
+	return nil
+}
+
+func (m Money) Add(v Money) (Money, error) {
+	if err := m.same(v); err != nil {
+		return m, err
+	}
+
+	return NewMoney(m.Amount.Add(v.Amount), m.Currency), nil
+}
+
+func (m Money) Sub(v Money) (Money, error) {
+	if err := m.same(v); err != nil {
+		return m, err
+	}
+
+	return NewMoney(m.Amount.Sub(v.Amount), m.Currency), nil
+}
+
+//Cmp compares amounts like decimal.Cmp does
+func (m Money) Cmp(v Money) (int, error) {
+	if err := m.same(v); err != nil {
+		return 0, err
+	}
+
+	return m.Amount.Cmp(v.Amount), nil
+}
+
+func (m Money) LessThan(v Money) (bool, error) {
+	c, err := m.Cmp(v)
+	return c < 0, err
+}
+
+func (m Money) Neg() Money {
+	return NewMoney(m.Amount.Neg(), m.Currency)
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount.IsPositive()
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+//Equal reports whether money has the same currency and amount regardless of its precision
+func (m Money) Equal(v Money) bool {
+	return m.Currency == v.Currency && m.Amount.Equal(v.Amount)
+}
+
+//Validate checks that amount is positive and fits minor unit of currency
+func (m Money) Validate() error {
+	return m.Currency.ValidateAmount(m.Amount)
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+
+	return m.Amount.String() + " " + string(m.Currency)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   jsonAmount `json:"amount"`
+		Currency Currency   `json:"currency,omitempty"`
+	}{jsonAmount(m.Amount), m.Currency})
+}
+
+//UnmarshalJSON reads money object or bare amount which is money without currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		*m = Money{}
+		return m.Amount.UnmarshalJSON(data)
+	}
+
+	var v struct {
+		Amount   decimal.Decimal `json:"amount"`
+		Currency Currency        `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*m = NewMoney(v.Amount, v.Currency)
+	return nil
+}
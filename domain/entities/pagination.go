@@ -0,0 +1,119 @@
+package entities
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+//LedgerCursor points to the last ledger of the page, pages are ordered by creation time and id
+type LedgerCursor struct {
+	CreatedAt time.Time
+	Id        int64
+}
+
+//String encodes cursor to opaque value for clients
+func (lc *LedgerCursor) String() string {
+	raw := strconv.FormatInt(lc.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(lc.Id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+//ParseLedgerCursor decodes cursor made by LedgerCursor.String
+func ParseLedgerCursor(v string) (*LedgerCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed cursor: %w", err)
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, xerrors.New("malformed cursor")
+	}
+
+	nsec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed cursor: %w", err)
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("malformed cursor: %w", err)
+	}
+
+	return &LedgerCursor{CreatedAt: time.Unix(0, nsec), Id: id}, nil
+}
+
+//Pagination selects page after Cursor, zero Limit means default page size
+type Pagination struct {
+	Cursor *LedgerCursor
+	Limit  int
+}
+
+//Validate checks limit bounds
+func (p *Pagination) Validate() error {
+	if p.Limit < 0 || p.Limit > MaxPageSize {
+		return xerrors.Errorf("limit should be from 1 to %d", MaxPageSize)
+	}
+
+	return nil
+}
+
+//PageSize returns requested limit or default one
+func (p *Pagination) PageSize() int {
+	if p.Limit == 0 {
+		return DefaultPageSize
+	}
+	return p.Limit
+}
+
+//LedgerFilter narrows ledgers list, zero values mean no restriction.
+//Direction and Counterparty are relative to Account.
+type LedgerFilter struct {
+	Pagination
+	Account      AccountId
+	Counterparty AccountId
+	Direction    *Direction
+	Type         *PaymentType
+	MinAmount    decimal.NullDecimal
+	MaxAmount    decimal.NullDecimal
+	From         time.Time
+	To           time.Time
+}
+
+//Validate checks filter consistency
+func (lf *LedgerFilter) Validate() error {
+	if lf.Account == "" && (lf.Counterparty != "" || lf.Direction != nil) {
+		return xerrors.New("counterparty and direction filters require account")
+	}
+
+	if lf.MinAmount.Valid && lf.MaxAmount.Valid && lf.MinAmount.Decimal.GreaterThan(lf.MaxAmount.Decimal) {
+		return xerrors.New("min_amount is greater than max_amount")
+	}
+
+	if !lf.From.IsZero() && !lf.To.IsZero() && !lf.From.Before(lf.To) {
+		return xerrors.New("from should be before to")
+	}
+
+	return lf.Pagination.Validate()
+}
+
+//LedgersPage is a single page of ledgers list
+type LedgersPage struct {
+	Ledgers    Ledgers `json:"ledgers"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+//PaymentsPage is a single page of account's payments
+type PaymentsPage struct {
+	Payments   Payments `json:"payments"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
@@ -1,13 +1,21 @@
 package entities
 
-import "github.com/shopspring/decimal"
-
+//Payment is a leg of ledger, RefundOf refers to the ledger returned by the refund
+//and Batch to the batch of transfers made along, Overdraft marks outgoing leg which left account below zero
 type Payment struct {
-	Account     AccountId       `json:"account"`
-	Amount      decimal.Decimal `json:"amount"`
-	ToAccount   AccountId       `json:"to_account,omitempty"`
-	FromAccount AccountId       `json:"from_account,omitempty"`
-	Direction   Direction       `json:"direction"`
+	Ledger      string      `json:"ledger,omitempty"`
+	Account     AccountId   `json:"account"`
+	Amount      Money       `json:"amount"`
+	ToAccount   AccountId   `json:"to_account,omitempty"`
+	FromAccount AccountId   `json:"from_account,omitempty"`
+	Direction   Direction   `json:"direction"`
+	Type        PaymentType `json:"type"`
+	FX          *Conversion `json:"fx,omitempty"`
+	RefundOf    string      `json:"refund_of,omitempty"`
+	Batch       string      `json:"batch,omitempty"`
+	Overdraft   bool        `json:"overdraft,omitempty"`
+
+	BalanceAfter *Money `json:"balance_after,omitempty"`
 }
 
 func (l *Payment) Equal(v *Payment) bool {
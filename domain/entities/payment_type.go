@@ -0,0 +1,23 @@
+package entities
+
+import "golang.org/x/xerrors"
+
+//PaymentType tells peer transfers from money coming in and out of the wallet
+type PaymentType string
+
+const (
+	Transfer   PaymentType = "transfer"
+	Deposit    PaymentType = "deposit"
+	Withdrawal PaymentType = "withdrawal"
+	Refund     PaymentType = "refund"
+)
+
+//ParsePaymentType converts transfer/deposit/withdrawal/refund string to PaymentType
+func ParsePaymentType(v string) (PaymentType, error) {
+	switch pt := PaymentType(v); pt {
+	case Transfer, Deposit, Withdrawal, Refund:
+		return pt, nil
+	default:
+		return "", xerrors.New("wrong type of payment only transfer/deposit/withdrawal/refund values allowed")
+	}
+}
@@ -0,0 +1,40 @@
+package entities
+
+import "context"
+
+//AdminScope lets principal use any account, settlement ones included
+const AdminScope = "admin"
+
+//Principal is the authenticated caller, accounts it creates are owned by its Subject
+type Principal struct {
+	Subject string
+	Scopes  []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+//CanUse reports whether principal may move funds of the account or change it
+func (p *Principal) CanUse(a *Account) bool {
+	return p.HasScope(AdminScope) || a.Owner != "" && a.Owner == p.Subject
+}
+
+type principalKey struct{}
+
+//ContextWithPrincipal returns copy of ctx carrying the caller
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+//PrincipalFromContext returns the caller put into ctx by authentication
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
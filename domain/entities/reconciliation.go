@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+//DiscrepancyKind names the invariant which is broken
+type DiscrepancyKind string
+
+const (
+	//BalanceMismatch is account balance which differs from opening balance plus its payments
+	BalanceMismatch DiscrepancyKind = "balance_mismatch"
+	//UnbalancedLedger is ledger which hasn't exactly two legs or which legs don't net out
+	UnbalancedLedger DiscrepancyKind = "unbalanced_ledger"
+	//CurrencyImbalance is money created or lost within currency apart of conversions
+	CurrencyImbalance DiscrepancyKind = "currency_imbalance"
+)
+
+//Discrepancy is a single broken invariant, Expected is derived from payments and Actual is what is stored
+type Discrepancy struct {
+	Kind     DiscrepancyKind `json:"kind"`
+	Account  AccountId       `json:"account,omitempty"`
+	Ledger   string          `json:"ledger,omitempty"`
+	Currency Currency        `json:"currency,omitempty"`
+	Legs     int64           `json:"legs,omitempty"`
+	Expected decimal.Decimal `json:"expected"`
+	Actual   decimal.Decimal `json:"actual"`
+}
+
+//MarshalJSON writes expected and actual values in the money format
+func (d Discrepancy) MarshalJSON() ([]byte, error) {
+	type discrepancy Discrepancy
+	return json.Marshal(&struct {
+		discrepancy
+		Expected jsonAmount `json:"expected"`
+		Actual   jsonAmount `json:"actual"`
+	}{discrepancy(d), jsonAmount(d.Expected), jsonAmount(d.Actual)})
+}
+
+type Discrepancies []*Discrepancy
+
+//ReconciliationReport is the result of checking balances and ledgers in a single snapshot
+type ReconciliationReport struct {
+	StartedAt     time.Time     `json:"started_at"`
+	FinishedAt    time.Time     `json:"finished_at"`
+	Consistent    bool          `json:"consistent"`
+	Discrepancies Discrepancies `json:"discrepancies"`
+}
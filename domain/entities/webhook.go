@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"net/url"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+//MinWebhookSecretLength keeps signatures from being guessed
+const MinWebhookSecretLength = 16
+
+//Webhook is a subscription of url to all the events, Secret signs deliveries
+//and is revealed only when webhook is created
+type Webhook struct {
+	Id        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//Validate checks that url is absolute http one and secret is long enough
+func (wh *Webhook) Validate() error {
+	u, err := url.Parse(wh.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return xerrors.Errorf("url %q should be absolute http or https one", wh.URL)
+	}
+
+	if len(wh.Secret) < MinWebhookSecretLength {
+		return xerrors.Errorf("secret should be at least %d characters long", MinWebhookSecretLength)
+	}
+
+	return nil
+}
+
+type Webhooks []*Webhook
+
+//DeliveryStatus is a lifecycle state of event delivery to webhook
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	//DeliveryDead is the dead-letter state of delivery which ran out of attempts
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+//ParseDeliveryStatus converts pending/delivered/dead string to DeliveryStatus
+func ParseDeliveryStatus(v string) (DeliveryStatus, error) {
+	switch ds := DeliveryStatus(v); ds {
+	case DeliveryPending, DeliveryDelivered, DeliveryDead:
+		return ds, nil
+	default:
+		return "", xerrors.New("wrong status of delivery only pending/delivered/dead values allowed")
+	}
+}
+
+//Delivery is an attempt series to post Event to Webhook
+type Delivery struct {
+	id            int64
+	Event         *Event         `json:"event"`
+	Webhook       *Webhook       `json:"-"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int64          `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     string         `json:"last_error,omitempty"`
+}
+
+func (d *Delivery) GetId() int64 {
+	return d.id
+}
+
+func (d *Delivery) SetId(id int64) {
+	d.id = id
+}
+
+//Delivered marks delivery as successful
+func (d *Delivery) Delivered() {
+	d.Attempts++
+	d.Status = DeliveryDelivered
+	d.LastError = ""
+}
+
+//Failed schedules the next attempt at next or moves delivery to dead-letter state after maxAttempts
+func (d *Delivery) Failed(reason string, maxAttempts int64, next time.Time) {
+	d.Attempts++
+	d.LastError = reason
+	if d.Attempts >= maxAttempts {
+		d.Status = DeliveryDead
+		return
+	}
+	d.NextAttemptAt = next
+}
+
+type Deliveries []*Delivery
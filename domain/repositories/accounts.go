@@ -3,15 +3,20 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/NickRI/wallets-task/domain/entities"
-	"github.com/shopspring/decimal"
 )
 
 //go:generate mockgen -destination=../../internal/mock/accounts.go -package=mock github.com/NickRI/wallets-task/domain/repositories Accounts
 type Accounts interface {
 	List(context.Context) (entities.Accounts, error)
+	Create(context.Context, *entities.Account) (*entities.Account, error)
+	CreateSettlement(context.Context, entities.Currency) error
+	GetByName(context.Context, entities.AccountId) (*entities.Account, error)
 	GetByNameTx(*sql.Tx, context.Context, entities.AccountId) (*entities.Account, error)
-	UpdateBalanceTx(*sql.Tx, context.Context, *entities.Account, decimal.Decimal) error
-	LockTx(*sql.Tx, context.Context) error
+	UpdateBalanceTx(*sql.Tx, context.Context, *entities.Account, entities.Money) error
+	BalanceAt(context.Context, *entities.Account, time.Time) (entities.Money, error)
+	UpdateStatusTx(*sql.Tx, context.Context, *entities.Account, entities.AccountStatus) error
+	LockTx(*sql.Tx, context.Context, ...entities.AccountId) error
 }
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/holds.go -package=mock github.com/NickRI/wallets-task/domain/repositories Holds
+type Holds interface {
+	GetById(context.Context, string) (*entities.Hold, error)
+	GetByIdTx(*sql.Tx, context.Context, string) (*entities.Hold, error)
+	CreateTx(*sql.Tx, context.Context, *entities.Account, *entities.Account, entities.Money, time.Time) (*entities.Hold, error)
+	CaptureTx(*sql.Tx, context.Context, *entities.Hold, entities.Money) error
+	VoidTx(*sql.Tx, context.Context, *entities.Hold) error
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/idempotency.go -package=mock github.com/NickRI/wallets-task/domain/repositories IdempotencyKeys
+type IdempotencyKeys interface {
+	GetByKeyTx(*sql.Tx, context.Context, string, time.Time) (*entities.IdempotencyKey, error)
+	AddTx(*sql.Tx, context.Context, *entities.IdempotencyKey) error
+}
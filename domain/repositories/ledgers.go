@@ -5,11 +5,17 @@ import (
 	"database/sql"
 
 	"github.com/NickRI/wallets-task/domain/entities"
-	"github.com/shopspring/decimal"
 )
 
 //go:generate mockgen -destination=../../internal/mock/ledgers.go -package=mock github.com/NickRI/wallets-task/domain/repositories Ledgers
 type Ledgers interface {
-	List(context.Context) (entities.Ledgers, error)
-	AddTx(*sql.Tx, context.Context, *entities.Account, *entities.Account, decimal.Decimal) (*entities.Ledger, error)
+	List(context.Context, *entities.LedgerFilter) (*entities.LedgersPage, error)
+	GetByGuid(context.Context, string) (*entities.Ledger, error)
+	ListByAccount(context.Context, *entities.Account, *entities.Pagination) (*entities.PaymentsPage, error)
+	AddTx(*sql.Tx, context.Context, *entities.Account, *entities.Account, entities.Money, entities.PaymentType, *entities.Conversion) (*entities.Ledger, error)
+	AddBatchTx(*sql.Tx, context.Context, string, *entities.Account, *entities.Account, entities.Money, *entities.Conversion) (*entities.Ledger, error)
+	AddRefundTx(*sql.Tx, context.Context, string, *entities.Account, *entities.Account, entities.Money, *entities.Conversion) (*entities.Ledger, error)
+	GetByGuidTx(*sql.Tx, context.Context, string) (*entities.Ledger, error)
+	RefundedTx(*sql.Tx, context.Context, *entities.Payment) (entities.Money, error)
+	TransferTx(*sql.Tx, context.Context, entities.AccountId, entities.AccountId, entities.Money) (*entities.TransferAttempt, error)
 }
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/limits.go -package=mock github.com/NickRI/wallets-task/domain/repositories Limits
+type Limits interface {
+	Get(context.Context, *entities.Account) (*entities.Limits, error)
+	GetTx(*sql.Tx, context.Context, *entities.Account) (*entities.Limits, error)
+	Usage(context.Context, *entities.Account, time.Time) (*entities.LimitsUsage, error)
+	UsageTx(*sql.Tx, context.Context, *entities.Account, time.Time) (*entities.LimitsUsage, error)
+}
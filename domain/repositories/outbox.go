@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/outbox.go -package=mock github.com/NickRI/wallets-task/domain/repositories Outbox
+type Outbox interface {
+	AddTx(*sql.Tx, context.Context, entities.EventType, interface{}) (*entities.Event, error)
+	FanOut(context.Context, int) (int64, error)
+	Claim(context.Context, int, time.Time) (entities.Deliveries, error)
+	UpdateDelivery(context.Context, *entities.Delivery) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/quotes.go -package=mock github.com/NickRI/wallets-task/domain/repositories Quotes
+type Quotes interface {
+	Create(context.Context, *entities.FXQuote) (*entities.FXQuote, error)
+	GetByIdTx(*sql.Tx, context.Context, string) (*entities.FXQuote, error)
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/reconciliation.go -package=mock github.com/NickRI/wallets-task/domain/repositories Reconciliation
+type Reconciliation interface {
+	BalanceMismatchesTx(*sql.Tx, context.Context) (entities.Discrepancies, error)
+	UnbalancedLedgersTx(*sql.Tx, context.Context) (entities.Discrepancies, error)
+	CurrencyImbalancesTx(*sql.Tx, context.Context) (entities.Discrepancies, error)
+}
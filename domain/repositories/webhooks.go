@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/webhooks.go -package=mock github.com/NickRI/wallets-task/domain/repositories Webhooks
+type Webhooks interface {
+	Create(context.Context, *entities.Webhook) (*entities.Webhook, error)
+	List(context.Context) (entities.Webhooks, error)
+	Deactivate(context.Context, string) (*entities.Webhook, error)
+	Deliveries(context.Context, string, entities.DeliveryStatus, int) (entities.Deliveries, error)
+}
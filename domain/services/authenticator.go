@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/authenticator.go -package=mock github.com/NickRI/wallets-task/domain/services Authenticator
+//Authenticator resolves credentials presented by the caller to principal
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials string) (*entities.Principal, error)
+}
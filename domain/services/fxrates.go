@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/fxrates.go -package=mock github.com/NickRI/wallets-task/domain/services FXRates
+type FXRates interface {
+	Rate(ctx context.Context, from, to entities.Currency) (*entities.FXRate, error)
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/reconciler.go -package=mock github.com/NickRI/wallets-task/domain/services Reconciler
+type Reconciler interface {
+	Reconcile(ctx context.Context) (*entities.ReconciliationReport, error)
+	LastReport(ctx context.Context) (*entities.ReconciliationReport, error)
+}
@@ -2,14 +2,30 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/NickRI/wallets-task/domain/entities"
-	"github.com/shopspring/decimal"
 )
 
 //go:generate mockgen -destination=../../internal/mock/wallet.go -package=mock github.com/NickRI/wallets-task/domain/services Wallet
 type Wallet interface {
-	LedgersList(ctx context.Context) (entities.Ledgers, error)
+	LedgersList(ctx context.Context, filter *entities.LedgerFilter) (*entities.LedgersPage, error)
+	GetLedger(ctx context.Context, ledgerId string) (*entities.Ledger, error)
+	AccountLedgers(ctx context.Context, accountId string, page *entities.Pagination) (*entities.PaymentsPage, error)
 	AccountsList(ctx context.Context) (entities.Accounts, error)
-	Send(ctx context.Context, creditId, debitId string, amount decimal.Decimal) (*entities.Ledger, error)
+	CreateAccount(ctx context.Context, accountId, currency string) (*entities.Account, error)
+	GetAccount(ctx context.Context, accountId string) (*entities.Account, error)
+	BalanceAt(ctx context.Context, accountId string, at time.Time) (*entities.AccountBalance, error)
+	Allowance(ctx context.Context, accountId string) (*entities.Allowance, error)
+	ChangeAccountStatus(ctx context.Context, accountId string, status entities.AccountStatus) (*entities.Account, error)
+	Send(ctx context.Context, idempotencyKey, quoteId, creditId, debitId string, amount entities.Money) (*entities.Ledger, error)
+	Batch(ctx context.Context, legs []*entities.TransferLeg) (*entities.Batch, error)
+	Refund(ctx context.Context, ledgerId string, amount entities.Money) (*entities.Ledger, error)
+	Quote(ctx context.Context, from, to string) (*entities.FXQuote, error)
+	Deposit(ctx context.Context, idempotencyKey, accountId string, amount entities.Money) (*entities.Ledger, error)
+	Withdraw(ctx context.Context, idempotencyKey, accountId string, amount entities.Money) (*entities.Ledger, error)
+	Authorize(ctx context.Context, creditId, debitId string, amount entities.Money) (*entities.Hold, error)
+	Capture(ctx context.Context, holdId string, amount entities.Money) (*entities.Ledger, error)
+	Void(ctx context.Context, holdId string) (*entities.Hold, error)
+	GetHold(ctx context.Context, holdId string) (*entities.Hold, error)
 }
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+)
+
+//go:generate mockgen -destination=../../internal/mock/webhook_service.go -package=mock -mock_names=Webhooks=MockWebhookService github.com/NickRI/wallets-task/domain/services Webhooks
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, url, secret string) (*entities.Webhook, error)
+	ListWebhooks(ctx context.Context) (entities.Webhooks, error)
+	DeleteWebhook(ctx context.Context, webhookId string) (*entities.Webhook, error)
+	WebhookDeliveries(ctx context.Context, webhookId string, status entities.DeliveryStatus) (entities.Deliveries, error)
+}
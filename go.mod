@@ -8,9 +8,12 @@ require (
 	github.com/go-chi/docgen v1.0.5
 	github.com/go-kit/kit v0.9.0
 	github.com/golang/mock v1.1.1
+	github.com/golang/protobuf v1.3.2
 	github.com/lib/pq v1.2.0
+	github.com/prometheus/client_golang v1.0.0
 	github.com/satori/go.uuid v1.2.0
 	github.com/shopspring/decimal v0.0.0-20180709203117-cd690d0c9e24
 	github.com/spf13/viper v1.4.0
 	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
+	google.golang.org/grpc v1.21.0
 )
@@ -0,0 +1,69 @@
+//Package auth implements authenticators of API keys and JWT verified with locally configured keys
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"golang.org/x/xerrors"
+)
+
+//APIKey describes key known to the service, only sha256 hex of the key is kept in config
+type APIKey struct {
+	Hash    string   `mapstructure:"sha256"`
+	Subject string   `mapstructure:"subject"`
+	Scopes  []string `mapstructure:"scopes"`
+}
+
+type apiKey struct {
+	hash      []byte
+	principal entities.Principal
+}
+
+//APIKeys authenticates callers by keys of config
+type APIKeys struct {
+	keys []apiKey
+}
+
+func NewAPIKeys(keys []APIKey) (services.Authenticator, error) {
+	a := &APIKeys{keys: make([]apiKey, 0, len(keys))}
+
+	for i, key := range keys {
+		hash, err := hex.DecodeString(key.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, xerrors.Errorf("api key %d: sha256 must be %d bytes in hex", i, sha256.Size)
+		}
+
+		if key.Subject == "" {
+			return nil, xerrors.Errorf("api key %d: subject is empty", i)
+		}
+
+		a.keys = append(a.keys, apiKey{hash: hash, principal: entities.Principal{Subject: key.Subject, Scopes: key.Scopes}})
+	}
+
+	return a, nil
+}
+
+//Authenticate compares hash of credentials with every key in constant time
+func (a *APIKeys) Authenticate(ctx context.Context, credentials string) (*entities.Principal, error) {
+	sum := sha256.Sum256([]byte(credentials))
+
+	var found *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 {
+			found = &a.keys[i]
+		}
+	}
+
+	if found == nil {
+		return nil, models.UnauthenticatedError{xerrors.New("invalid api key")}
+	}
+
+	p := found.principal
+	return &p, nil
+}
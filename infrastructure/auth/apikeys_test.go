@@ -0,0 +1,110 @@
+// +build !integration
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"golang.org/x/xerrors"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestNewAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []APIKey
+		wantErr error
+	}{
+		{
+			name:    "hash is not hex",
+			keys:    []APIKey{{Hash: "secret", Subject: "alice"}},
+			wantErr: xerrors.New("api key 0: sha256 must be 32 bytes in hex"),
+		},
+		{
+			name:    "hash is short",
+			keys:    []APIKey{{Hash: "abcd", Subject: "alice"}},
+			wantErr: xerrors.New("api key 0: sha256 must be 32 bytes in hex"),
+		},
+		{
+			name:    "subject is empty",
+			keys:    []APIKey{{Hash: hashKey("alice-key")}},
+			wantErr: xerrors.New("api key 0: subject is empty"),
+		},
+		{
+			name: "works fine",
+			keys: []APIKey{{Hash: hashKey("alice-key"), Subject: "alice"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPIKeys(tt.keys)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("NewAPIKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	keys, err := NewAPIKeys([]APIKey{
+		{Hash: hashKey("alice-key"), Subject: "alice"},
+		{Hash: hashKey("ops-key"), Subject: "ops", Scopes: []string{entities.AdminScope}},
+	})
+	if err != nil {
+		t.Fatalf("NewAPIKeys() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		credentials string
+		want        *entities.Principal
+		wantErr     error
+	}{
+		{
+			name:        "unknown key",
+			credentials: "bob-key",
+			wantErr:     models.UnauthenticatedError{xerrors.New("invalid api key")},
+		},
+		{
+			name:        "empty key",
+			credentials: "",
+			wantErr:     models.UnauthenticatedError{xerrors.New("invalid api key")},
+		},
+		{
+			name:        "owner",
+			credentials: "alice-key",
+			want:        &entities.Principal{Subject: "alice"},
+		},
+		{
+			name:        "admin",
+			credentials: "ops-key",
+			want:        &entities.Principal{Subject: "ops", Scopes: []string{entities.AdminScope}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keys.Authenticate(context.Background(), tt.credentials)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !xerrors.As(err, &models.UnauthenticatedError{}) {
+				t.Fatalf("Authenticate() error = %T, want UnauthenticatedError", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Authenticate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
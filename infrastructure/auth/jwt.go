@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"golang.org/x/xerrors"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+//clockSkew is tolerated between clocks of the issuer and the service
+const clockSkew = 30 * time.Second
+
+//JWTKey verifies tokens with kid header equal to Id, Secret is used by HS256
+//and PublicKey in PEM format by RS256
+type JWTKey struct {
+	Id        string `mapstructure:"kid"`
+	Algorithm string `mapstructure:"alg"`
+	Secret    string `mapstructure:"secret"`
+	PublicKey string `mapstructure:"public_key"`
+}
+
+type verifier struct {
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+func (v *verifier) verify(signed, signature []byte) bool {
+	if v.alg == HS256 {
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+
+	sum := sha256.Sum256(signed)
+	return rsa.VerifyPKCS1v15(v.public, crypto.SHA256, sum[:], signature) == nil
+}
+
+//JWT authenticates callers by bearer tokens, issuer and audience are checked when not empty
+type JWT struct {
+	keys     map[string]*verifier
+	issuer   string
+	audience string
+}
+
+func NewJWT(keys []JWTKey, issuer, audience string) (services.Authenticator, error) {
+	j := &JWT{keys: make(map[string]*verifier, len(keys)), issuer: issuer, audience: audience}
+
+	for _, key := range keys {
+		if _, ok := j.keys[key.Id]; ok {
+			return nil, xerrors.Errorf("jwt key %q is duplicated", key.Id)
+		}
+
+		v := &verifier{alg: key.Algorithm}
+		switch key.Algorithm {
+		case HS256:
+			if key.Secret == "" {
+				return nil, xerrors.Errorf("jwt key %q: secret is empty", key.Id)
+			}
+			v.secret = []byte(key.Secret)
+		case RS256:
+			public, err := parsePublicKey(key.PublicKey)
+			if err != nil {
+				return nil, xerrors.Errorf("jwt key %q: %w", key.Id, err)
+			}
+			v.public = public
+		default:
+			return nil, xerrors.Errorf("jwt key %q: unsupported algorithm %q", key.Id, key.Algorithm)
+		}
+
+		j.keys[key.Id] = v
+	}
+
+	return j, nil
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+//audience is a single string or array of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Scopes    []string `json:"scopes"`
+}
+
+//Authenticate verifies signature of the token and its claims, scopes are taken
+//from space separated scope claim or scopes array
+func (j *JWT) Authenticate(ctx context.Context, credentials string) (*entities.Principal, error) {
+	c, err := j.parse(credentials, time.Now())
+	if err != nil {
+		return nil, models.UnauthenticatedError{xerrors.Errorf("invalid token: %w", err)}
+	}
+
+	scopes := append(strings.Fields(c.Scope), c.Scopes...)
+	return &entities.Principal{Subject: c.Subject, Scopes: scopes}, nil
+}
+
+func (j *JWT) parse(token string, now time.Time) (*claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, xerrors.New("malformed token")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, xerrors.Errorf("header: %w", err)
+	}
+
+	v, ok := j.keys[h.KeyId]
+	if !ok {
+		return nil, xerrors.Errorf("unknown key %q", h.KeyId)
+	}
+
+	//algorithm is fixed by the key, so the token can't choose how it's verified
+	if h.Algorithm != v.alg {
+		return nil, xerrors.Errorf("algorithm %q doesn't match the key", h.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !v.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, xerrors.New("signature is invalid")
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, xerrors.Errorf("claims: %w", err)
+	}
+
+	if c.Subject == "" {
+		return nil, xerrors.New("subject is missing")
+	}
+
+	if c.ExpiresAt == nil {
+		return nil, xerrors.New("expiration is missing")
+	}
+
+	if now.Add(-clockSkew).After(unixTime(*c.ExpiresAt)) {
+		return nil, xerrors.New("token is expired")
+	}
+
+	if c.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*c.NotBefore)) {
+		return nil, xerrors.New("token is not valid yet")
+	}
+
+	if j.issuer != "" && c.Issuer != j.issuer {
+		return nil, xerrors.Errorf("issuer %q is not accepted", c.Issuer)
+	}
+
+	if j.audience != "" && !c.Audience.contains(j.audience) {
+		return nil, xerrors.Errorf("audience %q is not accepted", j.audience)
+	}
+
+	return &c, nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return xerrors.Errorf("decode error: %w", err)
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+//parsePublicKey accepts PKIX and PKCS1 encoded RSA keys
+func parsePublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, xerrors.New("public key is not in PEM format")
+	}
+
+	if public, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return public, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, xerrors.Errorf("parse public key error: %w", err)
+	}
+
+	public, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, xerrors.New("public key is not RSA")
+	}
+
+	return public, nil
+}
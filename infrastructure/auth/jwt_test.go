@@ -0,0 +1,196 @@
+// +build !integration
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"golang.org/x/xerrors"
+)
+
+func segment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signHS256(t *testing.T, secret string, header, claims map[string]interface{}) string {
+	signed := segment(t, header) + "." + segment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]interface{}) string {
+	signed := segment(t, header) + "." + segment(t, claims)
+	sum := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("rsa.SignPKCS1v15() error = %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestNewJWT(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []JWTKey
+		wantErr error
+	}{
+		{
+			name:    "unsupported algorithm",
+			keys:    []JWTKey{{Id: "k1", Algorithm: "none"}},
+			wantErr: xerrors.New(`jwt key "k1": unsupported algorithm "none"`),
+		},
+		{
+			name:    "empty secret",
+			keys:    []JWTKey{{Id: "k1", Algorithm: HS256}},
+			wantErr: xerrors.New(`jwt key "k1": secret is empty`),
+		},
+		{
+			name:    "public key isn't pem",
+			keys:    []JWTKey{{Id: "k1", Algorithm: RS256, PublicKey: "key"}},
+			wantErr: xerrors.New(`jwt key "k1": public key is not in PEM format`),
+		},
+		{
+			name:    "duplicated key",
+			keys:    []JWTKey{{Id: "k1", Algorithm: HS256, Secret: "s"}, {Id: "k1", Algorithm: HS256, Secret: "s"}},
+			wantErr: xerrors.New(`jwt key "k1" is duplicated`),
+		},
+		{
+			name: "works fine",
+			keys: []JWTKey{{Id: "k1", Algorithm: HS256, Secret: "s"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWT(tt.keys, "", "")
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("NewJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWT_Authenticate(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+
+	tokens, err := NewJWT([]JWTKey{
+		{Id: "hs", Algorithm: HS256, Secret: "top-secret"},
+		{Id: "rs", Algorithm: RS256, PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))},
+	}, "wallets", "walletsvc")
+	if err != nil {
+		t.Fatalf("NewJWT() error = %v", err)
+	}
+
+	hs := map[string]interface{}{"alg": HS256, "kid": "hs", "typ": "JWT"}
+	rs := map[string]interface{}{"alg": RS256, "kid": "rs", "typ": "JWT"}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *entities.Principal
+		wantErr error
+	}{
+		{
+			name:    "malformed",
+			token:   "token",
+			wantErr: xerrors.New("invalid token: malformed token"),
+		},
+		{
+			name:    "unknown key",
+			token:   signHS256(t, "top-secret", map[string]interface{}{"alg": HS256, "kid": "other"}, map[string]interface{}{"sub": "alice", "exp": exp}),
+			wantErr: xerrors.New(`invalid token: unknown key "other"`),
+		},
+		{
+			name:    "algorithm doesn't match the key",
+			token:   signHS256(t, "top-secret", map[string]interface{}{"alg": HS256, "kid": "rs"}, map[string]interface{}{"sub": "alice", "exp": exp}),
+			wantErr: xerrors.New(`invalid token: algorithm "HS256" doesn't match the key`),
+		},
+		{
+			name:    "wrong secret",
+			token:   signHS256(t, "guess", hs, map[string]interface{}{"sub": "alice", "exp": exp, "iss": "wallets", "aud": "walletsvc"}),
+			wantErr: xerrors.New("invalid token: signature is invalid"),
+		},
+		{
+			name:    "expiration is missing",
+			token:   signHS256(t, "top-secret", hs, map[string]interface{}{"sub": "alice", "iss": "wallets", "aud": "walletsvc"}),
+			wantErr: xerrors.New("invalid token: expiration is missing"),
+		},
+		{
+			name:    "expired",
+			token:   signHS256(t, "top-secret", hs, map[string]interface{}{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix(), "iss": "wallets", "aud": "walletsvc"}),
+			wantErr: xerrors.New("invalid token: token is expired"),
+		},
+		{
+			name:    "not valid yet",
+			token:   signHS256(t, "top-secret", hs, map[string]interface{}{"sub": "alice", "exp": exp, "nbf": time.Now().Add(time.Minute).Unix(), "iss": "wallets", "aud": "walletsvc"}),
+			wantErr: xerrors.New("invalid token: token is not valid yet"),
+		},
+		{
+			name:    "subject is missing",
+			token:   signHS256(t, "top-secret", hs, map[string]interface{}{"exp": exp, "iss": "wallets", "aud": "walletsvc"}),
+			wantErr: xerrors.New("invalid token: subject is missing"),
+		},
+		{
+			name:    "wrong issuer",
+			token:   signHS256(t, "top-secret", hs, map[string]interface{}{"sub": "alice", "exp": exp, "iss": "other", "aud": "walletsvc"}),
+			wantErr: xerrors.New(`invalid token: issuer "other" is not accepted`),
+		},
+		{
+			name:    "wrong audience",
+			token:   signHS256(t, "top-secret", hs, map[string]interface{}{"sub": "alice", "exp": exp, "iss": "wallets", "aud": []string{"other"}}),
+			wantErr: xerrors.New(`invalid token: audience "walletsvc" is not accepted`),
+		},
+		{
+			name:  "hs256 with scope claim",
+			token: signHS256(t, "top-secret", hs, map[string]interface{}{"sub": "alice", "exp": exp, "iss": "wallets", "aud": "walletsvc", "scope": "read admin"}),
+			want:  &entities.Principal{Subject: "alice", Scopes: []string{"read", entities.AdminScope}},
+		},
+		{
+			name:  "rs256 with scopes array",
+			token: signRS256(t, private, rs, map[string]interface{}{"sub": "ops", "exp": exp, "iss": "wallets", "aud": []string{"walletsvc", "other"}, "scopes": []string{entities.AdminScope}}),
+			want:  &entities.Principal{Subject: "ops", Scopes: []string{entities.AdminScope}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokens.Authenticate(context.Background(), tt.token)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !xerrors.As(err, &models.UnauthenticatedError{}) {
+				t.Fatalf("Authenticate() error = %T, want UnauthenticatedError", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Authenticate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+//HTTP asks rates provider with GET <url>?from=USD&to=EUR, provider responds with {"rate": "0.91"}
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTP(url string, timeout time.Duration) services.FXRates {
+	return &HTTP{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+type rateResponse struct {
+	Rate decimal.Decimal `json:"rate"`
+}
+
+func (h *HTTP) Rate(ctx context.Context, from, to entities.Currency) (*entities.FXRate, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("rates request error: %w", err)
+	}
+
+	req.URL.RawQuery = url.Values{"from": {string(from)}, "to": {string(to)}}.Encode()
+
+	resp, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, xerrors.Errorf("rates provider error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, models.NotFoundWrapper{xerrors.Errorf("rate for %s not found", pair(from, to))}
+	default:
+		return nil, xerrors.Errorf("rates provider responded with %s", resp.Status)
+	}
+
+	var rr rateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, xerrors.Errorf("rates response decoding error: %w", err)
+	}
+
+	if !rr.Rate.IsPositive() {
+		return nil, xerrors.Errorf("rates provider returned wrong rate %s for %s", rr.Rate, pair(from, to))
+	}
+
+	return &entities.FXRate{From: from, To: to, Rate: rr.Rate}, nil
+}
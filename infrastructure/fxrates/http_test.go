@@ -0,0 +1,79 @@
+// +build !integration
+
+package fxrates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestHTTP_Rate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("from") + "/" + r.URL.Query().Get("to") {
+		case "USD/EUR":
+			w.Write([]byte(`{"rate":"0.9"}`))
+		case "USD/JPY":
+			w.Write([]byte(`{"rate":"0"}`))
+		case "USD/GBP":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rates := NewHTTP(server.URL, time.Second)
+
+	type args struct {
+		from entities.Currency
+		to   entities.Currency
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *entities.FXRate
+		wantErr error
+	}{
+		{
+			name:    "pair not found",
+			args:    args{from: "EUR", to: "USD"},
+			wantErr: models.NotFoundWrapper{xerrors.New("rate for EUR/USD not found")},
+		},
+		{
+			name:    "provider fails",
+			args:    args{from: "USD", to: "GBP"},
+			wantErr: xerrors.New("rates provider responded with 500 Internal Server Error"),
+		},
+		{
+			name:    "wrong rate",
+			args:    args{from: "USD", to: "JPY"},
+			wantErr: xerrors.New("rates provider returned wrong rate 0 for USD/JPY"),
+		},
+		{
+			name: "works fine",
+			args: args{from: "USD", to: "EUR"},
+			want: &entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.9")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Rate(context.Background(), tt.args.from, tt.args.to)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("Rate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Rate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+//Static serves fixed rates keyed by "FROM/TO" pair, e.g. "USD/EUR"
+type Static struct {
+	rates map[string]decimal.Decimal
+}
+
+func NewStatic(rates map[string]decimal.Decimal) services.FXRates {
+	return &Static{rates: rates}
+}
+
+//NewFile reads static rates from json object like {"USD/EUR": "0.91"}
+func NewFile(path string) (services.FXRates, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("open rates file error: %w", err)
+	}
+	defer f.Close()
+
+	rates := make(map[string]decimal.Decimal)
+	if err := json.NewDecoder(f).Decode(&rates); err != nil {
+		return nil, xerrors.Errorf("decode rates file error: %w", err)
+	}
+
+	return NewStatic(rates), nil
+}
+
+func (s *Static) Rate(ctx context.Context, from, to entities.Currency) (*entities.FXRate, error) {
+	if from == to {
+		return &entities.FXRate{From: from, To: to, Rate: decimal.New(1, 0)}, nil
+	}
+
+	rate, ok := s.rates[pair(from, to)]
+	if !ok {
+		return nil, models.NotFoundWrapper{xerrors.Errorf("rate for %s not found", pair(from, to))}
+	}
+
+	return &entities.FXRate{From: from, To: to, Rate: rate}, nil
+}
+
+func pair(from, to entities.Currency) string {
+	return string(from) + "/" + string(to)
+}
@@ -0,0 +1,90 @@
+// +build !integration
+
+package fxrates
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestStatic_Rate(t *testing.T) {
+	rates := NewStatic(map[string]decimal.Decimal{"USD/EUR": decimal.RequireFromString("0.9")})
+
+	type args struct {
+		from entities.Currency
+		to   entities.Currency
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *entities.FXRate
+		wantErr error
+	}{
+		{
+			name:    "pair not found",
+			args:    args{from: "EUR", to: "USD"},
+			wantErr: models.NotFoundWrapper{xerrors.New("rate for EUR/USD not found")},
+		},
+		{
+			name: "same currencies",
+			args: args{from: "USD", to: "USD"},
+			want: &entities.FXRate{From: "USD", To: "USD", Rate: decimal.New(1, 0)},
+		},
+		{
+			name: "works fine",
+			args: args{from: "USD", to: "EUR"},
+			want: &entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.9")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Rate(context.Background(), tt.args.from, tt.args.to)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("Rate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Rate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "fxrates")
+	if err != nil {
+		t.Fatalf("TempFile error: %+v", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(`{"USD/EUR": "0.9"}`); err != nil {
+		t.Fatalf("WriteString error: %+v", err)
+	}
+	f.Close()
+
+	if _, err := NewFile(f.Name() + ".absent"); err == nil {
+		t.Fatalf("NewFile() expected error for absent file")
+	}
+
+	rates, err := NewFile(f.Name())
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+
+	got, err := rates.Rate(context.Background(), "USD", "EUR")
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+
+	if !got.Rate.Equal(decimal.RequireFromString("0.9")) {
+		t.Fatalf("Rate() got = %v, want 0.9", got.Rate)
+	}
+}
@@ -3,19 +3,31 @@ package gateways
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/NickRI/wallets-task/db/common"
 	"github.com/NickRI/wallets-task/db/models"
 	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/repositories"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"golang.org/x/xerrors"
 )
 
+//accountColumns selects account with available balance, holds past expires_at don't reserve funds
+const accountColumns = `a.id, a.user_name, a.balance,
+	a.balance - COALESCE((SELECT SUM(h.amount) FROM holds h WHERE h.account_id = a.id AND h.status = 'active' AND h.expires_at > NOW()), 0),
+	a.currency, a.status, a.created_at, a.updated_at, a.owner, a.overdraft_limit, a.overdraft_expires_at`
+
 type Accounts struct {
 	listQuery    *listAccountQuery
 	balanceQuery *updateBalanceQuery
 	fetchQuery   *fetchAccountQuery
 	lockQuery    *lockQuery
+	createQuery  *createAccountQuery
+	statusQuery  *updateStatusQuery
+	settleQuery  *createSettlementQuery
+	historyQuery *balanceAtQuery
 }
 
 func NewAccounts(d *sql.DB) (repositories.Accounts, error) {
@@ -42,6 +54,26 @@ func NewAccounts(d *sql.DB) (repositories.Accounts, error) {
 		return nil, xerrors.Errorf("Error preparation newLockQuery: %w", err)
 	}
 
+	table.createQuery, err = newCreateAccountQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation newCreateAccountQuery: %w", err)
+	}
+
+	table.statusQuery, err = newUpdateStatusQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation newUpdateStatusQuery: %w", err)
+	}
+
+	table.settleQuery, err = newCreateSettlementQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation newCreateSettlementQuery: %w", err)
+	}
+
+	table.historyQuery, err = newBalanceAtQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation newBalanceAtQuery: %w", err)
+	}
+
 	return table, nil
 }
 
@@ -64,6 +96,34 @@ func (a *Accounts) List(ctx context.Context) (entities.Accounts, error) {
 	return acList.ToDomain(), nil
 }
 
+func (a *Accounts) Create(ctx context.Context, account *entities.Account) (*entities.Account, error) {
+	row := a.createQuery.QueryRowContext(ctx, account.AccountId, account.Currency, account.Status, account.Owner)
+
+	created := &models.Account{}
+	if err := row.Scan(created.Bind()...); err != nil {
+		return nil, err
+	}
+
+	return created.ToDomain(), nil
+}
+
+//CreateSettlement adds system account for currency unless it exists
+func (a *Accounts) CreateSettlement(ctx context.Context, currency entities.Currency) error {
+	_, err := a.settleQuery.ExecContext(ctx, entities.SettlementAccountId(currency), currency, entities.AccountActive)
+	return err
+}
+
+func (a *Accounts) GetByName(ctx context.Context, accId entities.AccountId) (*entities.Account, error) {
+	row := a.fetchQuery.QueryRowContext(ctx, accId)
+
+	account := &models.Account{}
+	if err := row.Scan(account.Bind()...); err != nil {
+		return nil, err
+	}
+
+	return account.ToDomain(), nil
+}
+
 func (a *Accounts) GetByNameTx(tx *sql.Tx, ctx context.Context, accId entities.AccountId) (*entities.Account, error) {
 	row := tx.StmtContext(ctx, a.fetchQuery.Stmt).QueryRowContext(ctx, accId)
 
@@ -75,13 +135,49 @@ func (a *Accounts) GetByNameTx(tx *sql.Tx, ctx context.Context, accId entities.A
 	return account.ToDomain(), nil
 }
 
-func (a *Accounts) UpdateBalanceTx(tx *sql.Tx, ctx context.Context, account *entities.Account, amount decimal.Decimal) error {
-	_, err := tx.StmtContext(ctx, a.balanceQuery.Stmt).ExecContext(ctx, amount, account.GetId())
+func (a *Accounts) UpdateBalanceTx(tx *sql.Tx, ctx context.Context, account *entities.Account, amount entities.Money) error {
+	value, err := accountAmount(account, amount)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.StmtContext(ctx, a.balanceQuery.Stmt).ExecContext(ctx, value, account.GetId())
 	return err
 }
 
-func (a *Accounts) LockTx(tx *sql.Tx, ctx context.Context) error {
-	_, err := tx.StmtContext(ctx, a.lockQuery.Stmt).ExecContext(ctx)
+//accountAmount returns amount of money which is in currency of the account
+func accountAmount(account *entities.Account, m entities.Money) (decimal.Decimal, error) {
+	if m.Currency != account.Currency {
+		return decimal.Zero, xerrors.Errorf("%s: %s is not in %s: %w", account.AccountId, m, account.Currency, entities.ErrCurrencyMismatch)
+	}
+
+	return m.Amount, nil
+}
+
+//BalanceAt returns balance account had at the moment, zero before account was created
+func (a *Accounts) BalanceAt(ctx context.Context, account *entities.Account, at time.Time) (entities.Money, error) {
+	var balance decimal.Decimal
+	if err := a.historyQuery.QueryRowContext(ctx, account.GetId(), at).Scan(&common.NullDecimal{V: &balance}); err != nil {
+		return entities.NewMoney(decimal.Zero, account.Currency), err
+	}
+
+	return entities.NewMoney(balance, account.Currency), nil
+}
+
+func (a *Accounts) UpdateStatusTx(tx *sql.Tx, ctx context.Context, account *entities.Account, status entities.AccountStatus) error {
+	_, err := tx.StmtContext(ctx, a.statusQuery.Stmt).ExecContext(ctx, status, account.GetId())
+	return err
+}
+
+//LockTx locks rows of accounts by their names until the end of tx, rows are locked in order of their ids,
+//so transactions locking the same accounts wait for each other instead of deadlocking. Missing accounts are skipped
+func (a *Accounts) LockTx(tx *sql.Tx, ctx context.Context, accIds ...entities.AccountId) error {
+	names := make([]string, 0, len(accIds))
+	for _, accId := range accIds {
+		names = append(names, string(accId))
+	}
+
+	_, err := tx.StmtContext(ctx, a.lockQuery.Stmt).ExecContext(ctx, pq.Array(names))
 	return err
 }
 
@@ -103,7 +199,7 @@ type listAccountQuery struct {
 }
 
 func newListAccountQuery(d *sql.DB) (*listAccountQuery, error) {
-	stmt, err := d.Prepare("SELECT id, user_name, balance, currency, created_at, updated_at FROM accounts")
+	stmt, err := d.Prepare("SELECT " + accountColumns + " FROM accounts a")
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +212,7 @@ type fetchAccountQuery struct {
 }
 
 func newFetchAccountQuery(d *sql.DB) (*fetchAccountQuery, error) {
-	stmt, err := d.Prepare(`SELECT id, user_name, balance, currency, created_at, updated_at FROM accounts WHERE user_name = $1`)
+	stmt, err := d.Prepare("SELECT " + accountColumns + " FROM accounts a WHERE a.user_name = $1")
 	if err != nil {
 		return nil, err
 	}
@@ -129,10 +225,74 @@ type lockQuery struct {
 }
 
 func newLockQuery(d *sql.DB) (*lockQuery, error) {
-	stmt, err := d.Prepare(`LOCK TABLE accounts IN SHARE UPDATE EXCLUSIVE MODE`)
+	stmt, err := d.Prepare(`SELECT a.id FROM accounts a WHERE a.user_name = ANY($1) ORDER BY a.id FOR UPDATE`)
 	if err != nil {
 		return nil, err
 	}
 
 	return &lockQuery{stmt}, nil
 }
+
+type createAccountQuery struct {
+	*sql.Stmt
+}
+
+func newCreateAccountQuery(d *sql.DB) (*createAccountQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO accounts (id, user_name, balance, currency, status, owner, created_at, updated_at)
+		VALUES (DEFAULT, $1, 0, $2, $3, NULLIF($4, ''), DEFAULT, DEFAULT)
+		RETURNING id, user_name, balance, balance, currency, status, created_at, updated_at, owner, overdraft_limit, overdraft_expires_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createAccountQuery{stmt}, nil
+}
+
+type updateStatusQuery struct {
+	*sql.Stmt
+}
+
+func newUpdateStatusQuery(d *sql.DB) (*updateStatusQuery, error) {
+	stmt, err := d.Prepare("UPDATE accounts SET status = $1, updated_at = NOW() WHERE id = $2")
+	if err != nil {
+		return nil, err
+	}
+
+	return &updateStatusQuery{stmt}, nil
+}
+
+type createSettlementQuery struct {
+	*sql.Stmt
+}
+
+func newCreateSettlementQuery(d *sql.DB) (*createSettlementQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO accounts (id, user_name, balance, currency, status, created_at, updated_at)
+		VALUES (DEFAULT, $1, 0, $2, $3, DEFAULT, DEFAULT)
+		ON CONFLICT (user_name) DO NOTHING
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createSettlementQuery{stmt}, nil
+}
+
+type balanceAtQuery struct {
+	*sql.Stmt
+}
+
+//newBalanceAtQuery derives balance from opening balance and all the legs made up to the moment,
+//accounts.balance is maintained by the same legs so both agree at the present
+func newBalanceAtQuery(d *sql.DB) (*balanceAtQuery, error) {
+	stmt, err := d.Prepare(`SELECT CASE WHEN a.created_at > $2 THEN 0
+			ELSE a.opening_balance + COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.account_id = a.id AND p.created_at <= $2), 0)
+		END
+		FROM accounts a WHERE a.id = $1`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balanceAtQuery{stmt}, nil
+}
@@ -26,6 +26,10 @@ func TestNewAccounts(t *testing.T) {
 	newUpdateBalanceError := xerrors.New("new_update_balance_error")
 	newFetchAccountError := xerrors.New("new_fetch_account_error")
 	newLockQueryError := xerrors.New("new_lock_query_error")
+	newCreateAccountError := xerrors.New("new_create_account_error")
+	newUpdateStatusError := xerrors.New("new_update_status_error")
+	newCreateSettlementError := xerrors.New("new_create_settlement_error")
+	newBalanceAtError := xerrors.New("new_balance_at_error")
 
 	tests := []struct {
 		name    string
@@ -65,18 +69,76 @@ func TestNewAccounts(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE").
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE").
 					WillReturnError(newLockQueryError)
 			},
 			wantErr: newLockQueryError,
 		},
+		{
+			name: "newCreateAccountQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*").
+					WillReturnError(newCreateAccountError)
+			},
+			wantErr: newCreateAccountError,
+		},
+		{
+			name: "newUpdateStatusQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*").
+					WillReturnError(newUpdateStatusError)
+			},
+			wantErr: newUpdateStatusError,
+		},
+		{
+			name: "newCreateSettlementQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*").
+					WillReturnError(newCreateSettlementError)
+			},
+			wantErr: newCreateSettlementError,
+		},
+		{
+			name: "newBalanceAtQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*").
+					WillReturnError(newBalanceAtError)
+			},
+			wantErr: newBalanceAtError,
+		},
 		{
 			name: "works well",
 			before: func() {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 			},
 			want: &Accounts{},
 		},
@@ -95,6 +157,10 @@ func TestNewAccounts(t *testing.T) {
 				got.(*Accounts).balanceQuery = tt.want.(*Accounts).balanceQuery
 				got.(*Accounts).fetchQuery = tt.want.(*Accounts).fetchQuery
 				got.(*Accounts).lockQuery = tt.want.(*Accounts).lockQuery
+				got.(*Accounts).createQuery = tt.want.(*Accounts).createQuery
+				got.(*Accounts).statusQuery = tt.want.(*Accounts).statusQuery
+				got.(*Accounts).settleQuery = tt.want.(*Accounts).settleQuery
+				got.(*Accounts).historyQuery = tt.want.(*Accounts).historyQuery
 			}
 
 			if !reflect.DeepEqual(got, tt.want) {
@@ -111,14 +177,14 @@ func TestAccounts_List(t *testing.T) {
 	}
 	defer db.Close()
 
-	var rows = sqlmock.NewRows([]string{"id", "user_name", "balance", "currency", "created_at", "updated_at"})
-	var testAccount = &entities.Account{AccountId: "user_name", Balance: decimal.NewFromFloat(4.124), Currency: "USD"}
+	var rows = sqlmock.NewRows([]string{"id", "user_name", "balance", "available_balance", "currency", "status", "created_at", "updated_at", "owner", "overdraft_limit", "overdraft_expires_at"})
+	var testAccount = &entities.Account{AccountId: "user_name", Balance: decimal.NewFromFloat(4.124), AvailableBalance: decimal.NewFromFloat(3.124), Currency: "USD", Status: entities.AccountActive, Owner: "alice"}
 
 	queryContextError := xerrors.New("query_context_error")
 
 	testAccount.SetId(1)
 
-	testRow := []driver.Value{testAccount.GetId(), testAccount.AccountId, testAccount.Balance, testAccount.Currency, time.Now(), time.Now()}
+	testRow := []driver.Value{testAccount.GetId(), testAccount.AccountId, testAccount.Balance, testAccount.AvailableBalance, testAccount.Currency, testAccount.Status, time.Now(), time.Now(), testAccount.Owner, nil, nil}
 
 	type args struct {
 		ctx context.Context
@@ -137,7 +203,11 @@ func TestAccounts_List(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 				mock.ExpectQuery("SELECT .* FROM accounts").WillReturnError(queryContextError)
 			},
 			wantErr: queryContextError,
@@ -149,7 +219,11 @@ func TestAccounts_List(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 				mock.ExpectQuery("SELECT .* FROM accounts").
 					WillReturnRows(rows.AddRow(testRow...))
 
@@ -189,11 +263,14 @@ func TestAccounts_GetByNameTx(t *testing.T) {
 	}
 	defer db.Close()
 
-	var rows = sqlmock.NewRows([]string{"id", "user_name", "balance", "currency", "created_at", "updated_at"})
-	var testAccount = &entities.Account{AccountId: "user_name", Balance: decimal.NewFromFloat(4.124), Currency: "USD"}
+	var rows = sqlmock.NewRows([]string{"id", "user_name", "balance", "available_balance", "currency", "status", "created_at", "updated_at", "owner", "overdraft_limit", "overdraft_expires_at"})
+	var testOverdraftLimit = decimal.New(10, 0)
+	var testExpiresAt = time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+	var testAccount = &entities.Account{AccountId: "user_name", Balance: decimal.NewFromFloat(-4.124), AvailableBalance: decimal.NewFromFloat(-5.124), Currency: "USD", Status: entities.AccountActive, Owner: "alice",
+		OverdraftLimit: &testOverdraftLimit, OverdraftExpiresAt: &testExpiresAt, Overdrawn: true}
 	testAccount.SetId(1)
 
-	testRow := []driver.Value{testAccount.GetId(), testAccount.AccountId, testAccount.Balance, testAccount.Currency, time.Now(), time.Now()}
+	testRow := []driver.Value{testAccount.GetId(), testAccount.AccountId, testAccount.Balance, testAccount.AvailableBalance, testAccount.Currency, testAccount.Status, time.Now(), time.Now(), testAccount.Owner, testOverdraftLimit, testExpiresAt}
 
 	queryRowContextError := xerrors.New("query_row_context_error")
 
@@ -218,7 +295,11 @@ func TestAccounts_GetByNameTx(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 
 				mock.ExpectBegin()
 				mock.ExpectQuery("SELECT .* WHERE .*").
@@ -237,7 +318,11 @@ func TestAccounts_GetByNameTx(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 
 				mock.ExpectBegin()
 				mock.ExpectQuery("SELECT .* WHERE .*").
@@ -283,7 +368,7 @@ func TestAccounts_UpdateBalanceTx(t *testing.T) {
 	type args struct {
 		ctx     context.Context
 		account *entities.Account
-		amount  decimal.Decimal
+		amount  entities.Money
 	}
 	tests := []struct {
 		name    string
@@ -300,7 +385,7 @@ func TestAccounts_UpdateBalanceTx(t *testing.T) {
 					Balance:   decimal.NewFromFloat(4.212),
 					Currency:  "USD",
 				},
-				amount: decimal.NewFromFloat(1.212),
+				amount: entities.NewMoney(decimal.NewFromFloat(1.212), "USD"),
 			},
 			before: func(a *args) {
 				a.account.SetId(1)
@@ -308,16 +393,47 @@ func TestAccounts_UpdateBalanceTx(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 
 				mock.ExpectBegin()
 				mock.ExpectExec("UPDATE accounts SET .*").
-					WithArgs(a.amount, a.account.GetId()).
+					WithArgs(a.amount.Amount, a.account.GetId()).
 					WillReturnError(execContextError).
 					WillReturnResult(sqlmock.NewErrorResult(execContextError))
 			},
 			wantErr: execContextError,
 		},
+		{
+			name: "amount in another currency",
+			args: args{
+				ctx: context.Background(),
+				account: &entities.Account{
+					AccountId: "alice123",
+					Balance:   decimal.NewFromFloat(4.212),
+					Currency:  "USD",
+				},
+				amount: entities.NewMoney(decimal.NewFromFloat(1.212), "EUR"),
+			},
+			before: func(a *args) {
+				a.account.SetId(1)
+
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectBegin()
+			},
+			wantErr: entities.ErrCurrencyMismatch,
+		},
 		{
 			name: "working fine",
 			args: args{
@@ -327,7 +443,7 @@ func TestAccounts_UpdateBalanceTx(t *testing.T) {
 					Balance:   decimal.NewFromFloat(4.212),
 					Currency:  "USD",
 				},
-				amount: decimal.NewFromFloat(1.212),
+				amount: entities.NewMoney(decimal.NewFromFloat(1.212), "USD"),
 			},
 			before: func(a *args) {
 				a.account.SetId(1)
@@ -335,11 +451,15 @@ func TestAccounts_UpdateBalanceTx(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 
 				mock.ExpectBegin()
 				mock.ExpectExec("UPDATE accounts SET .*").
-					WithArgs(a.amount, a.account.GetId()).
+					WithArgs(a.amount.Amount, a.account.GetId()).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -365,3 +485,506 @@ func TestAccounts_UpdateBalanceTx(t *testing.T) {
 		})
 	}
 }
+
+func TestAccounts_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var rows = sqlmock.NewRows([]string{"id", "user_name", "balance", "available_balance", "currency", "status", "created_at", "updated_at", "owner", "overdraft_limit", "overdraft_expires_at"})
+	var testAccount = &entities.Account{AccountId: "carol789", Balance: decimal.New(0, 0), AvailableBalance: decimal.New(0, 0), Currency: "USD", Status: entities.AccountActive, Owner: "alice"}
+	testAccount.SetId(3)
+
+	testRow := []driver.Value{testAccount.GetId(), testAccount.AccountId, testAccount.Balance, testAccount.AvailableBalance, testAccount.Currency, testAccount.Status, time.Now(), time.Now(), testAccount.Owner, nil, nil}
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	type args struct {
+		ctx     context.Context
+		account *entities.Account
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Account
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			args: args{
+				ctx:     context.Background(),
+				account: &entities.Account{AccountId: "carol789", Currency: "USD", Status: entities.AccountActive, Owner: "alice"},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectQuery("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*").
+					WithArgs(a.account.AccountId, a.account.Currency, a.account.Status, a.account.Owner).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "working fine",
+			args: args{
+				ctx:     context.Background(),
+				account: &entities.Account{AccountId: "carol789", Currency: "USD", Status: entities.AccountActive, Owner: "alice"},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectQuery("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*").
+					WithArgs(a.account.AccountId, a.account.Currency, a.account.Status, a.account.Owner).
+					WillReturnRows(rows.AddRow(testRow...))
+			},
+			want: testAccount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			a, err := NewAccounts(db)
+			if err != nil {
+				t.Fatalf("NewAccounts error: %+v", err)
+			}
+
+			got, err := a.Create(tt.args.ctx, tt.args.account)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Create() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccounts_CreateSettlement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	execContextError := xerrors.New("exec_context_error")
+
+	type args struct {
+		ctx      context.Context
+		currency entities.Currency
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		wantErr error
+	}{
+		{
+			name: "ExecContext returns error",
+			args: args{ctx: context.Background(), currency: "USD"},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectExec("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*").
+					WithArgs("settlement:USD", a.currency, entities.AccountActive).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name: "working fine",
+			args: args{ctx: context.Background(), currency: "USD"},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectExec("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*").
+					WithArgs("settlement:USD", a.currency, entities.AccountActive).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			a, err := NewAccounts(db)
+			if err != nil {
+				t.Fatalf("NewAccounts error: %+v", err)
+			}
+
+			err = a.CreateSettlement(tt.args.ctx, tt.args.currency)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("CreateSettlement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccounts_GetByName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var rows = sqlmock.NewRows([]string{"id", "user_name", "balance", "available_balance", "currency", "status", "created_at", "updated_at", "owner", "overdraft_limit", "overdraft_expires_at"})
+	var testAccount = &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(4.124), AvailableBalance: decimal.NewFromFloat(4.124), Currency: "USD", Status: entities.AccountFrozen}
+	testAccount.SetId(2)
+
+	testRow := []driver.Value{testAccount.GetId(), testAccount.AccountId, testAccount.Balance, testAccount.AvailableBalance, testAccount.Currency, testAccount.Status, time.Now(), time.Now(), testAccount.Owner, nil, nil}
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	type args struct {
+		ctx   context.Context
+		accId entities.AccountId
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Account
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			args: args{
+				ctx:   context.Background(),
+				accId: "alice123",
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectQuery("SELECT .* WHERE .*").
+					WithArgs(a.accId).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "working fine",
+			args: args{
+				ctx:   context.Background(),
+				accId: "bob456",
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectQuery("SELECT .* WHERE .*").
+					WithArgs(a.accId).
+					WillReturnRows(rows.AddRow(testRow...))
+			},
+			want: testAccount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			a, err := NewAccounts(db)
+			if err != nil {
+				t.Fatalf("NewAccounts error: %+v", err)
+			}
+
+			got, err := a.GetByName(tt.args.ctx, tt.args.accId)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetByName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetByName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccounts_UpdateStatusTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	execContextError := xerrors.New("exec_context_error")
+
+	type args struct {
+		ctx     context.Context
+		account *entities.Account
+		status  entities.AccountStatus
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		wantErr error
+	}{
+		{
+			name: "ExecContext returns error",
+			args: args{
+				ctx:     context.Background(),
+				account: &entities.Account{AccountId: "alice123", Currency: "USD", Status: entities.AccountActive, Owner: "alice"},
+				status:  entities.AccountFrozen,
+			},
+			before: func(a *args) {
+				a.account.SetId(1)
+
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET status .*").
+					WithArgs(a.status, a.account.GetId()).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name: "working fine",
+			args: args{
+				ctx:     context.Background(),
+				account: &entities.Account{AccountId: "alice123", Currency: "USD", Status: entities.AccountActive, Owner: "alice"},
+				status:  entities.AccountFrozen,
+			},
+			before: func(a *args) {
+				a.account.SetId(1)
+
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET status .*").
+					WithArgs(a.status, a.account.GetId()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			a, err := NewAccounts(db)
+			if err != nil {
+				t.Fatalf("NewAccounts error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			err = a.UpdateStatusTx(tx, tt.args.ctx, tt.args.account, tt.args.status)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("UpdateStatusTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccounts_LockTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	execContextError := xerrors.New("exec_context_error")
+
+	type args struct {
+		ctx    context.Context
+		accIds []entities.AccountId
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		wantErr error
+	}{
+		{
+			name: "ExecContext returns error",
+			args: args{
+				ctx:    context.Background(),
+				accIds: []entities.AccountId{"bob456", "alice123"},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE").
+					WithArgs(`{"bob456","alice123"}`).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name: "working fine",
+			args: args{
+				ctx:    context.Background(),
+				accIds: []entities.AccountId{"bob456", "alice123"},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE").
+					WithArgs(`{"bob456","alice123"}`).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			a, err := NewAccounts(db)
+			if err != nil {
+				t.Fatalf("NewAccounts error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			err = a.LockTx(tx, tt.args.ctx, tt.args.accIds...)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("LockTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccounts_BalanceAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var testAccount = &entities.Account{AccountId: "bob456", Currency: "USD", Status: entities.AccountActive, Owner: "alice"}
+	testAccount.SetId(2)
+	testAt := time.Date(2019, 7, 31, 23, 59, 59, 0, time.UTC)
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    entities.Money
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			before: func() {
+				mock.ExpectQuery("SELECT CASE .* FROM accounts a WHERE a.id = .*").
+					WithArgs(testAccount.GetId(), testAt).
+					WillReturnError(queryRowContextError)
+			},
+			want:    entities.NewMoney(decimal.Zero, "USD"),
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "working fine",
+			before: func() {
+				mock.ExpectQuery("SELECT CASE .* FROM accounts a WHERE a.id = .*").
+					WithArgs(testAccount.GetId(), testAt).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow("995.5324425"))
+			},
+			want: entities.NewMoney(decimal.RequireFromString("995.5324425"), "USD"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM accounts")
+			mock.ExpectPrepare("UPDATE accounts SET .*")
+			mock.ExpectPrepare("SELECT .* WHERE .*")
+			mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+			mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+			mock.ExpectPrepare("UPDATE accounts SET status .*")
+			mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+			mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+			tt.before()
+
+			a, err := NewAccounts(db)
+			if err != nil {
+				t.Fatalf("NewAccounts error: %+v", err)
+			}
+
+			got, err := a.BalanceAt(context.Background(), testAccount, testAt)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("BalanceAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Fatalf("BalanceAt() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/xerrors"
+)
+
+type Holds struct {
+	fetchQuery  *fetchHoldQuery
+	createQuery *createHoldQuery
+	statusQuery *updateHoldStatusQuery
+}
+
+func NewHolds(d *sql.DB) (repositories.Holds, error) {
+	var err error
+	table := new(Holds)
+
+	table.fetchQuery, err = newFetchHoldQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation fetchHoldQuery: %w", err)
+	}
+
+	table.createQuery, err = newCreateHoldQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation createHoldQuery: %w", err)
+	}
+
+	table.statusQuery, err = newUpdateHoldStatusQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation updateHoldStatusQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//GetById returns hold by id, malformed id is reported as sql.ErrNoRows
+func (h *Holds) GetById(ctx context.Context, id string) (*entities.Hold, error) {
+	guid, err := uuid.FromString(id)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return h.scan(h.fetchQuery.QueryRowContext(ctx, guid.Bytes()))
+}
+
+//GetByIdTx returns hold by id, malformed id is reported as sql.ErrNoRows
+func (h *Holds) GetByIdTx(tx *sql.Tx, ctx context.Context, id string) (*entities.Hold, error) {
+	guid, err := uuid.FromString(id)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return h.scan(tx.StmtContext(ctx, h.fetchQuery.Stmt).QueryRowContext(ctx, guid.Bytes()))
+}
+
+func (h *Holds) scan(row *sql.Row) (*entities.Hold, error) {
+	hold := &models.Hold{}
+	if err := row.Scan(hold.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return hold.ToDomain(), nil
+}
+
+//CreateTx reserves amount of credit account for the payment to debit until expiresAt
+func (h *Holds) CreateTx(tx *sql.Tx, ctx context.Context, credit, debit *entities.Account, amount entities.Money, expiresAt time.Time) (*entities.Hold, error) {
+	value, err := accountAmount(credit, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := models.NewHold(credit, debit, value, expiresAt)
+
+	if _, err := tx.StmtContext(ctx, h.createQuery.Stmt).ExecContext(ctx, hold.Bind()...); err != nil {
+		return nil, err
+	}
+
+	return hold.ToDomain(), nil
+}
+
+//CaptureTx finishes hold with the amount actually paid, the rest is released
+func (h *Holds) CaptureTx(tx *sql.Tx, ctx context.Context, hold *entities.Hold, amount entities.Money) error {
+	if amount.Currency != hold.Amount.Currency {
+		return xerrors.Errorf("hold %s: %s is not in %s: %w", hold.Id, amount, hold.Amount.Currency, entities.ErrCurrencyMismatch)
+	}
+
+	return h.updateStatusTx(tx, ctx, hold, entities.HoldCaptured, common.NullDecimal{V: &amount.Amount})
+}
+
+//VoidTx releases whole amount of hold
+func (h *Holds) VoidTx(tx *sql.Tx, ctx context.Context, hold *entities.Hold) error {
+	return h.updateStatusTx(tx, ctx, hold, entities.HoldVoided, common.NullDecimal{})
+}
+
+func (h *Holds) updateStatusTx(tx *sql.Tx, ctx context.Context, hold *entities.Hold, status entities.HoldStatus, captured common.NullDecimal) error {
+	guid, err := uuid.FromString(hold.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.StmtContext(ctx, h.statusQuery.Stmt).ExecContext(ctx, status, captured, guid.Bytes())
+	return err
+}
+
+type fetchHoldQuery struct {
+	*sql.Stmt
+}
+
+func newFetchHoldQuery(d *sql.DB) (*fetchHoldQuery, error) {
+	stmt, err := d.Prepare(`SELECT h.id, h.guid, h.account_id, h.to_account_id, h.amount, h.captured_amount, h.status,
+		h.expires_at, h.created_at, h.updated_at, a1.user_name, a2.user_name, a1.currency
+		FROM holds h
+		INNER JOIN accounts a1 ON a1.id = h.account_id
+		INNER JOIN accounts a2 ON a2.id = h.to_account_id
+		WHERE h.guid = $1`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetchHoldQuery{stmt}, nil
+}
+
+type createHoldQuery struct {
+	*sql.Stmt
+}
+
+func newCreateHoldQuery(d *sql.DB) (*createHoldQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO holds (id, guid, account_id, to_account_id, amount, status, expires_at, created_at, updated_at)
+		VALUES (DEFAULT, $1, $2, $3, $4, $5, $6, DEFAULT, DEFAULT)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createHoldQuery{stmt}, nil
+}
+
+type updateHoldStatusQuery struct {
+	*sql.Stmt
+}
+
+func newUpdateHoldStatusQuery(d *sql.DB) (*updateHoldStatusQuery, error) {
+	stmt, err := d.Prepare("UPDATE holds SET status = $1, captured_amount = $2, updated_at = NOW() WHERE guid = $3")
+	if err != nil {
+		return nil, err
+	}
+
+	return &updateHoldStatusQuery{stmt}, nil
+}
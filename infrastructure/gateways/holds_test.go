@@ -0,0 +1,426 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func expectHoldsPrepare(mock sqlmock.Sqlmock) {
+	mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*")
+	mock.ExpectPrepare("INSERT INTO holds (.*) VALUES (.*)")
+	mock.ExpectPrepare("UPDATE holds SET .*")
+}
+
+func TestNewHolds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	newFetchHoldError := xerrors.New("new_fetch_hold_error")
+	newCreateHoldError := xerrors.New("new_create_hold_error")
+	newUpdateHoldStatusError := xerrors.New("new_update_hold_status_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    repositories.Holds
+		wantErr error
+	}{
+		{
+			name: "newFetchHoldQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*").
+					WillReturnError(newFetchHoldError)
+			},
+			wantErr: newFetchHoldError,
+		},
+		{
+			name: "newCreateHoldQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*")
+				mock.ExpectPrepare("INSERT INTO holds (.*) VALUES (.*)").
+					WillReturnError(newCreateHoldError)
+			},
+			wantErr: newCreateHoldError,
+		},
+		{
+			name: "newUpdateHoldStatusQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*")
+				mock.ExpectPrepare("INSERT INTO holds (.*) VALUES (.*)")
+				mock.ExpectPrepare("UPDATE holds SET .*").
+					WillReturnError(newUpdateHoldStatusError)
+			},
+			wantErr: newUpdateHoldStatusError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				expectHoldsPrepare(mock)
+			},
+			want: &Holds{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+			got, err := NewHolds(db)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("NewHolds() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil {
+				got.(*Holds).fetchQuery = tt.want.(*Holds).fetchQuery
+				got.(*Holds).createQuery = tt.want.(*Holds).createQuery
+				got.(*Holds).statusQuery = tt.want.(*Holds).statusQuery
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewHolds() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHolds_GetByIdTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"h.id", "h.guid", "h.account_id", "h.to_account_id", "h.amount", "h.captured_amount", "h.status",
+		"h.expires_at", "h.created_at", "h.updated_at", "a1.user_name", "a2.user_name", "a1.currency"}
+
+	testGuid := uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665")
+	testAmount := decimal.RequireFromString("5.5")
+	testCapturedAmount := decimal.RequireFromString("4")
+	testCaptured := entities.NewMoney(testCapturedAmount, "USD")
+	testCreatedAt := time.Unix(1563494400, 0)
+	testExpiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	type args struct {
+		ctx context.Context
+		id  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Hold
+		wantErr error
+	}{
+		{
+			name: "malformed id",
+			args: args{ctx: context.Background(), id: "abc"},
+			before: func(a *args) {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "QueryRowContext returns error",
+			args: args{ctx: context.Background(), id: testGuid.String()},
+			before: func(a *args) {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM holds .* WHERE .*").
+					WithArgs(testGuid.Bytes()).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "active hold",
+			args: args{ctx: context.Background(), id: testGuid.String()},
+			before: func(a *args) {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM holds .* WHERE .*").
+					WithArgs(testGuid.Bytes()).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						[]driver.Value{1, testGuid.Bytes(), 1, 2, testAmount, nil, "active",
+							testExpiresAt, testCreatedAt, testCreatedAt, "alice123", "bob456", "USD"}...,
+					))
+			},
+			want: &entities.Hold{
+				Id:        testGuid.String(),
+				Account:   "alice123",
+				ToAccount: "bob456",
+				Amount:    entities.NewMoney(testAmount, "USD"),
+				Status:    entities.HoldActive,
+				ExpiresAt: testExpiresAt,
+			},
+		},
+		{
+			name: "active hold past expiration is expired",
+			args: args{ctx: context.Background(), id: testGuid.String()},
+			before: func(a *args) {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM holds .* WHERE .*").
+					WithArgs(testGuid.Bytes()).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						[]driver.Value{1, testGuid.Bytes(), 1, 2, testAmount, nil, "active",
+							testCreatedAt, testCreatedAt, testCreatedAt, "alice123", "bob456", "USD"}...,
+					))
+			},
+			want: &entities.Hold{
+				Id:        testGuid.String(),
+				Account:   "alice123",
+				ToAccount: "bob456",
+				Amount:    entities.NewMoney(testAmount, "USD"),
+				Status:    entities.HoldExpired,
+				ExpiresAt: testCreatedAt,
+			},
+		},
+		{
+			name: "captured hold",
+			args: args{ctx: context.Background(), id: testGuid.String()},
+			before: func(a *args) {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM holds .* WHERE .*").
+					WithArgs(testGuid.Bytes()).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						[]driver.Value{1, testGuid.Bytes(), 1, 2, testAmount, testCapturedAmount, "captured",
+							testCreatedAt, testCreatedAt, testCreatedAt, "alice123", "bob456", "USD"}...,
+					))
+			},
+			want: &entities.Hold{
+				Id:             testGuid.String(),
+				Account:        "alice123",
+				ToAccount:      "bob456",
+				Amount:         entities.NewMoney(testAmount, "USD"),
+				CapturedAmount: &testCaptured,
+				Status:         entities.HoldCaptured,
+				ExpiresAt:      testCreatedAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			h, err := NewHolds(db)
+			if err != nil {
+				t.Fatalf("NewHolds error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := h.GetByIdTx(tx, tt.args.ctx, tt.args.id)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetByIdTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetByIdTx() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHolds_CreateTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	execContextError := xerrors.New("exec_context_error")
+
+	credit := &entities.Account{AccountId: "alice123", Currency: "USD"}
+	credit.SetId(1)
+	debit := &entities.Account{AccountId: "bob456"}
+	debit.SetId(2)
+
+	testAmount := entities.NewMoney(decimal.RequireFromString("5.5"), "USD")
+	testExpiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    *entities.Hold
+		wantErr error
+	}{
+		{
+			name: "ExecContext returns error",
+			before: func() {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO holds (.*) VALUES (.*)").
+					WithArgs(sqlmock.AnyArg(), 1, 2, testAmount.Amount, "active", testExpiresAt).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO holds (.*) VALUES (.*)").
+					WithArgs(sqlmock.AnyArg(), 1, 2, testAmount.Amount, "active", testExpiresAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			want: &entities.Hold{
+				Account:   "alice123",
+				ToAccount: "bob456",
+				Amount:    testAmount,
+				Status:    entities.HoldActive,
+				ExpiresAt: testExpiresAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+
+			h, err := NewHolds(db)
+			if err != nil {
+				t.Fatalf("NewHolds error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := h.CreateTx(tx, context.Background(), credit, debit, testAmount, testExpiresAt)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("CreateTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != nil {
+				if _, err := uuid.FromString(got.Id); err != nil {
+					t.Fatalf("CreateTx() got malformed id %s", got.Id)
+				}
+				got.Id = ""
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CreateTx() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHolds_UpdateStatusTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	execContextError := xerrors.New("exec_context_error")
+
+	testGuid := uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665")
+	testAmount := entities.NewMoney(decimal.RequireFromString("4"), "USD")
+	testHold := &entities.Hold{Id: testGuid.String(), Amount: entities.NewMoney(decimal.RequireFromString("5"), "USD")}
+
+	tests := []struct {
+		name    string
+		before  func()
+		call    func(repositories.Holds, *sql.Tx) error
+		wantErr error
+	}{
+		{
+			name: "capture returns error",
+			before: func() {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE holds SET .*").
+					WithArgs(entities.HoldCaptured, testAmount.Amount.String(), testGuid.Bytes()).
+					WillReturnError(execContextError)
+			},
+			call: func(h repositories.Holds, tx *sql.Tx) error {
+				return h.CaptureTx(tx, context.Background(), testHold, testAmount)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name: "capture works well",
+			before: func() {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE holds SET .*").
+					WithArgs(entities.HoldCaptured, testAmount.Amount.String(), testGuid.Bytes()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(h repositories.Holds, tx *sql.Tx) error {
+				return h.CaptureTx(tx, context.Background(), testHold, testAmount)
+			},
+		},
+		{
+			name: "capture in another currency",
+			before: func() {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+			},
+			call: func(h repositories.Holds, tx *sql.Tx) error {
+				return h.CaptureTx(tx, context.Background(), testHold, entities.NewMoney(testAmount.Amount, "EUR"))
+			},
+			wantErr: entities.ErrCurrencyMismatch,
+		},
+		{
+			name: "void works well",
+			before: func() {
+				expectHoldsPrepare(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE holds SET .*").
+					WithArgs(entities.HoldVoided, nil, testGuid.Bytes()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(h repositories.Holds, tx *sql.Tx) error {
+				return h.VoidTx(tx, context.Background(), testHold)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+
+			h, err := NewHolds(db)
+			if err != nil {
+				t.Fatalf("NewHolds error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			err = tt.call(h, tx)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("UpdateStatusTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"golang.org/x/xerrors"
+)
+
+type IdempotencyKeys struct {
+	fetchQuery  *fetchIdempotencyKeyQuery
+	createQuery *createIdempotencyKeyQuery
+}
+
+func NewIdempotencyKeys(d *sql.DB) (repositories.IdempotencyKeys, error) {
+	var err error
+	table := new(IdempotencyKeys)
+
+	table.fetchQuery, err = newFetchIdempotencyKeyQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation fetchIdempotencyKeyQuery: %w", err)
+	}
+
+	table.createQuery, err = newCreateIdempotencyKeyQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation createIdempotencyKeyQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//GetByKeyTx returns key created after since moment, older keys are treated as not existing
+func (ik *IdempotencyKeys) GetByKeyTx(tx *sql.Tx, ctx context.Context, key string, since time.Time) (*entities.IdempotencyKey, error) {
+	row := tx.StmtContext(ctx, ik.fetchQuery.Stmt).QueryRowContext(ctx, key, since)
+
+	ikey := &models.IdempotencyKey{}
+	if err := row.Scan(ikey.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return ikey.ToDomain()
+}
+
+//AddTx stores key, replacing the expired one with the same value
+func (ik *IdempotencyKeys) AddTx(tx *sql.Tx, ctx context.Context, key *entities.IdempotencyKey) error {
+	ikey, err := models.NewIdempotencyKey(key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.StmtContext(ctx, ik.createQuery.Stmt).ExecContext(ctx, ikey.Bind()...); err != nil {
+		return models.DBErrorWrapper{err}
+	}
+
+	return nil
+}
+
+type fetchIdempotencyKeyQuery struct {
+	*sql.Stmt
+}
+
+func newFetchIdempotencyKeyQuery(d *sql.DB) (*fetchIdempotencyKeyQuery, error) {
+	stmt, err := d.Prepare(`SELECT id, idempotency_key, fingerprint, ledger, created_at, updated_at
+		FROM idempotency_keys WHERE idempotency_key = $1 AND created_at > $2`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetchIdempotencyKeyQuery{stmt}, nil
+}
+
+type createIdempotencyKeyQuery struct {
+	*sql.Stmt
+}
+
+func newCreateIdempotencyKeyQuery(d *sql.DB) (*createIdempotencyKeyQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO idempotency_keys (id, idempotency_key, fingerprint, ledger, created_at, updated_at)
+		VALUES (DEFAULT, $1, $2, $3, DEFAULT, DEFAULT)
+		ON CONFLICT (idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, ledger = EXCLUDED.ledger, created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createIdempotencyKeyQuery{stmt}, nil
+}
@@ -0,0 +1,330 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestNewIdempotencyKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	newFetchKeyError := xerrors.New("new_fetch_key_error")
+	newCreateKeyError := xerrors.New("new_create_key_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    repositories.IdempotencyKeys
+		wantErr error
+	}{
+		{
+			name: "newFetchIdempotencyKeyQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*").
+					WillReturnError(newFetchKeyError)
+			},
+			wantErr: newFetchKeyError,
+		},
+		{
+			name: "newCreateIdempotencyKeyQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)").
+					WillReturnError(newCreateKeyError)
+			},
+			wantErr: newCreateKeyError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+			},
+			want: &IdempotencyKeys{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+			got, err := NewIdempotencyKeys(db)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("NewIdempotencyKeys() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil {
+				got.(*IdempotencyKeys).fetchQuery = tt.want.(*IdempotencyKeys).fetchQuery
+				got.(*IdempotencyKeys).createQuery = tt.want.(*IdempotencyKeys).createQuery
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewIdempotencyKeys() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeys_GetByKeyTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	var rows = sqlmock.NewRows([]string{"id", "idempotency_key", "fingerprint", "ledger", "created_at", "updated_at"})
+
+	testAmount := entities.NewMoney(decimal.NewFromFloat(4.124), "USD")
+	testCreatedAt := time.Now()
+	testKey := entities.NewIdempotencyKey("key", "", "alice123", "bob456", testAmount)
+	testKey.CreatedAt = testCreatedAt
+	testKey.Ledger = &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: testAmount, ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: testAmount, FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
+	//ledgers stored before money had currency keep bare amounts
+	legacyAmount := entities.NewMoney(testAmount.Amount, "")
+	legacyKey := *testKey
+	legacyKey.Ledger = &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: legacyAmount, ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: legacyAmount, FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
+	testRow := []driver.Value{1, testKey.Key, testKey.Fingerprint,
+		[]byte(`{"id":"c5417ca1-7a4c-4a6a-a4b7-2f2ad3e1b1f5","payments":[` +
+			`{"account":"alice123","amount":{"amount":"4.124","currency":"USD"},"to_account":"bob456","direction":"outgoing"},` +
+			`{"account":"bob456","amount":{"amount":4.124,"currency":"USD"},"from_account":"alice123","direction":"incoming"}]}`),
+		testCreatedAt, testCreatedAt,
+	}
+	legacyRow := []driver.Value{1, testKey.Key, testKey.Fingerprint,
+		[]byte(`[{"account":"alice123","amount":"4.124","to_account":"bob456","direction":"outgoing"},` +
+			`{"account":"bob456","amount":"4.124","from_account":"alice123","direction":"incoming"}]`),
+		testCreatedAt, testCreatedAt,
+	}
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	type args struct {
+		ctx   context.Context
+		key   string
+		since time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.IdempotencyKey
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			args: args{
+				ctx:   context.Background(),
+				key:   "key",
+				since: testCreatedAt.Add(-time.Hour),
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM idempotency_keys WHERE .*").
+					WithArgs(a.key, a.since).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "key is absent or expired",
+			args: args{
+				ctx:   context.Background(),
+				key:   "key",
+				since: testCreatedAt.Add(-time.Hour),
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM idempotency_keys WHERE .*").
+					WithArgs(a.key, a.since).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "working fine",
+			args: args{
+				ctx:   context.Background(),
+				key:   "key",
+				since: testCreatedAt.Add(-time.Hour),
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM idempotency_keys WHERE .*").
+					WithArgs(a.key, a.since).
+					WillReturnRows(rows.AddRow(testRow...))
+			},
+			want: testKey,
+		},
+		{
+			name: "legacy ledger array",
+			args: args{
+				ctx:   context.Background(),
+				key:   "key",
+				since: testCreatedAt.Add(-time.Hour),
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM idempotency_keys WHERE .*").
+					WithArgs(a.key, a.since).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "fingerprint", "ledger", "created_at", "updated_at"}).
+						AddRow(legacyRow...))
+			},
+			want: &legacyKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			ik, err := NewIdempotencyKeys(db)
+			if err != nil {
+				t.Fatalf("NewIdempotencyKeys error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := ik.GetByKeyTx(tx, tt.args.ctx, tt.args.key, tt.args.since)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetByKeyTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("GetByKeyTx() got = %v, want nil", got)
+				}
+				return
+			}
+
+			if !got.Match(tt.want) || !got.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Fatalf("GetByKeyTx() got = %v, want %v", got, tt.want)
+			}
+
+			for i := range got.Ledger.Payments {
+				if !reflect.DeepEqual(got.Ledger.Payments[i].Account, tt.want.Ledger.Payments[i].Account) ||
+					!got.Ledger.Payments[i].Amount.Equal(tt.want.Ledger.Payments[i].Amount) ||
+					got.Ledger.Payments[i].Direction != tt.want.Ledger.Payments[i].Direction {
+					t.Fatalf("GetByKeyTx() got = %v, want %v", got.Ledger.Payments[i], tt.want.Ledger.Payments[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeys_AddTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	execContextError := xerrors.New("exec_context_error")
+
+	testAmount := entities.NewMoney(decimal.NewFromFloat(1.212), "USD")
+	testKey := entities.NewIdempotencyKey("key", "", "alice123", "bob456", testAmount)
+	testKey.Ledger = &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: testAmount, ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: testAmount, FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
+	type args struct {
+		ctx context.Context
+		key *entities.IdempotencyKey
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		wantErr error
+	}{
+		{
+			name: "ExecContext returns error",
+			args: args{
+				ctx: context.Background(),
+				key: testKey,
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO idempotency_keys (.*) VALUES (.*)").
+					WithArgs(a.key.Key, a.key.Fingerprint, sqlmock.AnyArg()).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name: "working fine",
+			args: args{
+				ctx: context.Background(),
+				key: testKey,
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO idempotency_keys (.*) VALUES (.*)").
+					WithArgs(a.key.Key, a.key.Fingerprint, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			ik, err := NewIdempotencyKeys(db)
+			if err != nil {
+				t.Fatalf("NewIdempotencyKeys error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			err = ik.AddTx(tx, tt.args.ctx, tt.args.key)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("AddTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,17 +3,24 @@ package gateways
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/NickRI/wallets-task/db/common"
 	"github.com/NickRI/wallets-task/db/models"
 	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
 	"github.com/shopspring/decimal"
 	"golang.org/x/xerrors"
 )
 
 type Ledgers struct {
-	createQuery *createLedgerQuery
-	listQuery   *listLedgersQuery
+	createQuery   *createLedgerQuery
+	listQuery     *listLedgersQuery
+	accountQuery  *accountPaymentsQuery
+	fetchQuery    *fetchLedgerQuery
+	refundedQuery *refundedAmountQuery
+	transferQuery *transferQuery
 }
 
 func NewLedgers(d *sql.DB) (repositories.Ledgers, error) {
@@ -25,55 +32,299 @@ func NewLedgers(d *sql.DB) (repositories.Ledgers, error) {
 		return nil, xerrors.Errorf("Error preparation listLedgersQuery: %w", err)
 	}
 
+	table.accountQuery, err = newAccountPaymentsQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation accountPaymentsQuery: %w", err)
+	}
+
 	table.createQuery, err = newCreatePaymentQuery(d)
 	if err != nil {
 		return nil, xerrors.Errorf("Error preparation createPaymentQuery: %w", err)
 	}
 
+	table.fetchQuery, err = newFetchLedgerQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation fetchLedgerQuery: %w", err)
+	}
+
+	table.refundedQuery, err = newRefundedAmountQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation refundedAmountQuery: %w", err)
+	}
+
+	table.transferQuery, err = newTransferQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation transferQuery: %w", err)
+	}
+
 	return table, nil
 }
 
-func (p *Ledgers) AddTx(tx *sql.Tx, ctx context.Context, credit *entities.Account, debit *entities.Account, amount decimal.Decimal) (*entities.Ledger, error) {
-	pt := models.NewLedgerFromAccount(credit, debit, amount)
+func (p *Ledgers) AddTx(tx *sql.Tx, ctx context.Context, credit *entities.Account, debit *entities.Account, amount entities.Money, paymentType entities.PaymentType, fx *entities.Conversion) (*entities.Ledger, error) {
+	if _, err := accountAmount(credit, amount); err != nil {
+		return nil, err
+	}
+
+	return p.addTx(tx, ctx, models.NewLedgerFromAccount(credit, debit, amount, paymentType, fx))
+}
 
-	_, err := tx.StmtContext(ctx, p.createQuery.Stmt).ExecContext(ctx, pt.Bind()...)
+//AddBatchTx records transfer ledger as a part of the batch
+func (p *Ledgers) AddBatchTx(tx *sql.Tx, ctx context.Context, batchId string, credit *entities.Account, debit *entities.Account, amount entities.Money, fx *entities.Conversion) (*entities.Ledger, error) {
+	guid, err := uuid.FromString(batchId)
 	if err != nil {
+		return nil, xerrors.Errorf("wrong batch id %s: %w", batchId, err)
+	}
+
+	if _, err := accountAmount(credit, amount); err != nil {
+		return nil, err
+	}
+
+	pt := models.NewLedgerFromAccount(credit, debit, amount, entities.Transfer, fx)
+	pt.SetBatch(guid)
+
+	return p.addTx(tx, ctx, pt)
+}
+
+func (p *Ledgers) addTx(tx *sql.Tx, ctx context.Context, pt *models.Ledger) (*entities.Ledger, error) {
+	var createdAt time.Time
+	if err := tx.StmtContext(ctx, p.createQuery.Stmt).QueryRowContext(ctx, pt.Bind()...).Scan(&createdAt, &common.NullBool{V: &pt.Pays[0].Overdraft}); err != nil {
 		return nil, models.DBErrorWrapper{err}
 	}
+	pt.SetCreatedAt(createdAt)
 
 	return pt.ToDomain(), nil
 }
 
-func (p *Ledgers) List(ctx context.Context) (entities.Ledgers, error) {
+//AddRefundTx records ledger returning amount of the ledger refundOf, legs are linked to it
+func (p *Ledgers) AddRefundTx(tx *sql.Tx, ctx context.Context, refundOf string, credit *entities.Account, debit *entities.Account, amount entities.Money, fx *entities.Conversion) (*entities.Ledger, error) {
+	guid, err := uuid.FromString(refundOf)
+	if err != nil {
+		return nil, xerrors.Errorf("wrong ledger guid %s: %w", refundOf, err)
+	}
+
+	if _, err := accountAmount(credit, amount); err != nil {
+		return nil, err
+	}
+
+	pt := models.NewLedgerFromAccount(credit, debit, amount, entities.Refund, fx)
+	pt.SetRefundOf(guid)
+
+	return p.addTx(tx, ctx, pt)
+}
+
+//TransferTx makes transfer of amount between active accounts of the same currency with a single statement unless
+//it exceeds spendable balance of credit or credit is limited, amount without currency is in credit's currency.
+//Accounts of the attempt are locked and read as they were before the statement
+func (p *Ledgers) TransferTx(tx *sql.Tx, ctx context.Context, creditId, debitId entities.AccountId, amount entities.Money) (*entities.TransferAttempt, error) {
+	var (
+		guid     = uuid.NewV4()
+		currency *string
+	)
+
+	if amount.Currency != "" {
+		c := string(amount.Currency)
+		currency = &c
+	}
+
+	rows, err := tx.StmtContext(ctx, p.transferQuery.Stmt).QueryContext(ctx, creditId, debitId, amount.Amount,
+		&common.NullString{V: currency}, guid.Bytes(), entities.Transfer)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+	defer rows.Close()
+
+	var (
+		attempt   = &entities.TransferAttempt{}
+		createdAt time.Time
+		overdraft bool
+	)
+
+	for rows.Next() {
+		var (
+			account      = &models.Account{}
+			legCreated   time.Time
+			legOverdraft bool
+		)
+
+		if err := rows.Scan(append(account.Bind(), &common.NullTime{V: &legCreated}, &common.NullBool{V: &legOverdraft})...); err != nil {
+			return nil, models.DBErrorWrapper{err}
+		}
+
+		if account.UserName == string(creditId) {
+			attempt.Credit, overdraft = account.ToDomain(), legOverdraft
+		}
+		if account.UserName == string(debitId) {
+			attempt.Debit = account.ToDomain()
+		}
+		if !legCreated.IsZero() {
+			createdAt = legCreated
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	if createdAt.IsZero() {
+		return attempt, nil
+	}
+
+	money, err := amount.In(attempt.Credit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := models.NewLedgerFromAccount(attempt.Credit, attempt.Debit, money, entities.Transfer, nil)
+	pt.SetGuid(guid)
+	pt.SetCreatedAt(createdAt)
+	pt.Pays[0].Overdraft = overdraft
+	attempt.Ledger = pt.ToDomain()
+
+	return attempt, nil
+}
+
+//GetByGuid returns ledger by guid, malformed guid is reported as sql.ErrNoRows
+func (p *Ledgers) GetByGuid(ctx context.Context, guid string) (*entities.Ledger, error) {
+	id, err := uuid.FromString(guid)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return scanLedger(p.fetchQuery.QueryRowContext(ctx, id.Bytes()))
+}
+
+//GetByGuidTx returns ledger by guid within tx, malformed guid is reported as sql.ErrNoRows
+func (p *Ledgers) GetByGuidTx(tx *sql.Tx, ctx context.Context, guid string) (*entities.Ledger, error) {
+	id, err := uuid.FromString(guid)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return scanLedger(tx.StmtContext(ctx, p.fetchQuery.Stmt).QueryRowContext(ctx, id.Bytes()))
+}
+
+func scanLedger(row *sql.Row) (*entities.Ledger, error) {
+	ledger := &models.Ledger{Pays: [2]*models.Payment{&models.Payment{}, &models.Payment{}}}
+	if err := row.Scan(ledger.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return ledger.ToDomain(), nil
+}
+
+//RefundedTx returns amount already returned to the sender of outgoing payment sent
+func (p *Ledgers) RefundedTx(tx *sql.Tx, ctx context.Context, sent *entities.Payment) (entities.Money, error) {
+	var refunded decimal.Decimal
+
+	id, err := uuid.FromString(sent.Ledger)
+	if err != nil {
+		return sent.Amount, xerrors.Errorf("wrong ledger guid %s: %w", sent.Ledger, err)
+	}
+
+	if err := tx.StmtContext(ctx, p.refundedQuery.Stmt).QueryRowContext(ctx, id.Bytes()).Scan(&refunded); err != nil {
+		return sent.Amount, models.DBErrorWrapper{err}
+	}
+
+	return entities.NewMoney(refunded, sent.Amount.Currency), nil
+}
+
+//List returns one page of ledgers matched by filter, newest first
+func (p *Ledgers) List(ctx context.Context, filter *entities.LedgerFilter) (*entities.LedgersPage, error) {
+	lf := models.NewLedgerFilter(filter)
 	acList := models.LedgerList{}
-	rows, err := p.listQuery.QueryContext(ctx)
+
+	rows, err := p.listQuery.QueryContext(ctx, lf.Bind()...)
 	if err != nil {
-		return acList.ToDomain(), models.DBErrorWrapper{err}
+		return nil, models.DBErrorWrapper{err}
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		ledger := &models.Ledger{Pays: [2]*models.Payment{&models.Payment{}, &models.Payment{}}}
 		if err := rows.Scan(ledger.BindScan()...); err != nil {
-			return acList.ToDomain(), models.DBErrorWrapper{err}
+			return nil, models.DBErrorWrapper{err}
 		}
 		acList.Add(ledger)
 	}
 
-	return acList.ToDomain(), nil
+	if err := rows.Err(); err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	page := &entities.LedgersPage{}
+	if size := filter.PageSize(); len(acList) > size {
+		acList = acList[:size]
+		page.NextCursor = acList[size-1].Cursor().String()
+	}
+	page.Ledgers = acList.ToDomain()
+
+	return page, nil
 }
 
-type listLedgersQuery struct {
-	*sql.Stmt
+//ListByAccount returns one page of account's payments with balance after each of them, newest first
+func (p *Ledgers) ListByAccount(ctx context.Context, account *entities.Account, page *entities.Pagination) (*entities.PaymentsPage, error) {
+	psList := models.AccountPaymentList{}
+
+	rows, err := p.accountQuery.QueryContext(ctx, append([]interface{}{account.GetId()}, models.PaginationArgs(page)...)...)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payment := &models.AccountPayment{}
+		if err := rows.Scan(payment.BindScan()...); err != nil {
+			return nil, models.DBErrorWrapper{err}
+		}
+		psList.Add(payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	result := &entities.PaymentsPage{Payments: entities.Payments{}}
+	if size := page.PageSize(); len(psList) > size {
+		psList = psList[:size]
+		result.NextCursor = psList[size-1].Cursor().String()
+	}
+	result.Payments.Append(psList.ToDomain())
+
+	return result, nil
 }
 
-func newListLedgersQuery(d *sql.DB) (*listLedgersQuery, error) {
-	stmt, err := d.Prepare(`SELECT p1.*, p2.*, a1.user_name, a2.user_name
+//ledgerColumns joins incoming leg p1 with outgoing one p2 of the same guid
+const ledgerColumns = `p1.id, p1.guid, p1.account_id, p1.amount, p1.type,
+			p1.fx_rate, p1.source_amount, p1.target_amount, p1.refund_of, p1.batch_id, p1.updated_at, p1.created_at, p1.overdraft,
+			p2.id, p2.guid, p2.account_id, p2.amount, p2.type,
+			p2.fx_rate, p2.source_amount, p2.target_amount, p2.refund_of, p2.batch_id, p2.updated_at, p2.created_at, p2.overdraft,
+			a1.user_name, a2.user_name, a1.currency, a2.currency
 		FROM payments p1
 		JOIN payments p2 ON p1.guid = p2.guid AND p1.account_id != p2.account_id
 		JOIN accounts a1 ON p1.account_id = a1.id
-		JOIN accounts a2 ON p2.account_id = a2.id
-		WHERE p1.amount > 0`,
+		JOIN accounts a2 ON p2.account_id = a2.id`
+
+type listLedgersQuery struct {
+	*sql.Stmt
+}
+
+func newListLedgersQuery(d *sql.DB) (*listLedgersQuery, error) {
+	stmt, err := d.Prepare(`SELECT ` + ledgerColumns + `
+		WHERE p1.amount > 0
+			AND ($1::varchar IS NULL
+				OR ($3::varchar IS DISTINCT FROM 'outgoing' AND a1.user_name = $1::varchar)
+				OR ($3::varchar IS DISTINCT FROM 'incoming' AND a2.user_name = $1::varchar))
+			AND ($2::varchar IS NULL
+				OR (a1.user_name = $1::varchar AND a2.user_name = $2::varchar)
+				OR (a2.user_name = $1::varchar AND a1.user_name = $2::varchar))
+			AND ($4::decimal IS NULL OR p1.amount >= $4::decimal)
+			AND ($5::decimal IS NULL OR p1.amount <= $5::decimal)
+			AND ($6::timestamptz IS NULL OR p1.created_at >= $6::timestamptz)
+			AND ($7::timestamptz IS NULL OR p1.created_at < $7::timestamptz)
+			AND ($8::timestamptz IS NULL OR (p1.created_at, p1.id) < ($8::timestamptz, $9::integer))
+			AND ($10::varchar IS NULL OR p1.type = $10::varchar)
+		ORDER BY p1.created_at DESC, p1.id DESC
+		LIMIT $11`,
 	)
 	if err != nil {
 		return nil, err
@@ -82,13 +333,79 @@ func newListLedgersQuery(d *sql.DB) (*listLedgersQuery, error) {
 	return &listLedgersQuery{stmt}, nil
 }
 
+type accountPaymentsQuery struct {
+	*sql.Stmt
+}
+
+//newAccountPaymentsQuery selects legs of single account by payments(account_id, created_at, id) index
+//along with balances of the account stored right after each of them
+func newAccountPaymentsQuery(d *sql.DB) (*accountPaymentsQuery, error) {
+	stmt, err := d.Prepare(`SELECT p.id, p.guid, p.account_id, p.amount, p.type,
+			p.fx_rate, p.source_amount, p.target_amount, p.refund_of, p.batch_id, p.updated_at, p.created_at, p.overdraft,
+			a.user_name, c.user_name AS counterparty, a.currency, p.balance_after
+		FROM payments p
+		JOIN accounts a ON p.account_id = a.id
+		JOIN payments cp ON p.guid = cp.guid AND p.account_id != cp.account_id
+		JOIN accounts c ON cp.account_id = c.id
+		WHERE p.account_id = $1
+			AND ($2::timestamptz IS NULL OR (p.created_at, p.id) < ($2::timestamptz, $3::integer))
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $4`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accountPaymentsQuery{stmt}, nil
+}
+
+type fetchLedgerQuery struct {
+	*sql.Stmt
+}
+
+func newFetchLedgerQuery(d *sql.DB) (*fetchLedgerQuery, error) {
+	stmt, err := d.Prepare(`SELECT ` + ledgerColumns + `
+		WHERE p1.guid = $1 AND p1.amount > 0`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetchLedgerQuery{stmt}, nil
+}
+
+type refundedAmountQuery struct {
+	*sql.Stmt
+}
+
+//newRefundedAmountQuery sums the legs of refunds which return money to the original sender
+func newRefundedAmountQuery(d *sql.DB) (*refundedAmountQuery, error) {
+	stmt, err := d.Prepare(`SELECT COALESCE(SUM(p.amount), 0) FROM payments p
+		WHERE p.refund_of = $1 AND p.amount > 0`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &refundedAmountQuery{stmt}, nil
+}
+
 type createLedgerQuery struct {
 	*sql.Stmt
 }
 
+//newCreatePaymentQuery marks outgoing leg with overdraft when balance of the sender, which is already
+//decreased by the payment, is below zero, settlement accounts have no overdraft limit and are never marked.
+//Balances of both accounts are already updated, so they are stored as balances after the legs
 func newCreatePaymentQuery(d *sql.DB) (*createLedgerQuery, error) {
-	stmt, err := d.Prepare(`INSERT INTO payments (id, guid, account_id, amount, created_at, updated_at)
-		VALUES (DEFAULT, $1, $2, $3, DEFAULT, DEFAULT), (DEFAULT, $4, $5, $6, DEFAULT, DEFAULT);
+	stmt, err := d.Prepare(`INSERT INTO payments (id, guid, account_id, amount, type,
+			fx_rate, source_amount, target_amount, refund_of, batch_id, overdraft, balance_after, created_at, updated_at)
+		VALUES (DEFAULT, $1, $2, $3, $7, $8, $9, $10, $11, $12,
+				(SELECT a.balance < 0 AND a.overdraft_limit > 0 FROM accounts a WHERE a.id = $2),
+				(SELECT a.balance FROM accounts a WHERE a.id = $2), DEFAULT, DEFAULT),
+			(DEFAULT, $4, $5, $6, $7, $8, $9, $10, $11, $12, false,
+				(SELECT a.balance FROM accounts a WHERE a.id = $5), DEFAULT, DEFAULT)
+		RETURNING created_at, overdraft;
 	`)
 	if err != nil {
 		return nil, err
@@ -96,3 +413,54 @@ func newCreatePaymentQuery(d *sql.DB) (*createLedgerQuery, error) {
 
 	return &createLedgerQuery{stmt}, err
 }
+
+type transferQuery struct {
+	*sql.Stmt
+}
+
+//newTransferQuery locks both accounts in order of their ids and returns them as they were before the transfer along
+//with creation time and overdraft marker of their legs, the legs are null when transfer isn't made. Spendable balance
+//is the available one along with unexpired overdraft. Limits aren't checked by the statement, so it doesn't make transfers
+//from accounts which have own limits or limits of their currency and leaves them to the checks of the service
+func newTransferQuery(d *sql.DB) (*transferQuery, error) {
+	stmt, err := d.Prepare(`WITH src AS (
+			SELECT a.id, a.user_name, a.balance,
+				a.balance - COALESCE((SELECT SUM(h.amount) FROM holds h WHERE h.account_id = a.id AND h.status = 'active' AND h.expires_at > NOW()), 0) AS available,
+				a.currency, a.status, a.created_at, a.updated_at, a.owner, a.overdraft_limit, a.overdraft_expires_at
+			FROM accounts a
+			WHERE a.user_name IN ($1::varchar, $2::varchar)
+			ORDER BY a.id
+			FOR UPDATE
+		), transfer AS (
+			SELECT c.id AS credit_id, d.id AS debit_id
+			FROM src c, src d
+			WHERE c.user_name = $1::varchar AND d.user_name = $2::varchar AND c.id != d.id
+				AND c.status = 'active' AND d.status = 'active'
+				AND c.currency = d.currency AND ($4::varchar IS NULL OR c.currency = $4::varchar)
+				AND c.available + CASE WHEN c.overdraft_expires_at IS NULL OR c.overdraft_expires_at > NOW()
+					THEN c.overdraft_limit ELSE 0 END >= $3::decimal
+				AND NOT EXISTS (SELECT 1 FROM account_limits l
+					WHERE l.account_id = c.id OR l.account_id IS NULL AND l.currency = c.currency)
+		), balances AS (
+			UPDATE accounts a SET balance = a.balance + CASE WHEN a.id = t.credit_id THEN -$3::decimal ELSE $3::decimal END
+			FROM transfer t
+			WHERE a.id IN (t.credit_id, t.debit_id)
+			RETURNING a.id, a.balance, a.overdraft_limit, a.id = t.credit_id AS outgoing
+		), legs AS (
+			INSERT INTO payments (guid, account_id, amount, type, overdraft, balance_after)
+			SELECT $5, b.id, CASE WHEN b.outgoing THEN -$3::decimal ELSE $3::decimal END, $6,
+				b.outgoing AND b.balance < 0 AND b.overdraft_limit > 0, b.balance
+			FROM balances b
+			RETURNING account_id, created_at, overdraft
+		)
+		SELECT s.id, s.user_name, s.balance, s.available, s.currency, s.status, s.created_at, s.updated_at,
+			s.owner, s.overdraft_limit, s.overdraft_expires_at, l.created_at, l.overdraft
+		FROM src s
+		LEFT JOIN legs l ON l.account_id = s.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transferQuery{stmt}, nil
+}
@@ -4,6 +4,7 @@ package gateways
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"reflect"
 	"testing"
@@ -25,7 +26,11 @@ func TestNewLedgers(t *testing.T) {
 	}
 
 	newListLedgersError := xerrors.New("new_list_ledgers_error")
+	newAccountPaymentsError := xerrors.New("new_account_payments_error")
 	newCreatePaymentError := xerrors.New("new_create_payment_error")
+	newFetchLedgerError := xerrors.New("new_fetch_ledger_error")
+	newRefundedAmountError := xerrors.New("new_refunded_amount_error")
+	newTransferError := xerrors.New("new_transfer_error")
 
 	tests := []struct {
 		name    string
@@ -41,20 +46,70 @@ func TestNewLedgers(t *testing.T) {
 			},
 			wantErr: newListLedgersError,
 		},
+		{
+			name: "newAccountPaymentsQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*").
+					WillReturnError(newAccountPaymentsError)
+			},
+			wantErr: newAccountPaymentsError,
+		},
 		{
 			name: "newCreatePaymentQuery returns error",
 			before: func() {
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)").
 					WillReturnError(newCreatePaymentError)
 			},
 			wantErr: newCreatePaymentError,
 		},
+		{
+			name: "newFetchLedgerQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*").
+					WillReturnError(newFetchLedgerError)
+			},
+			wantErr: newFetchLedgerError,
+		},
+		{
+			name: "newRefundedAmountQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*").
+					WillReturnError(newRefundedAmountError)
+			},
+			wantErr: newRefundedAmountError,
+		},
+		{
+			name: "newTransferQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*").
+					WillReturnError(newTransferError)
+			},
+			wantErr: newTransferError,
+		},
 		{
 			name: "works well",
 			before: func() {
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
 			},
 			want: &Ledgers{},
 		},
@@ -70,7 +125,11 @@ func TestNewLedgers(t *testing.T) {
 
 			if tt.want != nil {
 				got.(*Ledgers).listQuery = tt.want.(*Ledgers).listQuery
+				got.(*Ledgers).accountQuery = tt.want.(*Ledgers).accountQuery
 				got.(*Ledgers).createQuery = tt.want.(*Ledgers).createQuery
+				got.(*Ledgers).fetchQuery = tt.want.(*Ledgers).fetchQuery
+				got.(*Ledgers).refundedQuery = tt.want.(*Ledgers).refundedQuery
+				got.(*Ledgers).transferQuery = tt.want.(*Ledgers).transferQuery
 			}
 
 			if !reflect.DeepEqual(got, tt.want) {
@@ -88,12 +147,15 @@ func TestLedgers_AddTx(t *testing.T) {
 	defer db.Close()
 
 	execContextError := xerrors.New("exec_context_error")
+	testCreatedAt := time.Now()
 
 	type args struct {
-		ctx    context.Context
-		credit *entities.Account
-		debit  *entities.Account
-		amount decimal.Decimal
+		ctx         context.Context
+		credit      *entities.Account
+		debit       *entities.Account
+		amount      entities.Money
+		paymentType entities.PaymentType
+		fx          *entities.Conversion
 	}
 	tests := []struct {
 		name    string
@@ -116,25 +178,30 @@ func TestLedgers_AddTx(t *testing.T) {
 					Balance:   decimal.NewFromFloat(1.212),
 					Currency:  "USD",
 				},
-				amount: decimal.NewFromFloat(1.212),
+				amount:      entities.NewMoney(decimal.NewFromFloat(1.212), "USD"),
+				paymentType: entities.Transfer,
 			},
 			before: func(a *args, l *entities.Ledger) {
 				a.credit.SetId(1)
 				a.debit.SetId(1)
 
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
 
-				pt := models.NewLedgerFromAccount(a.credit, a.debit, a.amount)
+				pt := models.NewLedgerFromAccount(a.credit, a.debit, a.amount, a.paymentType, a.fx)
 
 				mock.ExpectBegin()
-				mock.ExpectExec("INSERT INTO payments (.*) VALUES (.*), (.*)").
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
 					WithArgs(
 						sqlmock.AnyArg(), pt.Pays[0].AccountId, pt.Pays[0].Amount,
 						sqlmock.AnyArg(), pt.Pays[1].AccountId, pt.Pays[1].Amount,
+						string(a.paymentType), nil, nil, nil, nil, nil,
 					).
-					WillReturnError(execContextError).
-					WillReturnResult(sqlmock.NewErrorResult(execContextError))
+					WillReturnError(execContextError)
 			},
 			wantErr: execContextError,
 		},
@@ -152,25 +219,124 @@ func TestLedgers_AddTx(t *testing.T) {
 					Balance:   decimal.NewFromFloat(1.212),
 					Currency:  "USD",
 				},
-				amount: decimal.NewFromFloat(1.212),
+				amount:      entities.NewMoney(decimal.NewFromFloat(1.212), "USD"),
+				paymentType: entities.Transfer,
 			},
 			before: func(a *args, l *entities.Ledger) {
 				a.credit.SetId(1)
 				a.debit.SetId(1)
 
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
 
-				pt := models.NewLedgerFromAccount(a.credit, a.debit, a.amount)
+				pt := models.NewLedgerFromAccount(a.credit, a.debit, a.amount, a.paymentType, a.fx)
+				pt.SetCreatedAt(testCreatedAt)
 				*l = *pt.ToDomain()
 
 				mock.ExpectBegin()
-				mock.ExpectExec("INSERT INTO payments (.*) VALUES (.*), (.*)").
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
 					WithArgs(
 						sqlmock.AnyArg(), pt.Pays[0].AccountId, pt.Pays[0].Amount,
 						sqlmock.AnyArg(), pt.Pays[1].AccountId, pt.Pays[1].Amount,
+						string(a.paymentType), nil, nil, nil, nil, nil,
+					).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "overdraft"}).AddRow(testCreatedAt, false))
+			},
+			want: &entities.Ledger{},
+		},
+		{
+			name: "works well with overdraft",
+			args: args{
+				ctx: context.Background(),
+				credit: &entities.Account{
+					AccountId: "alice123",
+					Balance:   decimal.NewFromFloat(1.212),
+					Currency:  "USD",
+				},
+				debit: &entities.Account{
+					AccountId: "bob456",
+					Balance:   decimal.NewFromFloat(1.212),
+					Currency:  "USD",
+				},
+				amount:      entities.NewMoney(decimal.NewFromFloat(4.212), "USD"),
+				paymentType: entities.Transfer,
+			},
+			before: func(a *args, l *entities.Ledger) {
+				a.credit.SetId(1)
+				a.debit.SetId(2)
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				pt := models.NewLedgerFromAccount(a.credit, a.debit, a.amount, a.paymentType, a.fx)
+				pt.SetCreatedAt(testCreatedAt)
+				pt.Pays[0].Overdraft = true
+				*l = *pt.ToDomain()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at, overdraft").
+					WithArgs(
+						sqlmock.AnyArg(), pt.Pays[0].AccountId, pt.Pays[0].Amount,
+						sqlmock.AnyArg(), pt.Pays[1].AccountId, pt.Pays[1].Amount,
+						string(a.paymentType), nil, nil, nil, nil, nil,
+					).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "overdraft"}).AddRow(testCreatedAt, true))
+			},
+			want: &entities.Ledger{},
+		},
+		{
+			name: "works well with conversion",
+			args: args{
+				ctx: context.Background(),
+				credit: &entities.Account{
+					AccountId: "alice123",
+					Balance:   decimal.NewFromFloat(4.212),
+					Currency:  "USD",
+				},
+				debit: &entities.Account{
+					AccountId: "bob456",
+					Balance:   decimal.NewFromFloat(1.212),
+					Currency:  "EUR",
+				},
+				amount:      entities.NewMoney(decimal.RequireFromString("2"), "USD"),
+				paymentType: entities.Transfer,
+				fx: &entities.Conversion{
+					Rate:         decimal.RequireFromString("0.9"),
+					SourceAmount: decimal.RequireFromString("2"),
+					TargetAmount: decimal.RequireFromString("1.8"),
+				},
+			},
+			before: func(a *args, l *entities.Ledger) {
+				a.credit.SetId(1)
+				a.debit.SetId(2)
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				pt := models.NewLedgerFromAccount(a.credit, a.debit, a.amount, a.paymentType, a.fx)
+				pt.SetCreatedAt(testCreatedAt)
+				*l = *pt.ToDomain()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
+					WithArgs(
+						sqlmock.AnyArg(), pt.Pays[0].AccountId, a.amount.Amount.Neg(),
+						sqlmock.AnyArg(), pt.Pays[1].AccountId, a.fx.TargetAmount,
+						string(a.paymentType), a.fx.Rate, a.fx.SourceAmount, a.fx.TargetAmount, nil, nil,
 					).
-					WillReturnResult(sqlmock.NewResult(1, 2))
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "overdraft"}).AddRow(testCreatedAt, false))
 			},
 			want: &entities.Ledger{},
 		},
@@ -189,11 +355,18 @@ func TestLedgers_AddTx(t *testing.T) {
 				t.Fatalf("db.Begin error: %+v", err)
 			}
 
-			got, err := p.AddTx(tx, tt.args.ctx, tt.args.credit, tt.args.debit, tt.args.amount)
+			got, err := p.AddTx(tx, tt.args.ctx, tt.args.credit, tt.args.debit, tt.args.amount, tt.args.paymentType, tt.args.fx)
 			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
 				t.Fatalf("AddTx() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
+			if got != nil {
+				if got.Id == "" || got.Payments[0].Ledger != got.Id || got.Payments[1].Ledger != got.Id {
+					t.Fatalf("AddTx() got legs of ledgers %s and %s in %s", got.Payments[0].Ledger, got.Payments[1].Ledger, got.Id)
+				}
+				tt.want.Id, tt.want.Payments[0].Ledger, tt.want.Payments[1].Ledger = got.Id, got.Id, got.Id
+			}
+
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Fatalf("AddTx() got = %v, want %v", got, tt.want)
 			}
@@ -210,49 +383,67 @@ func TestLedgers_List(t *testing.T) {
 
 	queryContextError := xerrors.New("query_context_error")
 
-	var rows = sqlmock.NewRows([]string{"p1.id", "p1.guid", "p1.account_id", "p1.amount", "p1.created_at", "p1.updated_at",
-		"p2.id", "p2.guid", "p2.account_id", "p2.amount", "p2.created_at", "p2.updated_at",
-		"a1.user_name", "a2.user_name"})
+	columns := []string{"p1.id", "p1.guid", "p1.account_id", "p1.amount", "p1.type", "p1.fx_rate", "p1.source_amount", "p1.target_amount", "p1.refund_of", "p1.batch_id", "p1.updated_at", "p1.created_at", "p1.overdraft",
+		"p2.id", "p2.guid", "p2.account_id", "p2.amount", "p2.type", "p2.fx_rate", "p2.source_amount", "p2.target_amount", "p2.refund_of", "p2.batch_id", "p2.updated_at", "p2.created_at", "p2.overdraft",
+		"a1.user_name", "a2.user_name", "a1.currency", "a2.currency"}
 
 	testAmount := decimal.NewFromFloat(4.124)
+	testCreatedAt := time.Now()
+	testDirection := entities.Incoming
+	testType := entities.Transfer
 
 	outgoingPayment := &entities.Payment{
+		Ledger:    "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
 		Account:   "alice123",
-		Amount:    testAmount,
+		Amount:    entities.NewMoney(testAmount, "USD"),
 		ToAccount: "bob456",
 		Direction: entities.Outgoing,
+		Type:      entities.Transfer,
 	}
 
 	incomingPayment := &entities.Payment{
+		Ledger:      "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
 		Account:     "bob456",
-		Amount:      testAmount,
+		Amount:      entities.NewMoney(testAmount, "USD"),
 		FromAccount: "alice123",
 		Direction:   entities.Incoming,
+		Type:        entities.Transfer,
 	}
 
 	guidBytes := uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665").Bytes()
+	guidBytes2 := uuid.FromStringOrNil("a1b2c3d4-c06b-4a45-9cd9-85936d4b9665").Bytes()
+
+	testRow := []driver.Value{3, guidBytes, 1, testAmount, "transfer", nil, nil, nil, nil, nil, testCreatedAt, testCreatedAt, false,
+		4, guidBytes, 2, testAmount, "transfer", nil, nil, nil, nil, nil, testCreatedAt, testCreatedAt, false,
+		"bob456", "alice123", "USD", "USD",
+	}
 
-	testRow := []driver.Value{1, guidBytes, 1, testAmount, time.Now(), time.Now(),
-		2, guidBytes, 1, testAmount, time.Now(), time.Now(),
-		"bob456", "alice123",
+	testRow2 := []driver.Value{1, guidBytes2, 1, testAmount, "transfer", nil, nil, nil, nil, nil, testCreatedAt.Add(-time.Second), testCreatedAt.Add(-time.Second), false,
+		2, guidBytes2, 2, testAmount, "transfer", nil, nil, nil, nil, nil, testCreatedAt.Add(-time.Second), testCreatedAt.Add(-time.Second), false,
+		"bob456", "alice123", "USD", "USD",
 	}
 
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		filter *entities.LedgerFilter
 	}
 	tests := []struct {
 		name    string
 		args    args
-		before  func()
-		want    entities.Ledgers
+		before  func(*args)
+		want    *entities.LedgersPage
 		wantErr error
 	}{
 		{
 			name: "QueryContext returns error",
-			args: args{ctx: context.Background()},
-			before: func() {
+			args: args{ctx: context.Background(), filter: &entities.LedgerFilter{}},
+			before: func(a *args) {
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
 
 				mock.ExpectQuery("SELECT .* FROM payments .* WHERE .*").
 					WillReturnError(queryContextError).
@@ -262,41 +453,872 @@ func TestLedgers_List(t *testing.T) {
 		},
 		{
 			name: "everything fine",
-			args: args{ctx: context.Background()},
-			before: func() {
+			args: args{ctx: context.Background(), filter: &entities.LedgerFilter{}},
+			before: func(a *args) {
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
 
 				mock.ExpectQuery("SELECT .* FROM payments .* WHERE .*").
-					WillReturnRows(rows.AddRow(testRow...)).
+					WithArgs(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, entities.DefaultPageSize+1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(testRow...)).
 					RowsWillBeClosed()
 			},
-			want: entities.Ledgers{&entities.Ledger{outgoingPayment, incomingPayment}},
+			want: &entities.LedgersPage{Ledgers: entities.Ledgers{&entities.Ledger{Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", CreatedAt: testCreatedAt, Payments: [2]*entities.Payment{outgoingPayment, incomingPayment}}}},
+		},
+		{
+			name: "filtered page has next cursor",
+			args: args{
+				ctx: context.Background(),
+				filter: &entities.LedgerFilter{
+					Account:   "bob456",
+					Direction: &testDirection,
+					Type:      &testType,
+					MinAmount: decimal.NullDecimal{Decimal: testAmount, Valid: true},
+					From:      testCreatedAt.Add(-time.Hour),
+					Pagination: entities.Pagination{
+						Cursor: &entities.LedgerCursor{CreatedAt: testCreatedAt.Add(time.Second), Id: 5},
+						Limit:  1,
+					},
+				},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectQuery("SELECT .* FROM payments .* WHERE .*").
+					WithArgs("bob456", nil, "incoming", testAmount.String(), nil, a.filter.From, nil,
+						a.filter.Cursor.CreatedAt, a.filter.Cursor.Id, "transfer", 2).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(testRow...).AddRow(testRow2...)).
+					RowsWillBeClosed()
+			},
+			want: &entities.LedgersPage{
+				Ledgers:    entities.Ledgers{&entities.Ledger{Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", CreatedAt: testCreatedAt, Payments: [2]*entities.Payment{outgoingPayment, incomingPayment}}},
+				NextCursor: (&entities.LedgerCursor{CreatedAt: testCreatedAt, Id: 3}).String(),
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.before()
+			tt.before(&tt.args)
 
 			p, err := NewLedgers(db)
 			if err != nil {
 				t.Fatalf("NewAccounts error: %+v", err)
 			}
 
-			got, err := p.List(tt.args.ctx)
+			got, err := p.List(tt.args.ctx, tt.args.filter)
 			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
 				t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if len(got) != len(tt.want) {
-				t.Fatalf("List() got = %d, want %d", len(got), len(tt.want))
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("List() got = %v, want nil", got)
+				}
+				return
+			}
+
+			if got.NextCursor != tt.want.NextCursor {
+				t.Fatalf("List() got cursor = %s, want %s", got.NextCursor, tt.want.NextCursor)
+			}
+
+			if len(got.Ledgers) != len(tt.want.Ledgers) {
+				t.Fatalf("List() got = %d, want %d", len(got.Ledgers), len(tt.want.Ledgers))
+			}
+
+			for i := range got.Ledgers {
+				if !reflect.DeepEqual(got.Ledgers[i], tt.want.Ledgers[i]) {
+					t.Fatalf("List() got = %v, want %v", got.Ledgers[i], tt.want.Ledgers[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLedgers_ListByAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	queryContextError := xerrors.New("query_context_error")
+
+	columns := []string{"id", "guid", "account_id", "amount", "type", "fx_rate", "source_amount", "target_amount", "refund_of", "batch_id", "updated_at", "created_at", "overdraft",
+		"user_name", "counterparty", "currency", "balance_after"}
+
+	testAccount := &entities.Account{AccountId: "bob456"}
+	testAccount.SetId(2)
+
+	testAmount := decimal.NewFromFloat(4.124)
+	testCreatedAt := time.Now()
+	balanceAfterIncoming := entities.NewMoney(decimal.RequireFromString("14.124"), "USD")
+	balanceAfterOutgoing := entities.NewMoney(decimal.RequireFromString("10"), "USD")
+
+	incomingPayment := &entities.Payment{
+		Ledger:       "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+		Account:      "bob456",
+		Amount:       entities.NewMoney(testAmount, "USD"),
+		FromAccount:  "alice123",
+		Direction:    entities.Incoming,
+		Type:         entities.Transfer,
+		BalanceAfter: &balanceAfterIncoming,
+	}
+
+	outgoingPayment := &entities.Payment{
+		Ledger:       "a1b2c3d4-c06b-4a45-9cd9-85936d4b9665",
+		Account:      "bob456",
+		Amount:       entities.NewMoney(decimal.RequireFromString("-4.124"), "USD"),
+		ToAccount:    "settlement:USD",
+		Direction:    entities.Outgoing,
+		Type:         entities.Withdrawal,
+		BalanceAfter: &balanceAfterOutgoing,
+	}
+
+	guidBytes := uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665").Bytes()
+	guidBytes2 := uuid.FromStringOrNil("a1b2c3d4-c06b-4a45-9cd9-85936d4b9665").Bytes()
+
+	testRow := []driver.Value{4, guidBytes, 2, testAmount, "transfer", nil, nil, nil, nil, nil, testCreatedAt, testCreatedAt, false,
+		"bob456", "alice123", "USD", balanceAfterIncoming.Amount}
+
+	testRow2 := []driver.Value{2, guidBytes2, 2, testAmount.Neg(), "withdrawal", nil, nil, nil, nil, nil, testCreatedAt.Add(-time.Second), testCreatedAt.Add(-time.Second), false,
+		"bob456", "settlement:USD", "USD", balanceAfterOutgoing.Amount}
+
+	type args struct {
+		ctx     context.Context
+		account *entities.Account
+		page    *entities.Pagination
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.PaymentsPage
+		wantErr error
+	}{
+		{
+			name: "QueryContext returns error",
+			args: args{ctx: context.Background(), account: testAccount, page: &entities.Pagination{}},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectQuery("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*").
+					WillReturnError(queryContextError).
+					RowsWillBeClosed()
+			},
+			wantErr: queryContextError,
+		},
+		{
+			name: "account without payments",
+			args: args{ctx: context.Background(), account: testAccount, page: &entities.Pagination{}},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectQuery("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*").
+					WithArgs(2, nil, nil, entities.DefaultPageSize+1).
+					WillReturnRows(sqlmock.NewRows(columns)).
+					RowsWillBeClosed()
+			},
+			want: &entities.PaymentsPage{Payments: entities.Payments{}},
+		},
+		{
+			name: "everything fine",
+			args: args{ctx: context.Background(), account: testAccount, page: &entities.Pagination{}},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectQuery("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*").
+					WithArgs(2, nil, nil, entities.DefaultPageSize+1).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(testRow...).AddRow(testRow2...)).
+					RowsWillBeClosed()
+			},
+			want: &entities.PaymentsPage{Payments: entities.Payments{incomingPayment, outgoingPayment}},
+		},
+		{
+			name: "page has next cursor",
+			args: args{
+				ctx:     context.Background(),
+				account: testAccount,
+				page: &entities.Pagination{
+					Cursor: &entities.LedgerCursor{CreatedAt: testCreatedAt.Add(time.Second), Id: 5},
+					Limit:  1,
+				},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectQuery("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*").
+					WithArgs(2, a.page.Cursor.CreatedAt, a.page.Cursor.Id, 2).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(testRow...).AddRow(testRow2...)).
+					RowsWillBeClosed()
+			},
+			want: &entities.PaymentsPage{
+				Payments:   entities.Payments{incomingPayment},
+				NextCursor: (&entities.LedgerCursor{CreatedAt: testCreatedAt, Id: 4}).String(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			got, err := p.ListByAccount(tt.args.ctx, tt.args.account, tt.args.page)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("ListByAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("ListByAccount() got = %v, want nil", got)
+				}
+				return
+			}
+
+			if got.NextCursor != tt.want.NextCursor {
+				t.Fatalf("ListByAccount() got cursor = %s, want %s", got.NextCursor, tt.want.NextCursor)
+			}
+
+			if len(got.Payments) != len(tt.want.Payments) {
+				t.Fatalf("ListByAccount() got = %d, want %d", len(got.Payments), len(tt.want.Payments))
 			}
 
-			for i := range got {
-				if !reflect.DeepEqual(got[i], tt.want[i]) {
-					t.Fatalf("List() got = %v, want %v", got[i], tt.want[i])
+			for i := range got.Payments {
+				if !reflect.DeepEqual(got.Payments[i], tt.want.Payments[i]) {
+					t.Fatalf("ListByAccount() got = %v, want %v", got.Payments[i], tt.want.Payments[i])
 				}
 			}
 		})
 	}
 }
+
+func TestLedgers_GetByGuidTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"p1.id", "p1.guid", "p1.account_id", "p1.amount", "p1.type", "p1.fx_rate", "p1.source_amount", "p1.target_amount", "p1.refund_of", "p1.batch_id", "p1.updated_at", "p1.created_at", "p1.overdraft",
+		"p2.id", "p2.guid", "p2.account_id", "p2.amount", "p2.type", "p2.fx_rate", "p2.source_amount", "p2.target_amount", "p2.refund_of", "p2.batch_id", "p2.updated_at", "p2.created_at", "p2.overdraft",
+		"a1.user_name", "a2.user_name", "a1.currency", "a2.currency"}
+
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	testRefundOf := "a1b2c3d4-c06b-4a45-9cd9-85936d4b9665"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+	refundOfBytes := uuid.FromStringOrNil(testRefundOf).Bytes()
+	testCreatedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		guid    string
+		before  func(string)
+		want    *entities.Ledger
+		wantErr error
+	}{
+		{
+			name:    "malformed guid",
+			guid:    "123",
+			before:  func(string) {},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "ledger not found",
+			guid: testGuid,
+			before: func(guid string) {
+				mock.ExpectQuery("SELECT .* FROM payments .* WHERE p1.guid = .*").
+					WithArgs(guidBytes).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "refund ledger",
+			guid: testGuid,
+			before: func(guid string) {
+				mock.ExpectQuery("SELECT .* FROM payments .* WHERE p1.guid = .*").
+					WithArgs(guidBytes).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						3, guidBytes, 1, "1.5", "refund", nil, nil, nil, refundOfBytes, nil, testCreatedAt, testCreatedAt, false,
+						4, guidBytes, 2, "-1.5", "refund", nil, nil, nil, refundOfBytes, nil, testCreatedAt, testCreatedAt, false,
+						"alice123", "bob456", "USD", "USD",
+					))
+			},
+			want: &entities.Ledger{Id: testGuid, CreatedAt: testCreatedAt, Payments: [2]*entities.Payment{
+				&entities.Payment{
+					Ledger:    testGuid,
+					Account:   "bob456",
+					Amount:    entities.NewMoney(decimal.RequireFromString("-1.5"), "USD"),
+					ToAccount: "alice123",
+					Direction: entities.Outgoing,
+					Type:      entities.Refund,
+					RefundOf:  testRefundOf,
+				},
+				&entities.Payment{
+					Ledger:      testGuid,
+					Account:     "alice123",
+					Amount:      entities.NewMoney(decimal.RequireFromString("1.5"), "USD"),
+					FromAccount: "bob456",
+					Direction:   entities.Incoming,
+					Type:        entities.Refund,
+					RefundOf:    testRefundOf,
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+			mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+			mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+			mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+			mock.ExpectBegin()
+			tt.before(tt.guid)
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := p.GetByGuidTx(tx, context.Background(), tt.guid)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetByGuidTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetByGuidTx() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLedgers_GetByGuid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"p1.id", "p1.guid", "p1.account_id", "p1.amount", "p1.type", "p1.fx_rate", "p1.source_amount", "p1.target_amount", "p1.refund_of", "p1.batch_id", "p1.updated_at", "p1.created_at", "p1.overdraft",
+		"p2.id", "p2.guid", "p2.account_id", "p2.amount", "p2.type", "p2.fx_rate", "p2.source_amount", "p2.target_amount", "p2.refund_of", "p2.batch_id", "p2.updated_at", "p2.created_at", "p2.overdraft",
+		"a1.user_name", "a2.user_name", "a1.currency", "a2.currency"}
+
+	testGuid := "e9b0c72f-c08f-4e00-b158-42ae88f0c18e"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+	testCreatedAt := time.Now()
+
+	queryRowError := xerrors.New("query_row_error")
+
+	tests := []struct {
+		name    string
+		guid    string
+		before  func()
+		want    *entities.Ledger
+		wantErr error
+	}{
+		{
+			name:    "malformed guid",
+			guid:    "123",
+			before:  func() {},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "QueryRow returns error",
+			guid: testGuid,
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM payments .* WHERE p1.guid = .*").
+					WithArgs(guidBytes).
+					WillReturnError(queryRowError)
+			},
+			wantErr: queryRowError,
+		},
+		{
+			name: "transfer ledger",
+			guid: testGuid,
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM payments .* WHERE p1.guid = .*").
+					WithArgs(guidBytes).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						5, guidBytes, 2, "10", "transfer", nil, nil, nil, nil, nil, testCreatedAt, testCreatedAt, false,
+						6, guidBytes, 1, "-10", "transfer", nil, nil, nil, nil, nil, testCreatedAt, testCreatedAt, false,
+						"bob456", "alice123", "USD", "USD",
+					))
+			},
+			want: &entities.Ledger{Id: testGuid, CreatedAt: testCreatedAt, Payments: [2]*entities.Payment{
+				&entities.Payment{
+					Ledger:    testGuid,
+					Account:   "alice123",
+					Amount:    entities.NewMoney(decimal.RequireFromString("-10"), "USD"),
+					ToAccount: "bob456",
+					Direction: entities.Outgoing,
+					Type:      entities.Transfer,
+				},
+				&entities.Payment{
+					Ledger:      testGuid,
+					Account:     "bob456",
+					Amount:      entities.NewMoney(decimal.RequireFromString("10"), "USD"),
+					FromAccount: "alice123",
+					Direction:   entities.Incoming,
+					Type:        entities.Transfer,
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+			mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+			mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+			mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+			tt.before()
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			got, err := p.GetByGuid(context.Background(), tt.guid)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetByGuid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetByGuid() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLedgers_RefundedTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	queryRowError := xerrors.New("query_row_error")
+
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+	testSent := &entities.Payment{Ledger: testGuid, Amount: entities.NewMoney(decimal.RequireFromString("-10"), "USD")}
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    entities.Money
+		wantErr error
+	}{
+		{
+			name: "QueryRow returns error",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM payments p WHERE p.refund_of = .*").
+					WithArgs(guidBytes).
+					WillReturnError(queryRowError)
+			},
+			want:    testSent.Amount,
+			wantErr: queryRowError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM payments p WHERE p.refund_of = .*").
+					WithArgs(guidBytes).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("2.5"))
+			},
+			want: entities.NewMoney(decimal.RequireFromString("2.5"), "USD"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+			mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+			mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+			mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+			mock.ExpectBegin()
+			tt.before()
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := p.RefundedTx(tx, context.Background(), testSent)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("RefundedTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("RefundedTx() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLedgers_AddRefundTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	execContextError := xerrors.New("exec_context_error")
+	testCreatedAt := time.Now()
+
+	testRefundOf := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	refundOfBytes := uuid.FromStringOrNil(testRefundOf).Bytes()
+
+	credit := &entities.Account{AccountId: "bob456", Currency: "USD"}
+	credit.SetId(2)
+	debit := &entities.Account{AccountId: "alice123", Currency: "USD"}
+	debit.SetId(1)
+
+	tests := []struct {
+		name     string
+		refundOf string
+		before   func()
+		wantErr  error
+	}{
+		{
+			name:     "malformed guid",
+			refundOf: "123",
+			before:   func() {},
+			wantErr:  xerrors.New("wrong ledger guid 123: uuid: incorrect UUID length: 123"),
+		},
+		{
+			name:     "ExecContext returns error",
+			refundOf: testRefundOf,
+			before: func() {
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
+					WithArgs(
+						sqlmock.AnyArg(), 2, decimal.RequireFromString("-1.5"),
+						sqlmock.AnyArg(), 1, decimal.RequireFromString("1.5"),
+						"refund", nil, nil, nil, refundOfBytes, nil,
+					).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name:     "works well",
+			refundOf: testRefundOf,
+			before: func() {
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
+					WithArgs(
+						sqlmock.AnyArg(), 2, decimal.RequireFromString("-1.5"),
+						sqlmock.AnyArg(), 1, decimal.RequireFromString("1.5"),
+						"refund", nil, nil, nil, refundOfBytes, nil,
+					).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "overdraft"}).AddRow(testCreatedAt, false))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+			mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+			mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+			mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+			mock.ExpectBegin()
+			tt.before()
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := p.AddRefundTx(tx, context.Background(), tt.refundOf, credit, debit, entities.NewMoney(decimal.RequireFromString("1.5"), "USD"), nil)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("AddRefundTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			for _, leg := range got.Payments {
+				if leg.RefundOf != tt.refundOf || leg.Type != entities.Refund {
+					t.Fatalf("AddRefundTx() got leg %+v isn't linked to %s", leg, tt.refundOf)
+				}
+			}
+		})
+	}
+}
+
+func TestLedgers_AddBatchTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	execContextError := xerrors.New("exec_context_error")
+	testCreatedAt := time.Now()
+
+	testBatchId := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	batchBytes := uuid.FromStringOrNil(testBatchId).Bytes()
+
+	credit := &entities.Account{AccountId: "alice123", Currency: "USD"}
+	credit.SetId(1)
+	debit := &entities.Account{AccountId: "bob456", Currency: "USD"}
+	debit.SetId(2)
+
+	tests := []struct {
+		name    string
+		batchId string
+		before  func()
+		wantErr error
+	}{
+		{
+			name:    "malformed batch id",
+			batchId: "123",
+			before:  func() {},
+			wantErr: xerrors.New("wrong batch id 123: uuid: incorrect UUID length: 123"),
+		},
+		{
+			name:    "ExecContext returns error",
+			batchId: testBatchId,
+			before: func() {
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
+					WithArgs(
+						sqlmock.AnyArg(), 1, decimal.RequireFromString("-1.5"),
+						sqlmock.AnyArg(), 2, decimal.RequireFromString("1.5"),
+						"transfer", nil, nil, nil, nil, batchBytes,
+					).
+					WillReturnError(execContextError)
+			},
+			wantErr: execContextError,
+		},
+		{
+			name:    "works well",
+			batchId: testBatchId,
+			before: func() {
+				mock.ExpectQuery("INSERT INTO payments (.*) VALUES (.*), (.*) RETURNING created_at").
+					WithArgs(
+						sqlmock.AnyArg(), 1, decimal.RequireFromString("-1.5"),
+						sqlmock.AnyArg(), 2, decimal.RequireFromString("1.5"),
+						"transfer", nil, nil, nil, nil, batchBytes,
+					).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "overdraft"}).AddRow(testCreatedAt, false))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+			mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+			mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+			mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+			mock.ExpectBegin()
+			tt.before()
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := p.AddBatchTx(tx, context.Background(), tt.batchId, credit, debit, entities.NewMoney(decimal.RequireFromString("1.5"), "USD"), nil)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("AddBatchTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			for _, leg := range got.Payments {
+				if leg.Batch != tt.batchId || leg.Type != entities.Transfer {
+					t.Fatalf("AddBatchTx() got leg %+v isn't a part of batch %s", leg, tt.batchId)
+				}
+			}
+		})
+	}
+}
+
+func TestLedgers_TransferTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	queryError := xerrors.New("query_error")
+	testCreatedAt := time.Now()
+
+	columns := []string{"id", "user_name", "balance", "available", "currency", "status", "created_at", "updated_at",
+		"owner", "overdraft_limit", "overdraft_expires_at", "leg_created_at", "leg_overdraft"}
+
+	credit := &entities.Account{AccountId: "alice123", Balance: decimal.RequireFromString("10"),
+		AvailableBalance: decimal.RequireFromString("10"), Currency: "USD", Status: entities.AccountActive}
+	credit.SetId(1)
+	debit := &entities.Account{AccountId: "bob456", Balance: decimal.RequireFromString("5"),
+		AvailableBalance: decimal.RequireFromString("5"), Currency: "USD", Status: entities.AccountActive}
+	debit.SetId(2)
+
+	tests := []struct {
+		name       string
+		amount     entities.Money
+		before     func()
+		wantLedger bool
+		wantErr    error
+	}{
+		{
+			name:   "Query returns error",
+			amount: entities.NewMoney(decimal.RequireFromString("1.5"), "USD"),
+			before: func() {
+				mock.ExpectQuery("WITH src AS .* INSERT INTO payments .*").
+					WithArgs("alice123", "bob456", decimal.RequireFromString("1.5"), "USD", sqlmock.AnyArg(), "transfer").
+					WillReturnError(queryError)
+			},
+			wantErr: queryError,
+		},
+		{
+			name:   "transfer isn't made",
+			amount: entities.NewMoney(decimal.RequireFromString("20"), ""),
+			before: func() {
+				mock.ExpectQuery("WITH src AS .* INSERT INTO payments .*").
+					WithArgs("alice123", "bob456", decimal.RequireFromString("20"), nil, sqlmock.AnyArg(), "transfer").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, "alice123", "10", "10", "USD", "active", nil, nil, nil, "0", nil, nil, nil).
+						AddRow(2, "bob456", "5", "5", "USD", "active", nil, nil, nil, "0", nil, nil, nil))
+			},
+		},
+		{
+			name:   "transfer of limited account isn't made",
+			amount: entities.NewMoney(decimal.RequireFromString("1.5"), ""),
+			before: func() {
+				mock.ExpectQuery(`WITH src AS .* AND NOT EXISTS \(SELECT 1 FROM account_limits l WHERE l.account_id = c.id OR l.account_id IS NULL AND l.currency = c.currency\) .* INSERT INTO payments .*`).
+					WithArgs("alice123", "bob456", decimal.RequireFromString("1.5"), nil, sqlmock.AnyArg(), "transfer").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, "alice123", "10", "10", "USD", "active", nil, nil, nil, "0", nil, nil, nil).
+						AddRow(2, "bob456", "5", "5", "USD", "active", nil, nil, nil, "0", nil, nil, nil))
+			},
+		},
+		{
+			name:   "works well",
+			amount: entities.NewMoney(decimal.RequireFromString("1.5"), ""),
+			before: func() {
+				mock.ExpectQuery("WITH src AS .* INSERT INTO payments .*").
+					WithArgs("alice123", "bob456", decimal.RequireFromString("1.5"), nil, sqlmock.AnyArg(), "transfer").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, "alice123", "10", "10", "USD", "active", nil, nil, nil, "0", nil, testCreatedAt, false).
+						AddRow(2, "bob456", "5", "5", "USD", "active", nil, nil, nil, "0", nil, testCreatedAt, false))
+			},
+			wantLedger: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+			mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+			mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+			mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+			mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+			mock.ExpectBegin()
+			tt.before()
+
+			p, err := NewLedgers(db)
+			if err != nil {
+				t.Fatalf("NewLedgers error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := p.TransferTx(tx, context.Background(), "alice123", "bob456", tt.amount)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("TransferTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Credit, credit) || !reflect.DeepEqual(got.Debit, debit) {
+				t.Fatalf("TransferTx() got accounts %+v, %+v, want %+v, %+v", got.Credit, got.Debit, credit, debit)
+			}
+
+			if !tt.wantLedger {
+				if got.Ledger != nil {
+					t.Fatalf("TransferTx() got ledger %+v, want nil", got.Ledger)
+				}
+				return
+			}
+
+			if got.Ledger == nil || got.Ledger.Id == "" || !got.Ledger.CreatedAt.Equal(testCreatedAt) {
+				t.Fatalf("TransferTx() got ledger %+v", got.Ledger)
+			}
+
+			outgoing, incoming := got.Ledger.Payments[0], got.Ledger.Payments[1]
+			if !outgoing.Amount.Equal(entities.NewMoney(decimal.RequireFromString("-1.5"), "USD")) || outgoing.Account != "alice123" ||
+				!incoming.Amount.Equal(entities.NewMoney(decimal.RequireFromString("1.5"), "USD")) || incoming.Account != "bob456" ||
+				outgoing.Ledger != got.Ledger.Id || incoming.Ledger != got.Ledger.Id {
+				t.Fatalf("TransferTx() got legs %+v, %+v", outgoing, incoming)
+			}
+		})
+	}
+}
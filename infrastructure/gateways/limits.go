@@ -0,0 +1,113 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"golang.org/x/xerrors"
+)
+
+type Limits struct {
+	fetchQuery *fetchLimitsQuery
+	usageQuery *limitsUsageQuery
+}
+
+func NewLimits(d *sql.DB) (repositories.Limits, error) {
+	var err error
+	table := new(Limits)
+
+	table.fetchQuery, err = newFetchLimitsQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation fetchLimitsQuery: %w", err)
+	}
+
+	table.usageQuery, err = newLimitsUsageQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation limitsUsageQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//Get returns limits of the account or of its currency, account without any of them isn't limited
+func (l *Limits) Get(ctx context.Context, account *entities.Account) (*entities.Limits, error) {
+	return l.scan(l.fetchQuery.QueryRowContext(ctx, account.GetId(), account.Currency))
+}
+
+//GetTx returns limits of the account or of its currency, account without any of them isn't limited
+func (l *Limits) GetTx(tx *sql.Tx, ctx context.Context, account *entities.Account) (*entities.Limits, error) {
+	return l.scan(tx.StmtContext(ctx, l.fetchQuery.Stmt).QueryRowContext(ctx, account.GetId(), account.Currency))
+}
+
+func (l *Limits) scan(row *sql.Row) (*entities.Limits, error) {
+	limits := &models.Limits{}
+	if err := row.Scan(limits.BindScan()...); err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return &entities.Limits{}, nil
+		}
+		return nil, err
+	}
+
+	return limits.ToDomain(), nil
+}
+
+//Usage sums outgoing payments of the account in windows of limits at the moment
+func (l *Limits) Usage(ctx context.Context, account *entities.Account, at time.Time) (*entities.LimitsUsage, error) {
+	day, month, hour := entities.LimitsWindows(at)
+	return l.scanUsage(l.usageQuery.QueryRowContext(ctx, account.GetId(), day, month, hour))
+}
+
+//UsageTx sums outgoing payments of the account in windows of limits at the moment, under serializable
+//isolation concurrent payment of the account makes one of the transactions fail, so limits can't be overrun
+func (l *Limits) UsageTx(tx *sql.Tx, ctx context.Context, account *entities.Account, at time.Time) (*entities.LimitsUsage, error) {
+	day, month, hour := entities.LimitsWindows(at)
+	return l.scanUsage(tx.StmtContext(ctx, l.usageQuery.Stmt).QueryRowContext(ctx, account.GetId(), day, month, hour))
+}
+
+func (l *Limits) scanUsage(row *sql.Row) (*entities.LimitsUsage, error) {
+	usage := &models.LimitsUsage{}
+	if err := row.Scan(usage.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return usage.ToDomain(), nil
+}
+
+type fetchLimitsQuery struct {
+	*sql.Stmt
+}
+
+func newFetchLimitsQuery(d *sql.DB) (*fetchLimitsQuery, error) {
+	stmt, err := d.Prepare(`SELECT l.max_amount, l.daily_amount, l.monthly_amount, l.hourly_count
+		FROM account_limits l
+		WHERE l.account_id = $1 OR l.account_id IS NULL AND l.currency = $2
+		ORDER BY l.account_id NULLS LAST LIMIT 1`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetchLimitsQuery{stmt}, nil
+}
+
+type limitsUsageQuery struct {
+	*sql.Stmt
+}
+
+func newLimitsUsageQuery(d *sql.DB) (*limitsUsageQuery, error) {
+	stmt, err := d.Prepare(`SELECT COALESCE(-SUM(p.amount) FILTER (WHERE p.created_at >= $2), 0),
+			COALESCE(-SUM(p.amount) FILTER (WHERE p.created_at >= $3), 0),
+			COUNT(*) FILTER (WHERE p.created_at >= $4)
+		FROM payments p
+		WHERE p.account_id = $1 AND p.amount < 0 AND p.created_at >= LEAST($2, $3, $4)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitsUsageQuery{stmt}, nil
+}
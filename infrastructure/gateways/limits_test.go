@@ -0,0 +1,220 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestNewLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	newFetchLimitsError := xerrors.New("new_fetch_limits_error")
+	newLimitsUsageError := xerrors.New("new_limits_usage_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    repositories.Limits
+		wantErr error
+	}{
+		{
+			name: "newFetchLimitsQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*").
+					WillReturnError(newFetchLimitsError)
+			},
+			wantErr: newFetchLimitsError,
+		},
+		{
+			name: "newLimitsUsageQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE .*").
+					WillReturnError(newLimitsUsageError)
+			},
+			wantErr: newLimitsUsageError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE .*")
+			},
+			want: &Limits{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+			got, err := NewLimits(db)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("NewLimits() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil {
+				got.(*Limits).fetchQuery = tt.want.(*Limits).fetchQuery
+				got.(*Limits).usageQuery = tt.want.(*Limits).usageQuery
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewLimits() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimits_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"max_amount", "daily_amount", "monthly_amount", "hourly_count"}
+
+	testAccount := &entities.Account{AccountId: "alice123", Currency: "USD"}
+	testAccount.SetId(1)
+
+	testMax := decimal.RequireFromString("100")
+	testDaily := decimal.RequireFromString("250")
+	testHourly := int64(5)
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    *entities.Limits
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM account_limits l WHERE .*").
+					WithArgs(testAccount.GetId(), testAccount.Currency).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "no limits",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM account_limits l WHERE .*").
+					WithArgs(testAccount.GetId(), testAccount.Currency).
+					WillReturnError(sql.ErrNoRows)
+			},
+			want: &entities.Limits{},
+		},
+		{
+			name: "null columns aren't limited",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM account_limits l WHERE .*").
+					WithArgs(testAccount.GetId(), testAccount.Currency).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("100", "250", nil, 5))
+			},
+			want: &entities.Limits{MaxAmount: &testMax, DailyAmount: &testDaily, HourlyCount: &testHourly},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE .*")
+			tt.before()
+
+			l, err := NewLimits(db)
+			if err != nil {
+				t.Fatalf("NewLimits error: %+v", err)
+			}
+
+			got, err := l.Get(context.Background(), testAccount)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Get() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimits_Usage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testAccount := &entities.Account{AccountId: "alice123", Currency: "USD"}
+	testAccount.SetId(1)
+
+	testAt := time.Date(2019, 8, 12, 15, 30, 0, 0, time.UTC)
+	day := time.Date(2019, 8, 12, 0, 0, 0, 0, time.UTC)
+	month := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	hour := time.Date(2019, 8, 12, 14, 30, 0, 0, time.UTC)
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    *entities.LimitsUsage
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM payments p WHERE .*").
+					WithArgs(testAccount.GetId(), day, month, hour).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM payments p WHERE .*").
+					WithArgs(testAccount.GetId(), day, month, hour).
+					WillReturnRows(sqlmock.NewRows([]string{"daily", "monthly", "hourly"}).AddRow("25.5", "300", 2))
+			},
+			want: &entities.LimitsUsage{DailyAmount: decimal.RequireFromString("25.5"), MonthlyAmount: decimal.RequireFromString("300"), HourlyCount: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*")
+			mock.ExpectPrepare("SELECT .* FROM payments p WHERE .*")
+			tt.before()
+
+			l, err := NewLimits(db)
+			if err != nil {
+				t.Fatalf("NewLimits error: %+v", err)
+			}
+
+			got, err := l.Usage(context.Background(), testAccount, testAt)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Usage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Usage() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
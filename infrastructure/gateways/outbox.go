@@ -0,0 +1,189 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/common"
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"golang.org/x/xerrors"
+)
+
+//deliveryColumns selects delivery with its event and webhook, d, e and s are expected aliases
+const deliveryColumns = `d.id, d.status, d.attempts, d.next_attempt_at, d.last_error,
+	e.guid, e.type, e.payload, e.created_at, s.guid, s.url, s.secret`
+
+type Outbox struct {
+	createQuery *createEventQuery
+	fanOutQuery *fanOutQuery
+	claimQuery  *claimDeliveriesQuery
+	updateQuery *updateDeliveryQuery
+}
+
+func NewOutbox(d *sql.DB) (repositories.Outbox, error) {
+	var err error
+	table := new(Outbox)
+
+	table.createQuery, err = newCreateEventQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation createEventQuery: %w", err)
+	}
+
+	table.fanOutQuery, err = newFanOutQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation fanOutQuery: %w", err)
+	}
+
+	table.claimQuery, err = newClaimDeliveriesQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation claimDeliveriesQuery: %w", err)
+	}
+
+	table.updateQuery, err = newUpdateDeliveryQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation updateDeliveryQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//AddTx writes event with data marshaled to json within tx of the change it tells about
+func (o *Outbox) AddTx(tx *sql.Tx, ctx context.Context, eventType entities.EventType, data interface{}) (*entities.Event, error) {
+	event, err := models.NewEvent(eventType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.StmtContext(ctx, o.createQuery.Stmt).QueryRowContext(ctx, event.Bind()...)
+	if err := row.Scan(&common.NullTime{V: &event.CreatedAt}); err != nil {
+		return nil, err
+	}
+
+	return event.ToDomain(), nil
+}
+
+//FanOut creates pending deliveries of up to limit new events for each active webhook created before
+//the event and returns number of created deliveries, events nobody subscribed to are dropped
+func (o *Outbox) FanOut(ctx context.Context, limit int) (int64, error) {
+	res, err := o.fanOutQuery.ExecContext(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+//Claim returns up to limit deliveries which are due, they aren't due for other dispatchers until leaseUntil
+func (o *Outbox) Claim(ctx context.Context, limit int, leaseUntil time.Time) (entities.Deliveries, error) {
+	rows, err := o.claimQuery.QueryContext(ctx, limit, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := entities.Deliveries{}
+	for rows.Next() {
+		d := &models.Delivery{}
+		if err := rows.Scan(d.BindScan()...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d.ToDomain())
+	}
+
+	return deliveries, rows.Err()
+}
+
+//UpdateDelivery stores result of the delivery attempt
+func (o *Outbox) UpdateDelivery(ctx context.Context, delivery *entities.Delivery) error {
+	_, err := o.updateQuery.ExecContext(ctx, models.NewDelivery(delivery).Bind()...)
+	return err
+}
+
+type createEventQuery struct {
+	*sql.Stmt
+}
+
+func newCreateEventQuery(d *sql.DB) (*createEventQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO outbox_events (id, guid, type, payload, dispatched_at, created_at)
+		VALUES (DEFAULT, $1, $2, $3, NULL, DEFAULT)
+		RETURNING created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createEventQuery{stmt}, nil
+}
+
+type fanOutQuery struct {
+	*sql.Stmt
+}
+
+//newFanOutQuery marks events as dispatched and creates their deliveries in one statement,
+//locked events are skipped so several dispatchers don't wait for each other. Webhook gets only events
+//created after it, events without such webhooks are marked as dispatched too and are never delivered
+func newFanOutQuery(d *sql.DB) (*fanOutQuery, error) {
+	stmt, err := d.Prepare(`WITH events AS (
+			UPDATE outbox_events SET dispatched_at = NOW()
+			WHERE id IN (
+				SELECT id FROM outbox_events WHERE dispatched_at IS NULL
+				ORDER BY id LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, created_at
+		)
+		INSERT INTO webhook_deliveries (id, event_id, subscription_id, status, attempts, next_attempt_at, created_at, updated_at)
+		SELECT DEFAULT, e.id, s.id, 'pending', 0, NOW(), DEFAULT, DEFAULT
+		FROM events e
+		JOIN webhook_subscriptions s ON s.active AND s.created_at <= e.created_at
+		ON CONFLICT (event_id, subscription_id) DO NOTHING`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fanOutQuery{stmt}, nil
+}
+
+type claimDeliveriesQuery struct {
+	*sql.Stmt
+}
+
+//newClaimDeliveriesQuery leases due deliveries of active webhooks by moving their next attempt forward
+func newClaimDeliveriesQuery(d *sql.DB) (*claimDeliveriesQuery, error) {
+	stmt, err := d.Prepare(`UPDATE webhook_deliveries d SET next_attempt_at = $2, updated_at = NOW()
+		FROM outbox_events e, webhook_subscriptions s
+		WHERE d.event_id = e.id AND d.subscription_id = s.id AND d.id IN (
+			SELECT pd.id FROM webhook_deliveries pd
+			JOIN webhook_subscriptions ps ON pd.subscription_id = ps.id AND ps.active
+			WHERE pd.status = 'pending' AND pd.next_attempt_at <= NOW()
+			ORDER BY pd.next_attempt_at LIMIT $1
+			FOR UPDATE OF pd SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claimDeliveriesQuery{stmt}, nil
+}
+
+type updateDeliveryQuery struct {
+	*sql.Stmt
+}
+
+func newUpdateDeliveryQuery(d *sql.DB) (*updateDeliveryQuery, error) {
+	stmt, err := d.Prepare(`UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, updated_at = NOW()
+		WHERE id = $1`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updateDeliveryQuery{stmt}, nil
+}
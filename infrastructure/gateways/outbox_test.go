@@ -0,0 +1,228 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/xerrors"
+)
+
+var deliveryRowColumns = []string{"id", "status", "attempts", "next_attempt_at", "last_error",
+	"e.guid", "e.type", "e.payload", "e.created_at", "s.guid", "s.url", "s.secret"}
+
+func expectOutboxPrepare(mock sqlmock.Sqlmock) {
+	mock.ExpectPrepare("INSERT INTO outbox_events (.*) VALUES (.*)")
+	mock.ExpectPrepare("WITH events AS (.*) INSERT INTO webhook_deliveries .*")
+	mock.ExpectPrepare("UPDATE webhook_deliveries d SET .* RETURNING .*")
+	mock.ExpectPrepare("UPDATE webhook_deliveries SET .* WHERE id = .*")
+}
+
+func TestNewOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	prepareError := xerrors.New("prepare_error")
+
+	queries := []string{
+		"INSERT INTO outbox_events (.*) VALUES (.*)",
+		"WITH events AS (.*) INSERT INTO webhook_deliveries .*",
+		"UPDATE webhook_deliveries d SET .* RETURNING .*",
+		"UPDATE webhook_deliveries SET .* WHERE id = .*",
+	}
+
+	for i := range queries {
+		for _, q := range queries[:i] {
+			mock.ExpectPrepare(q)
+		}
+		mock.ExpectPrepare(queries[i]).WillReturnError(prepareError)
+
+		if _, err := NewOutbox(db); !xerrors.Is(err, prepareError) {
+			t.Fatalf("NewOutbox() error = %v, wantErr %v", err, prepareError)
+		}
+	}
+
+	expectOutboxPrepare(mock)
+	if _, err := NewOutbox(db); err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+}
+
+func TestOutbox_AddTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+
+	expectOutboxPrepare(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO outbox_events (.*) VALUES (.*)").
+		WithArgs(sqlmock.AnyArg(), string(entities.PaymentCompleted), []byte(`{"id":"1"}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+
+	o, err := NewOutbox(db)
+	if err != nil {
+		t.Fatalf("NewOutbox error: %+v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("db.Begin error: %+v", err)
+	}
+
+	got, err := o.AddTx(tx, context.Background(), entities.PaymentCompleted, map[string]string{"id": "1"})
+	if err != nil {
+		t.Fatalf("AddTx() error = %v", err)
+	}
+
+	if _, err := uuid.FromString(got.Id); err != nil || got.Type != entities.PaymentCompleted ||
+		!got.CreatedAt.Equal(createdAt) || string(got.Data) != `{"id":"1"}` {
+		t.Errorf("AddTx() got = %+v", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestOutbox_FanOut(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectOutboxPrepare(mock)
+	mock.ExpectExec("WITH events AS (.*) INSERT INTO webhook_deliveries .*").
+		WithArgs(100).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	o, err := NewOutbox(db)
+	if err != nil {
+		t.Fatalf("NewOutbox error: %+v", err)
+	}
+
+	got, err := o.FanOut(context.Background(), 100)
+	if err != nil || got != 3 {
+		t.Errorf("FanOut() = %v, %v, want 3", got, err)
+	}
+}
+
+func TestOutbox_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+	leaseUntil := time.Date(2019, 8, 10, 12, 0, 10, 0, time.UTC)
+	createdAt := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+
+	queryError := xerrors.New("query_error")
+
+	want := &entities.Delivery{
+		Event:         &entities.Event{Id: testGuid, Type: entities.PaymentCompleted, CreatedAt: createdAt, Data: json.RawMessage(`{}`)},
+		Webhook:       &entities.Webhook{Id: testGuid, URL: "https://example.com/hooks", Secret: "0123456789abcdef", Active: true},
+		Status:        entities.DeliveryPending,
+		Attempts:      2,
+		NextAttemptAt: leaseUntil,
+	}
+	want.SetId(7)
+
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		err     error
+		want    entities.Deliveries
+		wantErr error
+	}{
+		{
+			name:    "query returns error",
+			err:     queryError,
+			wantErr: queryError,
+		},
+		{
+			name: "nothing to deliver",
+			rows: sqlmock.NewRows(deliveryRowColumns),
+			want: entities.Deliveries{},
+		},
+		{
+			name: "works fine",
+			rows: sqlmock.NewRows(deliveryRowColumns).AddRow(7, "pending", 2, leaseUntil, nil,
+				guidBytes, "payment.completed", []byte(`{}`), createdAt, guidBytes, "https://example.com/hooks", "0123456789abcdef"),
+			want: entities.Deliveries{want},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectOutboxPrepare(mock)
+			q := mock.ExpectQuery("UPDATE webhook_deliveries d SET .* RETURNING .*").WithArgs(10, leaseUntil)
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+			} else {
+				q.WillReturnRows(tt.rows)
+			}
+
+			o, err := NewOutbox(db)
+			if err != nil {
+				t.Fatalf("NewOutbox error: %+v", err)
+			}
+
+			got, err := o.Claim(context.Background(), 10, leaseUntil)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Claim() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Claim() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutbox_UpdateDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	next := time.Date(2019, 8, 10, 12, 0, 10, 0, time.UTC)
+
+	delivery := &entities.Delivery{Status: entities.DeliveryDead, Attempts: 10, NextAttemptAt: next, LastError: "timeout"}
+	delivery.SetId(7)
+
+	expectOutboxPrepare(mock)
+	mock.ExpectExec("UPDATE webhook_deliveries SET .* WHERE id = .*").
+		WithArgs(int64(7), "dead", int64(10), next, "timeout").
+		WillReturnResult(driver.RowsAffected(1))
+
+	o, err := NewOutbox(db)
+	if err != nil {
+		t.Fatalf("NewOutbox error: %+v", err)
+	}
+
+	if err := o.UpdateDelivery(context.Background(), delivery); err != nil {
+		t.Errorf("UpdateDelivery() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
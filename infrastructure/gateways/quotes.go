@@ -0,0 +1,94 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/xerrors"
+)
+
+type Quotes struct {
+	fetchQuery  *fetchQuoteQuery
+	createQuery *createQuoteQuery
+}
+
+func NewQuotes(d *sql.DB) (repositories.Quotes, error) {
+	var err error
+	table := new(Quotes)
+
+	table.fetchQuery, err = newFetchQuoteQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation fetchQuoteQuery: %w", err)
+	}
+
+	table.createQuery, err = newCreateQuoteQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation createQuoteQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//Create stores quote under the new random id
+func (q *Quotes) Create(ctx context.Context, quote *entities.FXQuote) (*entities.FXQuote, error) {
+	row := q.createQuery.QueryRowContext(ctx, models.NewFXQuote(quote).Bind()...)
+
+	created := &models.FXQuote{}
+	if err := row.Scan(created.BindScan()...); err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return created.ToDomain(), nil
+}
+
+//GetByIdTx returns quote by id, malformed id is reported as sql.ErrNoRows
+func (q *Quotes) GetByIdTx(tx *sql.Tx, ctx context.Context, id string) (*entities.FXQuote, error) {
+	guid, err := uuid.FromString(id)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	row := tx.StmtContext(ctx, q.fetchQuery.Stmt).QueryRowContext(ctx, guid.Bytes())
+
+	quote := &models.FXQuote{}
+	if err := row.Scan(quote.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return quote.ToDomain(), nil
+}
+
+type fetchQuoteQuery struct {
+	*sql.Stmt
+}
+
+func newFetchQuoteQuery(d *sql.DB) (*fetchQuoteQuery, error) {
+	stmt, err := d.Prepare(`SELECT id, guid, source_currency, target_currency, rate, expires_at, created_at, updated_at
+		FROM fx_quotes WHERE guid = $1`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetchQuoteQuery{stmt}, nil
+}
+
+type createQuoteQuery struct {
+	*sql.Stmt
+}
+
+func newCreateQuoteQuery(d *sql.DB) (*createQuoteQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO fx_quotes (id, guid, source_currency, target_currency, rate, expires_at, created_at, updated_at)
+		VALUES (DEFAULT, $1, $2, $3, $4, $5, DEFAULT, DEFAULT)
+		RETURNING id, guid, source_currency, target_currency, rate, expires_at, created_at, updated_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createQuoteQuery{stmt}, nil
+}
@@ -0,0 +1,275 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestNewQuotes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	newFetchQuoteError := xerrors.New("new_fetch_quote_error")
+	newCreateQuoteError := xerrors.New("new_create_quote_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    repositories.Quotes
+		wantErr error
+	}{
+		{
+			name: "newFetchQuoteQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*").
+					WillReturnError(newFetchQuoteError)
+			},
+			wantErr: newFetchQuoteError,
+		},
+		{
+			name: "newCreateQuoteQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)").
+					WillReturnError(newCreateQuoteError)
+			},
+			wantErr: newCreateQuoteError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+			},
+			want: &Quotes{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+			got, err := NewQuotes(db)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("NewQuotes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil {
+				got.(*Quotes).fetchQuery = tt.want.(*Quotes).fetchQuery
+				got.(*Quotes).createQuery = tt.want.(*Quotes).createQuery
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewQuotes() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotes_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"id", "guid", "source_currency", "target_currency", "rate", "expires_at", "created_at", "updated_at"}
+
+	testGuid := uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665")
+	testExpiresAt := time.Unix(1563494460, 0)
+	testCreatedAt := time.Unix(1563494400, 0)
+	testRate := decimal.RequireFromString("0.9")
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	type args struct {
+		ctx   context.Context
+		quote *entities.FXQuote
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.FXQuote
+		wantErr error
+	}{
+		{
+			name: "QueryRowContext returns error",
+			args: args{
+				ctx: context.Background(),
+				quote: &entities.FXQuote{
+					FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: testRate},
+					ExpiresAt: testExpiresAt,
+				},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectQuery("INSERT INTO fx_quotes (.*) VALUES (.*)").
+					WithArgs(sqlmock.AnyArg(), "USD", "EUR", testRate, testExpiresAt).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: models.DBErrorWrapper{queryRowContextError},
+		},
+		{
+			name: "works well",
+			args: args{
+				ctx: context.Background(),
+				quote: &entities.FXQuote{
+					FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: testRate},
+					ExpiresAt: testExpiresAt,
+				},
+			},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectQuery("INSERT INTO fx_quotes (.*) VALUES (.*)").
+					WithArgs(sqlmock.AnyArg(), "USD", "EUR", testRate, testExpiresAt).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(
+						1, testGuid.Bytes(), "USD", "EUR", testRate, testExpiresAt, testCreatedAt, testCreatedAt,
+					))
+			},
+			want: &entities.FXQuote{
+				Id:        testGuid.String(),
+				FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: testRate},
+				ExpiresAt: testExpiresAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			q, err := NewQuotes(db)
+			if err != nil {
+				t.Fatalf("NewQuotes error: %+v", err)
+			}
+
+			got, err := q.Create(tt.args.ctx, tt.args.quote)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Create() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotes_GetByIdTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"id", "guid", "source_currency", "target_currency", "rate", "expires_at", "created_at", "updated_at"}
+
+	testGuid := uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665")
+	testExpiresAt := time.Unix(1563494460, 0)
+	testCreatedAt := time.Unix(1563494400, 0)
+	testRate := decimal.RequireFromString("0.9")
+
+	testRow := []driver.Value{1, testGuid.Bytes(), "USD", "EUR", testRate, testExpiresAt, testCreatedAt, testCreatedAt}
+
+	queryRowContextError := xerrors.New("query_row_context_error")
+
+	type args struct {
+		ctx context.Context
+		id  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.FXQuote
+		wantErr error
+	}{
+		{
+			name: "malformed id",
+			args: args{ctx: context.Background(), id: "abc"},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "QueryRowContext returns error",
+			args: args{ctx: context.Background(), id: testGuid.String()},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM fx_quotes WHERE .*").
+					WithArgs(testGuid.Bytes()).
+					WillReturnError(queryRowContextError)
+			},
+			wantErr: queryRowContextError,
+		},
+		{
+			name: "works well",
+			args: args{ctx: context.Background(), id: testGuid.String()},
+			before: func(a *args) {
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT .* FROM fx_quotes WHERE .*").
+					WithArgs(testGuid.Bytes()).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(testRow...))
+			},
+			want: &entities.FXQuote{
+				Id:        testGuid.String(),
+				FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: testRate},
+				ExpiresAt: testExpiresAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(&tt.args)
+
+			q, err := NewQuotes(db)
+			if err != nil {
+				t.Fatalf("NewQuotes error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := q.GetByIdTx(tx, tt.args.ctx, tt.args.id)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetByIdTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetByIdTx() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
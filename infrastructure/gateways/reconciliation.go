@@ -0,0 +1,145 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"golang.org/x/xerrors"
+)
+
+type Reconciliation struct {
+	balancesQuery   *balanceMismatchQuery
+	ledgersQuery    *unbalancedLedgersQuery
+	currenciesQuery *currencyImbalanceQuery
+}
+
+func NewReconciliation(d *sql.DB) (repositories.Reconciliation, error) {
+	var err error
+	table := new(Reconciliation)
+
+	table.balancesQuery, err = newBalanceMismatchQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation balanceMismatchQuery: %w", err)
+	}
+
+	table.ledgersQuery, err = newUnbalancedLedgersQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation unbalancedLedgersQuery: %w", err)
+	}
+
+	table.currenciesQuery, err = newCurrencyImbalanceQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation currencyImbalanceQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//BalanceMismatchesTx returns accounts which balance isn't opening balance plus their payments
+func (r *Reconciliation) BalanceMismatchesTx(tx *sql.Tx, ctx context.Context) (entities.Discrepancies, error) {
+	return r.query(tx.StmtContext(ctx, r.balancesQuery.Stmt), ctx, entities.BalanceMismatch)
+}
+
+//UnbalancedLedgersTx returns ledgers which haven't two legs or which legs don't net out
+func (r *Reconciliation) UnbalancedLedgersTx(tx *sql.Tx, ctx context.Context) (entities.Discrepancies, error) {
+	return r.query(tx.StmtContext(ctx, r.ledgersQuery.Stmt), ctx, entities.UnbalancedLedger)
+}
+
+//CurrencyImbalancesTx returns currencies which total changed by other means than conversions
+func (r *Reconciliation) CurrencyImbalancesTx(tx *sql.Tx, ctx context.Context) (entities.Discrepancies, error) {
+	return r.query(tx.StmtContext(ctx, r.currenciesQuery.Stmt), ctx, entities.CurrencyImbalance)
+}
+
+func (r *Reconciliation) query(stmt *sql.Stmt, ctx context.Context, kind entities.DiscrepancyKind) (entities.Discrepancies, error) {
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := entities.Discrepancies{}
+	for rows.Next() {
+		d := &models.Discrepancy{}
+		if err := rows.Scan(d.BindScan()...); err != nil {
+			return nil, err
+		}
+		found = append(found, d.ToDomain(kind))
+	}
+
+	return found, rows.Err()
+}
+
+type balanceMismatchQuery struct {
+	*sql.Stmt
+}
+
+func newBalanceMismatchQuery(d *sql.DB) (*balanceMismatchQuery, error) {
+	stmt, err := d.Prepare(`SELECT a.user_name, NULL, a.currency, NULL,
+			a.opening_balance + COALESCE(SUM(p.amount), 0), a.balance
+		FROM accounts a
+		LEFT JOIN payments p ON p.account_id = a.id
+		GROUP BY a.id
+		HAVING a.balance != a.opening_balance + COALESCE(SUM(p.amount), 0)
+		ORDER BY a.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balanceMismatchQuery{stmt}, nil
+}
+
+type unbalancedLedgersQuery struct {
+	*sql.Stmt
+}
+
+//newUnbalancedLedgersQuery nets legs of the same currency to zero,
+//legs of conversion should match source and target amounts instead
+func newUnbalancedLedgersQuery(d *sql.DB) (*unbalancedLedgersQuery, error) {
+	stmt, err := d.Prepare(`SELECT NULL, l.guid, NULL, l.legs, 0, l.net
+		FROM (
+			SELECT p.guid, COUNT(*) AS legs, MIN(p.id) AS id,
+				CASE WHEN COUNT(p.fx_rate) = 0 THEN SUM(p.amount)
+					ELSE SUM(CASE WHEN p.amount < 0 THEN p.amount + p.source_amount ELSE p.amount - p.target_amount END)
+				END AS net
+			FROM payments p
+			GROUP BY p.guid
+		) l
+		WHERE l.legs != 2 OR l.net != 0
+		ORDER BY l.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unbalancedLedgersQuery{stmt}, nil
+}
+
+type currencyImbalanceQuery struct {
+	*sql.Stmt
+}
+
+//newCurrencyImbalanceQuery compares change of all balances within currency
+//with the money converted in and out of it, settlement accounts are included
+func newCurrencyImbalanceQuery(d *sql.DB) (*currencyImbalanceQuery, error) {
+	stmt, err := d.Prepare(`SELECT NULL, NULL, c.currency, NULL, COALESCE(f.net, 0), c.net
+		FROM (SELECT a.currency, SUM(a.balance - a.opening_balance) AS net FROM accounts a GROUP BY a.currency) c
+		LEFT JOIN (
+			SELECT a.currency, SUM(p.amount) AS net
+			FROM payments p
+			JOIN accounts a ON p.account_id = a.id
+			WHERE p.fx_rate IS NOT NULL
+			GROUP BY a.currency
+		) f ON c.currency = f.currency
+		WHERE c.net != COALESCE(f.net, 0)
+		ORDER BY c.currency`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &currencyImbalanceQuery{stmt}, nil
+}
@@ -0,0 +1,204 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func expectReconciliationPrepare(mock sqlmock.Sqlmock) {
+	mock.ExpectPrepare("SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*")
+	mock.ExpectPrepare("SELECT .* FROM payments p GROUP BY p.guid .*")
+	mock.ExpectPrepare("SELECT .* FROM accounts a GROUP BY a.currency.*")
+}
+
+func TestNewReconciliation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	newBalanceMismatchError := xerrors.New("new_balance_mismatch_error")
+	newUnbalancedLedgersError := xerrors.New("new_unbalanced_ledgers_error")
+	newCurrencyImbalanceError := xerrors.New("new_currency_imbalance_error")
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    repositories.Reconciliation
+		wantErr error
+	}{
+		{
+			name: "newBalanceMismatchQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*").
+					WillReturnError(newBalanceMismatchError)
+			},
+			wantErr: newBalanceMismatchError,
+		},
+		{
+			name: "newUnbalancedLedgersQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p GROUP BY p.guid .*").
+					WillReturnError(newUnbalancedLedgersError)
+			},
+			wantErr: newUnbalancedLedgersError,
+		},
+		{
+			name: "newCurrencyImbalanceQuery returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p GROUP BY p.guid .*")
+				mock.ExpectPrepare("SELECT .* FROM accounts a GROUP BY a.currency.*").
+					WillReturnError(newCurrencyImbalanceError)
+			},
+			wantErr: newCurrencyImbalanceError,
+		},
+		{
+			name: "works well",
+			before: func() {
+				expectReconciliationPrepare(mock)
+			},
+			want: &Reconciliation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+			got, err := NewReconciliation(db)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Errorf("NewReconciliation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil {
+				got.(*Reconciliation).balancesQuery = tt.want.(*Reconciliation).balancesQuery
+				got.(*Reconciliation).ledgersQuery = tt.want.(*Reconciliation).ledgersQuery
+				got.(*Reconciliation).currenciesQuery = tt.want.(*Reconciliation).currenciesQuery
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewReconciliation() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconciliation_Checks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	columns := []string{"account", "ledger", "currency", "legs", "expected", "actual"}
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+
+	queryError := xerrors.New("query_error")
+
+	tests := []struct {
+		name    string
+		query   string
+		check   func(repositories.Reconciliation, *sql.Tx, context.Context) (entities.Discrepancies, error)
+		rows    *sqlmock.Rows
+		err     error
+		want    entities.Discrepancies
+		wantErr error
+	}{
+		{
+			name:    "balance mismatches query returns error",
+			query:   "SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*",
+			check:   repositories.Reconciliation.BalanceMismatchesTx,
+			err:     queryError,
+			wantErr: queryError,
+		},
+		{
+			name:  "balance mismatches",
+			query: "SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*",
+			check: repositories.Reconciliation.BalanceMismatchesTx,
+			rows:  sqlmock.NewRows(columns).AddRow("bob456", nil, "USD", nil, "995.5", "996.5"),
+			want: entities.Discrepancies{
+				{Kind: entities.BalanceMismatch, Account: "bob456", Currency: "USD", Expected: decimal.RequireFromString("995.5"), Actual: decimal.RequireFromString("996.5")},
+			},
+		},
+		{
+			name:  "ledgers are balanced",
+			query: "SELECT .* FROM payments p GROUP BY p.guid .*",
+			check: repositories.Reconciliation.UnbalancedLedgersTx,
+			rows:  sqlmock.NewRows(columns),
+			want:  entities.Discrepancies{},
+		},
+		{
+			name:  "unbalanced ledgers",
+			query: "SELECT .* FROM payments p GROUP BY p.guid .*",
+			check: repositories.Reconciliation.UnbalancedLedgersTx,
+			rows:  sqlmock.NewRows(columns).AddRow(nil, guidBytes, nil, 1, "0", "-1.5"),
+			want: entities.Discrepancies{
+				{Kind: entities.UnbalancedLedger, Ledger: testGuid, Legs: 1, Expected: decimal.Zero, Actual: decimal.RequireFromString("-1.5")},
+			},
+		},
+		{
+			name:  "currency imbalances",
+			query: "SELECT .* FROM accounts a GROUP BY a.currency.*",
+			check: repositories.Reconciliation.CurrencyImbalancesTx,
+			rows:  sqlmock.NewRows(columns).AddRow(nil, nil, "EUR", nil, "10", "12"),
+			want: entities.Discrepancies{
+				{Kind: entities.CurrencyImbalance, Currency: "EUR", Expected: decimal.RequireFromString("10"), Actual: decimal.RequireFromString("12")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectReconciliationPrepare(mock)
+			mock.ExpectBegin()
+			if tt.err != nil {
+				mock.ExpectQuery(tt.query).WillReturnError(tt.err)
+			} else {
+				mock.ExpectQuery(tt.query).WillReturnRows(tt.rows)
+			}
+
+			r, err := NewReconciliation(db)
+			if err != nil {
+				t.Fatalf("NewReconciliation error: %+v", err)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("db.Begin error: %+v", err)
+			}
+
+			got, err := tt.check(r, tx, context.Background())
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("check error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("check got = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if !got[i].Expected.Equal(tt.want[i].Expected) || !got[i].Actual.Equal(tt.want[i].Actual) {
+					t.Fatalf("check got = %v, want %v", got[i], tt.want[i])
+				}
+				got[i].Expected, got[i].Actual = tt.want[i].Expected, tt.want[i].Actual
+
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Fatalf("check got = %v, want %v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
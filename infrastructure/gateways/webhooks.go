@@ -0,0 +1,189 @@
+package gateways
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/xerrors"
+)
+
+type Webhooks struct {
+	listQuery       *listWebhooksQuery
+	createQuery     *createWebhookQuery
+	deactivateQuery *deactivateWebhookQuery
+	deliveriesQuery *webhookDeliveriesQuery
+}
+
+func NewWebhooks(d *sql.DB) (repositories.Webhooks, error) {
+	var err error
+	table := new(Webhooks)
+
+	table.listQuery, err = newListWebhooksQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation listWebhooksQuery: %w", err)
+	}
+
+	table.createQuery, err = newCreateWebhookQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation createWebhookQuery: %w", err)
+	}
+
+	table.deactivateQuery, err = newDeactivateWebhookQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation deactivateWebhookQuery: %w", err)
+	}
+
+	table.deliveriesQuery, err = newWebhookDeliveriesQuery(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error preparation webhookDeliveriesQuery: %w", err)
+	}
+
+	return table, nil
+}
+
+//Create stores webhook under the new random id, returned webhook keeps the secret
+func (wh *Webhooks) Create(ctx context.Context, webhook *entities.Webhook) (*entities.Webhook, error) {
+	created := models.NewWebhook(webhook)
+
+	row := wh.createQuery.QueryRowContext(ctx, created.Bind()...)
+	if err := row.Scan(created.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return created.ToDomain(), nil
+}
+
+func (wh *Webhooks) List(ctx context.Context) (entities.Webhooks, error) {
+	rows, err := wh.listQuery.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := entities.Webhooks{}
+	for rows.Next() {
+		webhook := &models.Webhook{}
+		if err := rows.Scan(webhook.BindScan()...); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook.ToDomain())
+	}
+
+	return webhooks, rows.Err()
+}
+
+//Deactivate stops deliveries to webhook, malformed id is reported as sql.ErrNoRows
+func (wh *Webhooks) Deactivate(ctx context.Context, id string) (*entities.Webhook, error) {
+	guid, err := uuid.FromString(id)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	webhook := &models.Webhook{}
+	if err := wh.deactivateQuery.QueryRowContext(ctx, guid.Bytes()).Scan(webhook.BindScan()...); err != nil {
+		return nil, err
+	}
+
+	return webhook.ToDomain(), nil
+}
+
+//Deliveries returns up to limit latest deliveries of webhook, empty status means any
+func (wh *Webhooks) Deliveries(ctx context.Context, id string, status entities.DeliveryStatus, limit int) (entities.Deliveries, error) {
+	deliveries := entities.Deliveries{}
+
+	guid, err := uuid.FromString(id)
+	if err != nil {
+		return deliveries, nil
+	}
+
+	var filter *string
+	if status != "" {
+		v := string(status)
+		filter = &v
+	}
+
+	rows, err := wh.deliveriesQuery.QueryContext(ctx, guid.Bytes(), filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d := &models.Delivery{}
+		if err := rows.Scan(d.BindScan()...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d.ToDomain())
+	}
+
+	return deliveries, rows.Err()
+}
+
+type listWebhooksQuery struct {
+	*sql.Stmt
+}
+
+func newListWebhooksQuery(d *sql.DB) (*listWebhooksQuery, error) {
+	stmt, err := d.Prepare(`SELECT s.guid, s.url, s.active, s.created_at FROM webhook_subscriptions s ORDER BY s.id`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listWebhooksQuery{stmt}, nil
+}
+
+type createWebhookQuery struct {
+	*sql.Stmt
+}
+
+func newCreateWebhookQuery(d *sql.DB) (*createWebhookQuery, error) {
+	stmt, err := d.Prepare(`INSERT INTO webhook_subscriptions (id, guid, url, secret, active, created_at, updated_at)
+		VALUES (DEFAULT, $1, $2, $3, true, DEFAULT, DEFAULT)
+		RETURNING guid, url, active, created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createWebhookQuery{stmt}, nil
+}
+
+type deactivateWebhookQuery struct {
+	*sql.Stmt
+}
+
+func newDeactivateWebhookQuery(d *sql.DB) (*deactivateWebhookQuery, error) {
+	stmt, err := d.Prepare(`UPDATE webhook_subscriptions SET active = false, updated_at = NOW()
+		WHERE guid = $1
+		RETURNING guid, url, active, created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deactivateWebhookQuery{stmt}, nil
+}
+
+type webhookDeliveriesQuery struct {
+	*sql.Stmt
+}
+
+func newWebhookDeliveriesQuery(d *sql.DB) (*webhookDeliveriesQuery, error) {
+	stmt, err := d.Prepare(`SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON d.event_id = e.id
+		JOIN webhook_subscriptions s ON d.subscription_id = s.id
+		WHERE s.guid = $1 AND ($2::varchar IS NULL OR d.status = $2::varchar)
+		ORDER BY d.id DESC
+		LIMIT $3`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookDeliveriesQuery{stmt}, nil
+}
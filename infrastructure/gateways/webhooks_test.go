@@ -0,0 +1,265 @@
+// +build !integration
+
+package gateways
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/xerrors"
+)
+
+func expectWebhooksPrepare(mock sqlmock.Sqlmock) {
+	mock.ExpectPrepare("SELECT .* FROM webhook_subscriptions s ORDER BY s.id")
+	mock.ExpectPrepare("INSERT INTO webhook_subscriptions (.*) VALUES (.*)")
+	mock.ExpectPrepare("UPDATE webhook_subscriptions SET active = false.*")
+	mock.ExpectPrepare("SELECT .* FROM webhook_deliveries d .*")
+}
+
+func TestNewWebhooks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	prepareError := xerrors.New("prepare_error")
+
+	queries := []string{
+		"SELECT .* FROM webhook_subscriptions s ORDER BY s.id",
+		"INSERT INTO webhook_subscriptions (.*) VALUES (.*)",
+		"UPDATE webhook_subscriptions SET active = false.*",
+		"SELECT .* FROM webhook_deliveries d .*",
+	}
+
+	for i := range queries {
+		for _, q := range queries[:i] {
+			mock.ExpectPrepare(q)
+		}
+		mock.ExpectPrepare(queries[i]).WillReturnError(prepareError)
+
+		if _, err := NewWebhooks(db); !xerrors.Is(err, prepareError) {
+			t.Fatalf("NewWebhooks() error = %v, wantErr %v", err, prepareError)
+		}
+	}
+
+	expectWebhooksPrepare(mock)
+	if _, err := NewWebhooks(db); err != nil {
+		t.Fatalf("NewWebhooks() error = %v", err)
+	}
+}
+
+func TestWebhooks_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+	secret := "0123456789abcdef"
+
+	expectWebhooksPrepare(mock)
+	mock.ExpectQuery("INSERT INTO webhook_subscriptions (.*) VALUES (.*)").
+		WithArgs(sqlmock.AnyArg(), "https://example.com/hooks", secret).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "url", "active", "created_at"}).
+			AddRow(uuid.FromStringOrNil("c5417ca1-c06b-4a45-9cd9-85936d4b9665").Bytes(), "https://example.com/hooks", true, createdAt))
+
+	wh, err := NewWebhooks(db)
+	if err != nil {
+		t.Fatalf("NewWebhooks error: %+v", err)
+	}
+
+	got, err := wh.Create(context.Background(), &entities.Webhook{URL: "https://example.com/hooks", Secret: secret})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	want := &entities.Webhook{Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", URL: "https://example.com/hooks", Secret: secret, Active: true, CreatedAt: createdAt}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Create() got = %v, want %v", got, want)
+	}
+}
+
+func TestWebhooks_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+
+	expectWebhooksPrepare(mock)
+	mock.ExpectQuery("SELECT .* FROM webhook_subscriptions s ORDER BY s.id").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "url", "active", "created_at"}).
+			AddRow(uuid.FromStringOrNil(testGuid).Bytes(), "https://example.com/hooks", false, createdAt))
+
+	wh, err := NewWebhooks(db)
+	if err != nil {
+		t.Fatalf("NewWebhooks error: %+v", err)
+	}
+
+	got, err := wh.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := entities.Webhooks{{Id: testGuid, URL: "https://example.com/hooks", CreatedAt: createdAt}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() got = %v, want %v", got, want)
+	}
+}
+
+func TestWebhooks_Deactivate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+
+	tests := []struct {
+		name    string
+		id      string
+		before  func()
+		want    *entities.Webhook
+		wantErr error
+	}{
+		{
+			name:    "malformed id",
+			id:      "unknown",
+			before:  func() {},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "not found",
+			id:   testGuid,
+			before: func() {
+				mock.ExpectQuery("UPDATE webhook_subscriptions SET active = false.*").
+					WithArgs(guidBytes).
+					WillReturnRows(sqlmock.NewRows([]string{"guid", "url", "active", "created_at"}))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "works fine",
+			id:   testGuid,
+			before: func() {
+				mock.ExpectQuery("UPDATE webhook_subscriptions SET active = false.*").
+					WithArgs(guidBytes).
+					WillReturnRows(sqlmock.NewRows([]string{"guid", "url", "active", "created_at"}).
+						AddRow(guidBytes, "https://example.com/hooks", false, createdAt))
+			},
+			want: &entities.Webhook{Id: testGuid, URL: "https://example.com/hooks", CreatedAt: createdAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectWebhooksPrepare(mock)
+			tt.before()
+
+			wh, err := NewWebhooks(db)
+			if err != nil {
+				t.Fatalf("NewWebhooks error: %+v", err)
+			}
+
+			got, err := wh.Deactivate(context.Background(), tt.id)
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Deactivate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Deactivate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhooks_Deliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testGuid := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	guidBytes := uuid.FromStringOrNil(testGuid).Bytes()
+	createdAt := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		id      string
+		status  entities.DeliveryStatus
+		before  func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name:   "malformed id",
+			id:     "unknown",
+			before: func() {},
+		},
+		{
+			name: "any status",
+			id:   testGuid,
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM webhook_deliveries d .*").
+					WithArgs(guidBytes, nil, 100).
+					WillReturnRows(sqlmock.NewRows(deliveryRowColumns).
+						AddRow(1, "delivered", 1, createdAt, nil, guidBytes, "payment.completed", []byte(`{}`), createdAt, guidBytes, "https://example.com/hooks", "0123456789abcdef").
+						AddRow(2, "dead", 10, createdAt, "timeout", guidBytes, "payment.completed", []byte(`{}`), createdAt, guidBytes, "https://example.com/hooks", "0123456789abcdef"))
+			},
+			want: 2,
+		},
+		{
+			name:   "dead only",
+			id:     testGuid,
+			status: entities.DeliveryDead,
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM webhook_deliveries d .*").
+					WithArgs(guidBytes, "dead", 100).
+					WillReturnRows(sqlmock.NewRows(deliveryRowColumns))
+			},
+		},
+		{
+			name: "query returns error",
+			id:   testGuid,
+			before: func() {
+				mock.ExpectQuery("SELECT .* FROM webhook_deliveries d .*").
+					WillReturnError(xerrors.New("query_error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectWebhooksPrepare(mock)
+			tt.before()
+
+			wh, err := NewWebhooks(db)
+			if err != nil {
+				t.Fatalf("NewWebhooks error: %+v", err)
+			}
+
+			got, err := wh.Deliveries(context.Background(), tt.id, tt.status, 100)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deliveries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && len(got) != tt.want {
+				t.Errorf("Deliveries() got = %v, want %d deliveries", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//RegisterDBStats exposes connection pool statistics read from stats on every scrape, cumulative ones are counters
+func RegisterDBStats(r *Registry, namespace string, stats func() sql.DBStats) error {
+	_, err := r.register(newDBStatsCollector(namespace, stats))
+	return err
+}
+
+type dbStatsCollector struct {
+	stats func() sql.DBStats
+
+	maxOpen     *prometheus.Desc
+	open        *prometheus.Desc
+	inUse       *prometheus.Desc
+	idle        *prometheus.Desc
+	waitCount   *prometheus.Desc
+	waitSeconds *prometheus.Desc
+	idleClosed  *prometheus.Desc
+	lifeClosed  *prometheus.Desc
+}
+
+func newDBStatsCollector(namespace string, stats func() sql.DBStats) *dbStatsCollector {
+	return &dbStatsCollector{
+		stats:       stats,
+		maxOpen:     prometheus.NewDesc(namespace+"_db_max_open_connections", "Maximum number of open connections to the database.", nil, nil),
+		open:        prometheus.NewDesc(namespace+"_db_open_connections", "The number of established connections both in use and idle.", nil, nil),
+		inUse:       prometheus.NewDesc(namespace+"_db_in_use_connections", "The number of connections currently in use.", nil, nil),
+		idle:        prometheus.NewDesc(namespace+"_db_idle_connections", "The number of idle connections.", nil, nil),
+		waitCount:   prometheus.NewDesc(namespace+"_db_wait_count_total", "The total number of connections waited for.", nil, nil),
+		waitSeconds: prometheus.NewDesc(namespace+"_db_wait_duration_seconds_total", "The total time blocked waiting for a new connection.", nil, nil),
+		idleClosed:  prometheus.NewDesc(namespace+"_db_max_idle_closed_total", "The total number of connections closed due to SetMaxIdleConns.", nil, nil),
+		lifeClosed:  prometheus.NewDesc(namespace+"_db_max_lifetime_closed_total", "The total number of connections closed due to SetConnMaxLifetime.", nil, nil),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.maxOpen, c.open, c.inUse, c.idle, c.waitCount, c.waitSeconds, c.idleClosed, c.lifeClosed} {
+		ch <- d
+	}
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(s.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(s.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(s.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitSeconds, prometheus.CounterValue, s.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.idleClosed, prometheus.CounterValue, float64(s.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.lifeClosed, prometheus.CounterValue, float64(s.MaxLifetimeClosed))
+}
@@ -0,0 +1,84 @@
+//Package prometheus registers go-kit metrics backed by Prometheus client in own registry of the service,
+//label values are given to With as name, value pairs
+package prometheus
+
+import (
+	"net/http"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/xerrors"
+)
+
+//DefBuckets are the default buckets of the Prometheus client, they fit request latencies in seconds
+var DefBuckets = prometheus.DefBuckets
+
+//Registry keeps metrics of the service apart from the global registry and serves them on scrape
+type Registry struct {
+	http.Handler
+	reg *prometheus.Registry
+}
+
+func NewRegistry() *Registry {
+	reg := prometheus.NewRegistry()
+	return &Registry{Handler: promhttp.HandlerFor(reg, promhttp.HandlerOpts{}), reg: reg}
+}
+
+//NewCounter registers counter, the same counter registered before is reused
+func (r *Registry) NewCounter(name, help string, labelNames ...string) (*kitprometheus.Counter, error) {
+	c, err := r.register(prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames))
+	if err != nil {
+		return nil, err
+	}
+
+	cv, ok := c.(*prometheus.CounterVec)
+	if !ok {
+		return nil, xerrors.Errorf("metric %s is already registered with another type", name)
+	}
+
+	return kitprometheus.NewCounter(cv), nil
+}
+
+//NewGauge registers gauge, the same gauge registered before is reused
+func (r *Registry) NewGauge(name, help string, labelNames ...string) (*kitprometheus.Gauge, error) {
+	c, err := r.register(prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labelNames))
+	if err != nil {
+		return nil, err
+	}
+
+	gv, ok := c.(*prometheus.GaugeVec)
+	if !ok {
+		return nil, xerrors.Errorf("metric %s is already registered with another type", name)
+	}
+
+	return kitprometheus.NewGauge(gv), nil
+}
+
+//NewHistogram registers histogram with upper bounds of buckets in increasing order, the same histogram registered before is reused
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) (*kitprometheus.Histogram, error) {
+	c, err := r.register(prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labelNames))
+	if err != nil {
+		return nil, err
+	}
+
+	hv, ok := c.(*prometheus.HistogramVec)
+	if !ok {
+		return nil, xerrors.Errorf("metric %s is already registered with another type", name)
+	}
+
+	return kitprometheus.NewHistogram(hv), nil
+}
+
+//register returns collector registered before when it has the same descriptors as c
+func (r *Registry) register(c prometheus.Collector) (prometheus.Collector, error) {
+	if err := r.reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector, nil
+		}
+
+		return nil, xerrors.Errorf("register metric error: %w", err)
+	}
+
+	return c, nil
+}
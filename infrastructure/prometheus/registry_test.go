@@ -0,0 +1,92 @@
+// +build !integration
+
+package prometheus
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+
+	c, err := r.NewCounter("test_total", "Test counter.", "method", "kind")
+	if err != nil {
+		t.Fatalf("NewCounter() error = %v", err)
+	}
+	c.With("method", "Send", "kind", "low_balance").Add(1)
+	c.With("method", "Send").With("kind", "low_balance").Add(2)
+
+	h, err := r.NewHistogram("test_seconds", "Test histogram.", []float64{0.1, 1}, "method")
+	if err != nil {
+		t.Fatalf("NewHistogram() error = %v", err)
+	}
+	h.With("method", "Send").Observe(0.05)
+	h.With("method", "Send").Observe(2)
+
+	if err := RegisterDBStats(r, "test", func() sql.DBStats {
+		return sql.DBStats{MaxOpenConnections: 1000, OpenConnections: 3, InUse: 2, Idle: 1, WaitCount: 4, WaitDuration: 1500 * time.Millisecond}
+	}); err != nil {
+		t.Fatalf("RegisterDBStats() error = %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	for _, line := range []string{
+		"# TYPE test_total counter\n",
+		"test_total{kind=\"low_balance\",method=\"Send\"} 3\n",
+		"test_seconds_bucket{method=\"Send\",le=\"0.1\"} 1\n",
+		"test_seconds_bucket{method=\"Send\",le=\"+Inf\"} 2\n",
+		"test_seconds_count{method=\"Send\"} 2\n",
+		"# TYPE test_db_open_connections gauge\n",
+		"test_db_max_open_connections 1000\n",
+		"test_db_open_connections 3\n",
+		"test_db_in_use_connections 2\n",
+		"test_db_idle_connections 1\n",
+		"# TYPE test_db_wait_count_total counter\n",
+		"test_db_wait_count_total 4\n",
+		"test_db_wait_duration_seconds_total 1.5\n",
+		"# TYPE test_db_max_idle_closed_total counter\n",
+		"test_db_max_idle_closed_total 0\n",
+		"test_db_max_lifetime_closed_total 0\n",
+	} {
+		if !bytes.Contains(rr.Body.Bytes(), []byte(line)) {
+			t.Errorf("ServeHTTP() body = %s, want line %q", rr.Body.String(), line)
+		}
+	}
+}
+
+func TestRegistry_register(t *testing.T) {
+	r := NewRegistry()
+
+	c, err := r.NewCounter("test_total", "Test counter.", "method")
+	if err != nil {
+		t.Fatalf("NewCounter() error = %v", err)
+	}
+	c.With("method", "Send").Add(1)
+
+	same, err := r.NewCounter("test_total", "Test counter.", "method")
+	if err != nil {
+		t.Fatalf("NewCounter() of registered counter error = %v", err)
+	}
+	same.With("method", "Send").Add(1)
+
+	if _, err := r.NewGauge("test_total", "Test counter.", "method"); err == nil {
+		t.Errorf("NewGauge() with name of counter didn't return error")
+	}
+
+	if _, err := r.NewCounter("test_total", "Another help.", "kind"); err == nil {
+		t.Errorf("NewCounter() with another labels didn't return error")
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	if line := "test_total{method=\"Send\"} 2\n"; !bytes.Contains(rr.Body.Bytes(), []byte(line)) {
+		t.Errorf("ServeHTTP() body = %s, want line %q", rr.Body.String(), line)
+	}
+}
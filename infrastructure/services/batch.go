@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/xerrors"
+)
+
+const maxBatchLegs = 1000
+
+//Batch makes all the transfers of legs in one transaction, either every leg is done or none of them.
+//Error of the failed leg is prefixed by its index
+func (w *WalletService) Batch(ctx context.Context, legs []*entities.TransferLeg) (b *entities.Batch, err error) {
+	if err = validateBatch(legs); err != nil {
+		return nil, err
+	}
+
+	err = w.retrySerializable(ctx, "batch", func() (err error) {
+		b, err = w.tryBatch(ctx, legs)
+		return
+	})
+
+	return
+}
+
+func validateBatch(legs []*entities.TransferLeg) error {
+	if len(legs) == 0 {
+		return models.ValidationError{xerrors.New("batch should have at least one leg")}
+	}
+
+	if len(legs) > maxBatchLegs {
+		return models.ValidationError{xerrors.Errorf("batch can't have more than %d legs", maxBatchLegs)}
+	}
+
+	for i, leg := range legs {
+		if !leg.Amount.IsPositive() {
+			return models.ValidationError{xerrors.Errorf("leg %d: amount should be positive", i)}
+		}
+
+		if leg.From == leg.To {
+			return models.ValidationError{xerrors.Errorf("leg %d: sender and receiver should be different", i)}
+		}
+
+		for _, id := range []entities.AccountId{leg.From, leg.To} {
+			if id.IsSystem() {
+				return models.AccountStateError{xerrors.Errorf("leg %d: %s: system account can't be used in payments", i, id)}
+			}
+		}
+	}
+
+	return nil
+}
+
+//batchLeg is a checked leg ready to be written
+type batchLeg struct {
+	credit, debit *entities.Account
+	amount        entities.Money
+	target        entities.Money
+	fx            *entities.Conversion
+}
+
+func (w *WalletService) tryBatch(ctx context.Context, legs []*entities.TransferLeg) (b *entities.Batch, err error) {
+	var (
+		accounts   = map[entities.AccountId]*entities.Account{}
+		names      = make([]string, 0, 2*len(legs))
+		available  = map[entities.AccountId]entities.Money{}
+		allowances = map[entities.AccountId]*entities.Allowance{}
+		checked    = make([]*batchLeg, 0, len(legs))
+		now        = time.Now()
+	)
+
+	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return b, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = xerrors.Errorf("error during commit: %w", err)
+			return
+		}
+		for _, bl := range checked {
+			w.observeTransfer(entities.Transfer, bl.credit.Currency, bl.amount.Amount)
+		}
+	}()
+
+	account := func(id entities.AccountId) (*entities.Account, error) {
+		if a, ok := accounts[id]; ok {
+			return a, nil
+		}
+
+		a, err := w.getActiveAccountTx(tx, ctx, string(id))
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[id], available[id] = a, a.Spendable(now)
+		return a, nil
+	}
+
+	seen := map[entities.AccountId]bool{}
+	for _, leg := range legs {
+		for _, id := range []entities.AccountId{leg.From, leg.To} {
+			if !seen[id] {
+				seen[id], names = true, append(names, string(id))
+			}
+		}
+	}
+
+	//all accounts of the batch are locked before any of them is read
+	if err = w.lockAccountsTx(tx, ctx, names...); err != nil {
+		return
+	}
+
+	//balances are tracked through the legs, so the account can spend what it receives earlier in the batch
+	for i, leg := range legs {
+		bl := &batchLeg{}
+
+		if bl.credit, err = account(leg.From); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+
+		if bl.amount, err = accountMoney(bl.credit, leg.Amount); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+
+		if err = w.authorizeAccount(ctx, bl.credit); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+
+		if bl.debit, err = account(leg.To); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+
+		var low bool
+		if low, err = available[leg.From].LessThan(bl.amount); err != nil || low {
+			if err == nil {
+				err = models.LowBalanceWrapper{xerrors.Errorf("leg %d: %s: don't have enough balance", i, leg.From)}
+			}
+			return
+		}
+
+		//limits are spent through the legs too, so the batch can't send more than single transfers could
+		allowance, ok := allowances[leg.From]
+		if !ok {
+			if allowance, err = w.allowanceTx(tx, ctx, bl.credit); err != nil {
+				err = xerrors.Errorf("leg %d: %w", i, err)
+				return
+			}
+			allowances[leg.From] = allowance
+		}
+
+		if err = spendAllowance(allowance, bl.amount); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+
+		if bl.fx, err = w.conversionTx(tx, ctx, "", bl.credit, bl.debit, bl.amount.Amount); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+
+		bl.target = bl.amount
+		if bl.fx != nil {
+			bl.target = entities.NewMoney(bl.fx.TargetAmount, bl.debit.Currency)
+		}
+
+		if available[leg.From], err = available[leg.From].Sub(bl.amount); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+		if available[leg.To], err = available[leg.To].Add(bl.target); err != nil {
+			err = xerrors.Errorf("leg %d: %w", i, err)
+			return
+		}
+		checked = append(checked, bl)
+	}
+
+	batch := &entities.Batch{Id: uuid.NewV4().String(), Ledgers: make(entities.Ledgers, 0, len(checked))}
+	for i, bl := range checked {
+		if err = w.Accounts.UpdateBalanceTx(tx, ctx, bl.credit, bl.amount.Neg()); err != nil {
+			err = xerrors.Errorf("leg %d: %s: error during decrease balance: %w", i, bl.credit.AccountId, err)
+			return
+		}
+
+		if err = w.Accounts.UpdateBalanceTx(tx, ctx, bl.debit, bl.target); err != nil {
+			err = xerrors.Errorf("leg %d: %s: error during increase balance: %w", i, bl.debit.AccountId, err)
+			return
+		}
+
+		var l *entities.Ledger
+		if l, err = w.Ledgers.AddBatchTx(tx, ctx, batch.Id, bl.credit, bl.debit, bl.amount, bl.fx); err != nil {
+			err = xerrors.Errorf("leg %d: error during add ledger: %w", i, err)
+			return
+		}
+
+		if _, err = w.Outbox.AddTx(tx, ctx, entities.PaymentCompleted, l); err != nil {
+			err = xerrors.Errorf("leg %d: error during add event: %w", i, err)
+			return
+		}
+		batch.Ledgers.Add(l)
+	}
+
+	b = batch
+	return
+}
@@ -0,0 +1,223 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestWalletService_Batch(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+
+	addError := xerrors.New("add_error")
+	addEventError := xerrors.New("add_event_error")
+
+	account := func(id entities.AccountId, balance string) *entities.Account {
+		return &entities.Account{AccountId: id, Balance: decimal.RequireFromString(balance), AvailableBalance: decimal.RequireFromString(balance),
+			Currency: "USD", Status: entities.AccountActive}
+	}
+
+	leg := func(from, to entities.AccountId, amount string) *entities.TransferLeg {
+		return &entities.TransferLeg{From: from, To: to, Amount: entities.NewMoney(decimal.RequireFromString(amount), "USD")}
+	}
+
+	ledger := func(from, to entities.AccountId, amount string) *entities.Ledger {
+		return &entities.Ledger{Payments: [2]*entities.Payment{
+			&entities.Payment{Account: from, Amount: entities.NewMoney(decimal.RequireFromString(amount).Neg(), "USD"), ToAccount: to, Direction: entities.Outgoing},
+			&entities.Payment{Account: to, Amount: entities.NewMoney(decimal.RequireFromString(amount), "USD"), FromAccount: from, Direction: entities.Incoming},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		legs    []*entities.TransferLeg
+		before  func([]*entities.TransferLeg)
+		want    entities.Ledgers
+		wantErr error
+	}{
+		{
+			name:    "empty batch",
+			before:  func([]*entities.TransferLeg) {},
+			wantErr: xerrors.New("batch should have at least one leg"),
+		},
+		{
+			name:    "leg amount isn't positive",
+			legs:    []*entities.TransferLeg{leg("alice123", "bob456", "1"), leg("alice123", "carl789", "0")},
+			before:  func([]*entities.TransferLeg) {},
+			wantErr: xerrors.New("leg 1: amount should be positive"),
+		},
+		{
+			name:    "leg sends to itself",
+			legs:    []*entities.TransferLeg{leg("alice123", "alice123", "1")},
+			before:  func([]*entities.TransferLeg) {},
+			wantErr: xerrors.New("leg 0: sender and receiver should be different"),
+		},
+		{
+			name:    "leg uses system account",
+			legs:    []*entities.TransferLeg{leg("alice123", "settlement:USD", "1")},
+			before:  func([]*entities.TransferLeg) {},
+			wantErr: xerrors.New("leg 0: settlement:USD: system account can't be used in payments"),
+		},
+		{
+			name: "receiver of leg not found",
+			legs: []*entities.TransferLeg{leg("alice123", "bob456", "1"), leg("alice123", "carl789", "1")},
+			before: func(legs []*entities.TransferLeg) {
+				ctx := context.Background()
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456"), entities.AccountId("carl789")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(account("alice123", "10"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("bob456")).Return(account("bob456", "0"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("carl789")).Return(nil, sql.ErrNoRows)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.New("leg 1: account carl789 not found"),
+		},
+		{
+			name: "leg amount is more precise than currency",
+			legs: []*entities.TransferLeg{leg("alice123", "bob456", "1.001")},
+			before: func(legs []*entities.TransferLeg) {
+				ctx := context.Background()
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(account("alice123", "10"), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.New("leg 0: amount 1.001 has more than 2 decimal places of USD"),
+		},
+		{
+			name: "legs exceed balance of sender",
+			legs: []*entities.TransferLeg{leg("alice123", "bob456", "6"), leg("alice123", "carl789", "5")},
+			before: func(legs []*entities.TransferLeg) {
+				ctx := context.Background()
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456"), entities.AccountId("carl789")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(account("alice123", "10"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("bob456")).Return(account("bob456", "0"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("carl789")).Return(account("carl789", "0"), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.New("leg 1: alice123: don't have enough balance"),
+		},
+		{
+			name: "add ledger returns error",
+			legs: []*entities.TransferLeg{leg("alice123", "bob456", "6")},
+			before: func(legs []*entities.TransferLeg) {
+				ctx := context.Background()
+				alice, bob := account("alice123", "10"), account("bob456", "0")
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(alice, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("bob456")).Return(bob, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), ctx, alice, legs[0].Amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), ctx, bob, legs[0].Amount).Return(nil)
+				mockLedgers.EXPECT().AddBatchTx(gomock.Any(), ctx, gomock.Any(), alice, bob, legs[0].Amount, nil).Return(nil, addError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: addError,
+		},
+		{
+			name: "add event returns error",
+			legs: []*entities.TransferLeg{leg("alice123", "bob456", "6")},
+			before: func(legs []*entities.TransferLeg) {
+				ctx := context.Background()
+				alice, bob := account("alice123", "10"), account("bob456", "0")
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(alice, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("bob456")).Return(bob, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), ctx, alice, legs[0].Amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), ctx, bob, legs[0].Amount).Return(nil)
+				mockLedgers.EXPECT().AddBatchTx(gomock.Any(), ctx, gomock.Any(), alice, bob, legs[0].Amount, nil).Return(ledger("alice123", "bob456", "6"), nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), ctx, entities.PaymentCompleted, ledger("alice123", "bob456", "6")).Return(nil, addEventError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: addEventError,
+		},
+		{
+			name: "receiver spends what it got earlier in the batch",
+			legs: []*entities.TransferLeg{leg("alice123", "bob456", "6"), leg("bob456", "carl789", "5"), leg("alice123", "carl789", "4")},
+			before: func(legs []*entities.TransferLeg) {
+				ctx := context.Background()
+				alice, bob, carl := account("alice123", "10"), account("bob456", "0"), account("carl789", "0")
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456"), entities.AccountId("carl789")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(alice, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("bob456")).Return(bob, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("carl789")).Return(carl, nil)
+
+				var batchId string
+				for _, l := range []struct {
+					credit, debit *entities.Account
+					amount        string
+				}{{alice, bob, "6"}, {bob, carl, "5"}, {alice, carl, "4"}} {
+					l := l
+					amount := entities.NewMoney(decimal.RequireFromString(l.amount), "USD")
+					mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), ctx, l.credit, amount.Neg()).Return(nil)
+					mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), ctx, l.debit, amount).Return(nil)
+					mockLedgers.EXPECT().AddBatchTx(gomock.Any(), ctx, gomock.Any(), l.credit, l.debit, amount, nil).
+						DoAndReturn(func(_ *sql.Tx, _ context.Context, id string, credit, debit *entities.Account, _ entities.Money, _ *entities.Conversion) (*entities.Ledger, error) {
+							if batchId != "" && batchId != id {
+								t.Fatalf("AddBatchTx() got batch %s, want %s", id, batchId)
+							}
+							batchId = id
+							return ledger(credit.AccountId, debit.AccountId, l.amount), nil
+						})
+					mockOutbox.EXPECT().AddTx(gomock.Any(), ctx, entities.PaymentCompleted, ledger(l.credit.AccountId, l.debit.AccountId, l.amount)).
+						Return(&entities.Event{}, nil)
+				}
+				dbmock.ExpectCommit()
+			},
+			want: entities.Ledgers{ledger("alice123", "bob456", "6"), ledger("bob456", "carl789", "5"), ledger("alice123", "carl789", "4")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Accounts: mockAccounts, Ledgers: mockLedgers, Outbox: mockOutbox}
+			tt.before(tt.legs)
+
+			got, err := w.Batch(context.Background(), tt.legs)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Batch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("Batch() got = %v, want nil", got)
+				}
+				return
+			}
+
+			if got.Id == "" {
+				t.Fatalf("Batch() got empty batch id")
+			}
+
+			if !reflect.DeepEqual(got.Ledgers, tt.want) {
+				t.Fatalf("Batch() got = %v, want %v", got.Ledgers, tt.want)
+			}
+		})
+	}
+}
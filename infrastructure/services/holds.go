@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"golang.org/x/xerrors"
+)
+
+//Authorize reserves amount of credit account for the payment to debit account,
+//reserved funds are released if hold isn't captured during holdTTL
+func (w *WalletService) Authorize(ctx context.Context, creditId, debitId string, amount entities.Money) (h *entities.Hold, err error) {
+	if !amount.IsPositive() {
+		return nil, models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	err = w.retrySerializable(ctx, "authorize", func() (err error) {
+		h, err = w.tryAuthorize(ctx, creditId, debitId, amount)
+		return
+	})
+
+	return
+}
+
+//Capture pays amount of hold to its receiver, zero amount captures the whole hold
+//and the rest of partially captured one is released
+func (w *WalletService) Capture(ctx context.Context, holdId string, amount entities.Money) (l *entities.Ledger, err error) {
+	if amount.IsNegative() {
+		return nil, models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	err = w.retrySerializable(ctx, "capture", func() (err error) {
+		l, err = w.tryCapture(ctx, holdId, amount)
+		return
+	})
+
+	return
+}
+
+//Void releases reserved funds of active hold
+func (w *WalletService) Void(ctx context.Context, holdId string) (h *entities.Hold, err error) {
+	err = w.retrySerializable(ctx, "void", func() (err error) {
+		h, err = w.tryVoid(ctx, holdId)
+		return
+	})
+
+	return
+}
+
+//GetHold returns the hold to the caller who may use either of its accounts
+func (w *WalletService) GetHold(ctx context.Context, holdId string) (*entities.Hold, error) {
+	hold, err := w.Holds.GetById(ctx, holdId)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFoundWrapper{xerrors.Errorf("hold %s not found", holdId)}
+		}
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	if err = w.authorizeAnyAccount(ctx, hold.Account, hold.ToAccount); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (w *WalletService) tryAuthorize(ctx context.Context, creditId, debitId string, amount entities.Money) (h *entities.Hold, err error) {
+	for _, id := range []string{creditId, debitId} {
+		if entities.AccountId(id).IsSystem() {
+			return h, models.AccountStateError{xerrors.Errorf("%s: system account can't be used in payments", id)}
+		}
+	}
+
+	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return h, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = xerrors.Errorf("error during commit: %w", err)
+		}
+	}()
+
+	credit, debit, amount, err := w.transferAccountsTx(tx, ctx, entities.Transfer, creditId, debitId, amount)
+	if err != nil {
+		return
+	}
+
+	//hold is captured with the rate of the capture moment, here it's only checked that conversion is possible
+	if _, err = w.conversionTx(tx, ctx, "", credit, debit, amount.Amount); err != nil {
+		return
+	}
+
+	if h, err = w.Holds.CreateTx(tx, ctx, credit, debit, amount, time.Now().Add(w.holdTTL)); err != nil {
+		err = xerrors.Errorf("error during add hold: %w", err)
+	}
+	return
+}
+
+func (w *WalletService) tryCapture(ctx context.Context, holdId string, amount entities.Money) (l *entities.Ledger, err error) {
+	var currency entities.Currency
+
+	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return l, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = xerrors.Errorf("error during commit: %w", err)
+			return
+		}
+		w.observeTransfer(entities.Transfer, currency, amount.Amount)
+	}()
+
+	hold, err := w.getActiveHoldTx(tx, ctx, holdId)
+	if err != nil {
+		return
+	}
+
+	//hold is captured by its receiver, the payer has agreed to the payment by the authorization of the hold
+	transferCtx := ctx
+	if w.authorization {
+		var receiver *entities.Account
+		if receiver, err = w.getAccountTx(tx, ctx, string(hold.ToAccount)); err != nil {
+			return
+		}
+		if err = w.authorizeAccount(ctx, receiver); err != nil {
+			return
+		}
+		transferCtx = context.WithValue(ctx, holdPayerKey{}, hold.Account)
+	}
+
+	if amount.IsZero() {
+		amount = hold.Amount
+	}
+
+	if amount, err = amount.In(hold.Amount.Currency); err != nil {
+		err = models.ValidationError{xerrors.Errorf("hold %s: %w", holdId, err)}
+		return
+	}
+
+	if hold.Amount.Amount.LessThan(amount.Amount) {
+		err = models.ValidationError{xerrors.Errorf("hold %s: can't capture more than %s", holdId, hold.Amount)}
+		return
+	}
+
+	//hold is finished first so its funds become available for the transfer
+	if err = w.Holds.CaptureTx(tx, ctx, hold, amount); err != nil {
+		err = xerrors.Errorf("hold %s: error during capture: %w", holdId, err)
+		return
+	}
+
+	l, currency, err = w.transferTx(tx, transferCtx, "", entities.Transfer, string(hold.Account), string(hold.ToAccount), amount)
+	return
+}
+
+func (w *WalletService) tryVoid(ctx context.Context, holdId string) (h *entities.Hold, err error) {
+	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return h, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = xerrors.Errorf("error during commit: %w", err)
+		}
+	}()
+
+	if h, err = w.getActiveHoldTx(tx, ctx, holdId); err != nil {
+		return
+	}
+
+	if w.authorization {
+		var account *entities.Account
+		if account, err = w.getAccountTx(tx, ctx, string(h.Account)); err != nil {
+			h = nil
+			return
+		}
+		if err = w.authorizeAccount(ctx, account); err != nil {
+			h = nil
+			return
+		}
+	}
+
+	if err = w.Holds.VoidTx(tx, ctx, h); err != nil {
+		h, err = nil, xerrors.Errorf("hold %s: error during void: %w", holdId, err)
+		return
+	}
+
+	h.Status = entities.HoldVoided
+	return
+}
+
+func (w *WalletService) getActiveHoldTx(tx *sql.Tx, ctx context.Context, holdId string) (*entities.Hold, error) {
+	hold, err := w.Holds.GetByIdTx(tx, ctx, holdId)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFoundWrapper{xerrors.Errorf("hold %s not found", holdId)}
+		}
+		return nil, xerrors.Errorf("get hold error: %w", err)
+	}
+
+	if !hold.IsActive() {
+		return nil, models.AccountStateError{xerrors.Errorf("hold %s is %s", holdId, hold.Status)}
+	}
+
+	return hold, nil
+}
@@ -0,0 +1,411 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestWalletService_Authorize(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockHolds := mock.NewMockHolds(ctrl)
+
+	lockError := xerrors.New("lock_error")
+	createError := xerrors.New("create_error")
+
+	testHold := &entities.Hold{Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", Account: "alice123", ToAccount: "bob456",
+		Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), Status: entities.HoldActive}
+
+	type args struct {
+		ctx      context.Context
+		creditId string
+		debitId  string
+		amount   entities.Money
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Hold
+		wantErr error
+	}{
+		{
+			name:    "amount isn't positive",
+			args:    args{ctx: context.Background(), creditId: "alice123", debitId: "bob456", amount: entities.NewMoney(decimal.New(0, 0), "USD")},
+			before:  func(a *args) {},
+			wantErr: xerrors.New("amount should be positive"),
+		},
+		{
+			name:    "system account can't be used",
+			args:    args{ctx: context.Background(), creditId: "settlement:USD", debitId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("%s: system account can't be used in payments", "settlement:USD"),
+		},
+		{
+			name: "funds are already held",
+			args: args{ctx: context.Background(), creditId: "alice123", debitId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(3.2), Currency: "USD", Status: entities.AccountActive}, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: don't have enough balance", "alice123"),
+		},
+		{
+			name: "lock returns error",
+			args: args{ctx: context.Background(), creditId: "alice123", debitId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(lockError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: lockError,
+		},
+		{
+			name: "create returns error",
+			args: args{ctx: context.Background(), creditId: "alice123", debitId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+				mockHolds.EXPECT().CreateTx(gomock.Any(), a.ctx, credit, debit, a.amount, gomock.Any()).Return(nil, createError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: createError,
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), creditId: "alice123", debitId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+				mockHolds.EXPECT().CreateTx(gomock.Any(), a.ctx, credit, debit, a.amount, gomock.Any()).
+					DoAndReturn(func(_ *sql.Tx, _ context.Context, _, _ *entities.Account, _ entities.Money, expiresAt time.Time) (*entities.Hold, error) {
+						if expiresAt.Before(time.Now().Add(defaultHoldTTL - time.Minute)) {
+							t.Fatalf("CreateTx() got unexpected expiration %v", expiresAt)
+						}
+						return testHold, nil
+					})
+				dbmock.ExpectCommit()
+			},
+			want: testHold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, holdTTL: defaultHoldTTL, Accounts: mockAccounts, Holds: mockHolds}
+			tt.before(&tt.args)
+
+			got, err := w.Authorize(tt.args.ctx, tt.args.creditId, tt.args.debitId, tt.args.amount)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Authorize() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_Capture(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+	mockHolds := mock.NewMockHolds(ctrl)
+
+	captureError := xerrors.New("capture_error")
+
+	testHoldId := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	testLedger := &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: entities.NewMoney(decimal.NewFromFloat(2), "USD"), ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(2), "USD"), FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
+	activeHold := func() *entities.Hold {
+		return &entities.Hold{Id: testHoldId, Account: "alice123", ToAccount: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), Status: entities.HoldActive}
+	}
+
+	type args struct {
+		ctx    context.Context
+		holdId string
+		amount entities.Money
+	}
+	tests := []struct {
+		name          string
+		args          args
+		authorization bool
+		before        func(*args)
+		want          *entities.Ledger
+		wantErr       error
+	}{
+		{
+			name:    "negative amount",
+			args:    args{ctx: context.Background(), holdId: testHoldId, amount: entities.NewMoney(decimal.NewFromFloat(-1), "USD")},
+			before:  func(a *args) {},
+			wantErr: xerrors.New("amount should be positive"),
+		},
+		{
+			name: "hold not found",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(nil, sql.ErrNoRows)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("hold %s not found", testHoldId),
+		},
+		{
+			name: "hold is expired",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := activeHold()
+				hold.Status = entities.HoldExpired
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("hold %s is %s", testHoldId, entities.HoldExpired),
+		},
+		{
+			name: "amount exceeds hold",
+			args: args{ctx: context.Background(), holdId: testHoldId, amount: entities.NewMoney(decimal.NewFromFloat(5), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(activeHold(), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("hold %s: can't capture more than %s", testHoldId, "3.21 USD"),
+		},
+		{
+			name: "capture returns error",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := activeHold()
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockHolds.EXPECT().CaptureTx(gomock.Any(), a.ctx, hold, hold.Amount).Return(captureError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: captureError,
+		},
+		{
+			name: "captures part of hold",
+			args: args{ctx: context.Background(), holdId: testHoldId, amount: entities.NewMoney(decimal.NewFromFloat(2), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := activeHold()
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockHolds.EXPECT().CaptureTx(gomock.Any(), a.ctx, hold, a.amount).Return(nil)
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, hold.Account, hold.ToAccount).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, hold.Account).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, hold.ToAccount).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(testLedger, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name: "captures whole hold",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := activeHold()
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockHolds.EXPECT().CaptureTx(gomock.Any(), a.ctx, hold, hold.Amount).Return(nil)
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, hold.Account, hold.ToAccount).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, hold.Account).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, hold.ToAccount).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, hold.Amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, hold.Amount).Return(nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, hold.Amount, entities.Transfer, nil).Return(testLedger, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name:          "payer can't capture",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "alice"}), holdId: testHoldId},
+			authorization: true,
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := activeHold()
+				receiver := &entities.Account{AccountId: "bob456", Owner: "merchant", Currency: "USD", Status: entities.AccountActive}
+
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, hold.ToAccount).Return(receiver, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: access denied for %s", "bob456", "alice"),
+		},
+		{
+			name:          "merchant captures hold",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "merchant"}), holdId: testHoldId},
+			authorization: true,
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := activeHold()
+				credit := &entities.Account{AccountId: "alice123", Owner: "alice", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Owner: "merchant", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, hold.ToAccount).Return(debit, nil)
+				mockHolds.EXPECT().CaptureTx(gomock.Any(), a.ctx, hold, hold.Amount).Return(nil)
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), gomock.Any(), hold.Account, hold.ToAccount).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), gomock.Any(), hold.Account).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), gomock.Any(), hold.ToAccount).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), gomock.Any(), credit, hold.Amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), gomock.Any(), debit, hold.Amount).Return(nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), gomock.Any(), credit, debit, hold.Amount, entities.Transfer, nil).Return(testLedger, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), gomock.Any(), entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Accounts: mockAccounts, Ledgers: mockLedgers, Holds: mockHolds, Outbox: mockOutbox, authorization: tt.authorization}
+			tt.before(&tt.args)
+
+			got, err := w.Capture(tt.args.ctx, tt.args.holdId, tt.args.amount)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Capture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Capture() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_Void(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHolds := mock.NewMockHolds(ctrl)
+
+	voidError := xerrors.New("void_error")
+	testHoldId := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+
+	type args struct {
+		ctx    context.Context
+		holdId string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Hold
+		wantErr error
+	}{
+		{
+			name: "hold is captured",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).
+					Return(&entities.Hold{Id: testHoldId, Status: entities.HoldCaptured}, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("hold %s is %s", testHoldId, entities.HoldCaptured),
+		},
+		{
+			name: "void returns error",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := &entities.Hold{Id: testHoldId, Status: entities.HoldActive}
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockHolds.EXPECT().VoidTx(gomock.Any(), a.ctx, hold).Return(voidError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: voidError,
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), holdId: testHoldId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				hold := &entities.Hold{Id: testHoldId, Status: entities.HoldActive}
+				mockHolds.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.holdId).Return(hold, nil)
+				mockHolds.EXPECT().VoidTx(gomock.Any(), a.ctx, hold).Return(nil)
+				dbmock.ExpectCommit()
+			},
+			want: &entities.Hold{Id: testHoldId, Status: entities.HoldVoided},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Holds: mockHolds}
+			tt.before(&tt.args)
+
+			got, err := w.Void(tt.args.ctx, tt.args.holdId)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Void() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Void() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
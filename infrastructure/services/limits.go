@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"golang.org/x/xerrors"
+)
+
+//Allowance returns what is left of the account's limits at the moment
+func (w *WalletService) Allowance(ctx context.Context, accountId string) (*entities.Allowance, error) {
+	account, err := w.GetAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Limits == nil {
+		return entities.NewAllowance(account, &entities.Limits{}, &entities.LimitsUsage{}), nil
+	}
+
+	limits, err := w.Limits.Get(ctx, account)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	usage, err := w.Limits.Usage(ctx, account, time.Now())
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return entities.NewAllowance(account, limits, usage), nil
+}
+
+//allowanceTx reads limits and usage of the account in the payment transaction, nil allowance means no limits
+func (w *WalletService) allowanceTx(tx *sql.Tx, ctx context.Context, account *entities.Account) (*entities.Allowance, error) {
+	if w.Limits == nil {
+		return nil, nil
+	}
+
+	limits, err := w.Limits.GetTx(tx, ctx, account)
+	if err != nil {
+		return nil, xerrors.Errorf("get limits error: %w", err)
+	}
+
+	if *limits == (entities.Limits{}) {
+		return nil, nil
+	}
+
+	usage, err := w.Limits.UsageTx(tx, ctx, account, time.Now())
+	if err != nil {
+		return nil, xerrors.Errorf("get limits usage error: %w", err)
+	}
+
+	return entities.NewAllowance(account, limits, usage), nil
+}
+
+//checkLimitsTx fails when one more payment of amount from the account exceeds any of its limits
+func (w *WalletService) checkLimitsTx(tx *sql.Tx, ctx context.Context, account *entities.Account, amount entities.Money) error {
+	allowance, err := w.allowanceTx(tx, ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return spendAllowance(allowance, amount)
+}
+
+//spendAllowance checks payment of amount against allowance and takes it from the remainders
+func spendAllowance(allowance *entities.Allowance, amount entities.Money) error {
+	if allowance == nil {
+		return nil
+	}
+
+	if err := allowance.Check(amount); err != nil {
+		return models.LimitExceededError{xerrors.Errorf("%s: %w", allowance.AccountId, err)}
+	}
+
+	allowance.Spend(amount)
+	return nil
+}
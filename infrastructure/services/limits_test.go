@@ -0,0 +1,246 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func amountOf(v string) *decimal.Decimal {
+	d := decimal.RequireFromString(v)
+	return &d
+}
+
+func countOf(v int64) *int64 {
+	return &v
+}
+
+func TestWalletService_Allowance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLimits := mock.NewMockLimits(ctrl)
+	testError := xerrors.New("test_error")
+	testAccount := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(100), Currency: "USD", Status: entities.AccountActive}
+
+	tests := []struct {
+		name    string
+		before  func(ctx context.Context)
+		want    *entities.Allowance
+		wantErr error
+	}{
+		{
+			name: "account not found",
+			before: func(ctx context.Context) {
+				mockAccounts.EXPECT().GetByName(ctx, entities.AccountId("alice123")).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: xerrors.New("account alice123 not found"),
+		},
+		{
+			name: "get limits error",
+			before: func(ctx context.Context) {
+				mockAccounts.EXPECT().GetByName(ctx, entities.AccountId("alice123")).Return(testAccount, nil)
+				mockLimits.EXPECT().Get(ctx, testAccount).Return(nil, testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "usage error",
+			before: func(ctx context.Context) {
+				mockAccounts.EXPECT().GetByName(ctx, entities.AccountId("alice123")).Return(testAccount, nil)
+				mockLimits.EXPECT().Get(ctx, testAccount).Return(&entities.Limits{}, nil)
+				mockLimits.EXPECT().Usage(ctx, testAccount, gomock.Any()).Return(nil, testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "remainders never go below zero",
+			before: func(ctx context.Context) {
+				mockAccounts.EXPECT().GetByName(ctx, entities.AccountId("alice123")).Return(testAccount, nil)
+				mockLimits.EXPECT().Get(ctx, testAccount).
+					Return(&entities.Limits{MaxAmount: amountOf("50"), DailyAmount: amountOf("100"), MonthlyAmount: amountOf("1000"), HourlyCount: countOf(3)}, nil)
+				mockLimits.EXPECT().Usage(ctx, testAccount, gomock.Any()).
+					Return(&entities.LimitsUsage{DailyAmount: decimal.RequireFromString("120"), MonthlyAmount: decimal.RequireFromString("400"), HourlyCount: 1}, nil)
+			},
+			want: &entities.Allowance{
+				AccountId:        "alice123",
+				Currency:         "USD",
+				Limits:           entities.Limits{MaxAmount: amountOf("50"), DailyAmount: amountOf("100"), MonthlyAmount: amountOf("1000"), HourlyCount: countOf(3)},
+				DailyRemaining:   &decimal.Zero,
+				MonthlyRemaining: amountOf("600"),
+				HourlyRemaining:  countOf(2),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			w := &WalletService{Accounts: mockAccounts, Limits: mockLimits}
+			tt.before(ctx)
+
+			got, err := w.Allowance(ctx, "alice123")
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Allowance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Allowance() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_checkLimitsTx(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLimits := mock.NewMockLimits(ctrl)
+	testAccount := &entities.Account{AccountId: "alice123", Currency: "USD", Status: entities.AccountActive}
+	testUsage := &entities.LimitsUsage{DailyAmount: decimal.RequireFromString("80"), MonthlyAmount: decimal.RequireFromString("950"), HourlyCount: 2}
+
+	tests := []struct {
+		name    string
+		amount  string
+		before  func()
+		wantErr error
+	}{
+		{
+			name:   "no limits",
+			amount: "1000000",
+			before: func() {
+				mockLimits.EXPECT().GetTx(gomock.Any(), gomock.Any(), testAccount).Return(&entities.Limits{}, nil)
+			},
+		},
+		{
+			name:   "single payment limit",
+			amount: "60",
+			before: func() {
+				mockLimits.EXPECT().GetTx(gomock.Any(), gomock.Any(), testAccount).Return(&entities.Limits{MaxAmount: amountOf("50")}, nil)
+				mockLimits.EXPECT().UsageTx(gomock.Any(), gomock.Any(), testAccount, gomock.Any()).Return(testUsage, nil)
+			},
+			wantErr: models.LimitExceededError{xerrors.New("alice123: amount 60 exceeds single payment limit 50")},
+		},
+		{
+			name:   "daily limit",
+			amount: "30",
+			before: func() {
+				mockLimits.EXPECT().GetTx(gomock.Any(), gomock.Any(), testAccount).Return(&entities.Limits{DailyAmount: amountOf("100")}, nil)
+				mockLimits.EXPECT().UsageTx(gomock.Any(), gomock.Any(), testAccount, gomock.Any()).Return(testUsage, nil)
+			},
+			wantErr: models.LimitExceededError{xerrors.New("alice123: amount 30 exceeds daily limit, 20 is left")},
+		},
+		{
+			name:   "monthly limit",
+			amount: "60",
+			before: func() {
+				mockLimits.EXPECT().GetTx(gomock.Any(), gomock.Any(), testAccount).Return(&entities.Limits{MonthlyAmount: amountOf("1000")}, nil)
+				mockLimits.EXPECT().UsageTx(gomock.Any(), gomock.Any(), testAccount, gomock.Any()).Return(testUsage, nil)
+			},
+			wantErr: models.LimitExceededError{xerrors.New("alice123: amount 60 exceeds monthly limit, 50 is left")},
+		},
+		{
+			name:   "hourly transfers",
+			amount: "1",
+			before: func() {
+				mockLimits.EXPECT().GetTx(gomock.Any(), gomock.Any(), testAccount).Return(&entities.Limits{HourlyCount: countOf(2)}, nil)
+				mockLimits.EXPECT().UsageTx(gomock.Any(), gomock.Any(), testAccount, gomock.Any()).Return(testUsage, nil)
+			},
+			wantErr: models.LimitExceededError{xerrors.New("alice123: hourly limit of 2 transfers is reached")},
+		},
+		{
+			name:   "within limits",
+			amount: "20",
+			before: func() {
+				mockLimits.EXPECT().GetTx(gomock.Any(), gomock.Any(), testAccount).
+					Return(&entities.Limits{MaxAmount: amountOf("50"), DailyAmount: amountOf("100"), MonthlyAmount: amountOf("1000"), HourlyCount: countOf(3)}, nil)
+				mockLimits.EXPECT().UsageTx(gomock.Any(), gomock.Any(), testAccount, gomock.Any()).Return(testUsage, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Limits: mockLimits}
+			tt.before()
+
+			dbmock.ExpectBegin()
+			dbmock.ExpectRollback()
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("Begin() error = %v", err)
+			}
+			defer tx.Rollback()
+
+			err = w.checkLimitsTx(tx, context.Background(), testAccount, entities.NewMoney(decimal.RequireFromString(tt.amount), "USD"))
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("checkLimitsTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !xerrors.As(err, &models.LimitExceededError{}) {
+				t.Fatalf("checkLimitsTx() error = %T, want LimitExceededError", err)
+			}
+		})
+	}
+}
+
+func TestWalletService_BatchLimits(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLimits := mock.NewMockLimits(ctrl)
+
+	account := func(id entities.AccountId) *entities.Account {
+		return &entities.Account{AccountId: id, Balance: decimal.New(100, 0), AvailableBalance: decimal.New(100, 0), Currency: "USD", Status: entities.AccountActive}
+	}
+	alice, bob, carl := account("alice123"), account("bob456"), account("carl789")
+
+	ctx := context.Background()
+	dbmock.ExpectBegin()
+	mockAccounts.EXPECT().LockTx(gomock.Any(), ctx, entities.AccountId("alice123"), entities.AccountId("bob456"), entities.AccountId("carl789")).Return(nil)
+	mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("alice123")).Return(alice, nil)
+	mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("bob456")).Return(bob, nil)
+	mockAccounts.EXPECT().GetByNameTx(gomock.Any(), ctx, entities.AccountId("carl789")).Return(carl, nil)
+	mockLimits.EXPECT().GetTx(gomock.Any(), ctx, alice).Return(&entities.Limits{DailyAmount: amountOf("10")}, nil)
+	mockLimits.EXPECT().UsageTx(gomock.Any(), ctx, alice, gomock.Any()).Return(&entities.LimitsUsage{}, nil)
+	dbmock.ExpectRollback()
+
+	w := &WalletService{db: db, Accounts: mockAccounts, Limits: mockLimits}
+	legs := []*entities.TransferLeg{
+		{From: "alice123", To: "bob456", Amount: entities.NewMoney(decimal.New(6, 0), "USD")},
+		{From: "alice123", To: "carl789", Amount: entities.NewMoney(decimal.New(6, 0), "USD")},
+	}
+
+	_, err = w.Batch(ctx, legs)
+	wantErr := xerrors.New("leg 1: alice123: amount 6 exceeds daily limit, 4 is left")
+	if err == nil || err.Error() != wantErr.Error() {
+		t.Fatalf("Batch() error = %v, wantErr %v", err, wantErr)
+	}
+
+	if !xerrors.As(err, &models.LimitExceededError{}) {
+		t.Fatalf("Batch() error = %T, want LimitExceededError", err)
+	}
+}
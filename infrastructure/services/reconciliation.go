@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/NickRI/wallets-task/infrastructure/gateways"
+	"golang.org/x/xerrors"
+)
+
+//ReconciliationService checks that balances agree with payments and keeps the latest report
+type ReconciliationService struct {
+	db             *sql.DB
+	Reconciliation repositories.Reconciliation
+
+	mu   sync.RWMutex
+	last *entities.ReconciliationReport
+}
+
+func NewReconciliationService(d *sql.DB) (services.Reconciler, error) {
+	ReconciliationTable, err := gateways.NewReconciliation(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in reconciliation queries: %w", err)
+	}
+
+	return &ReconciliationService{db: d, Reconciliation: ReconciliationTable}, nil
+}
+
+//Reconcile runs all the checks and remembers the report as the latest one
+func (r *ReconciliationService) Reconcile(ctx context.Context) (*entities.ReconciliationReport, error) {
+	report, err := r.tryReconcile(ctx)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	r.mu.Lock()
+	r.last = report
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+//LastReport returns report of the latest Reconcile run
+func (r *ReconciliationService) LastReport(ctx context.Context) (*entities.ReconciliationReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.last == nil {
+		return nil, models.NotFoundWrapper{xerrors.New("reconciliation has not run yet")}
+	}
+
+	return r.last, nil
+}
+
+//tryReconcile reads in one repeatable read snapshot, so payments made meanwhile don't show up as discrepancies
+func (r *ReconciliationService) tryReconcile(ctx context.Context) (report *entities.ReconciliationReport, err error) {
+	report = &entities.ReconciliationReport{StartedAt: time.Now(), Discrepancies: entities.Discrepancies{}}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			report, err = nil, xerrors.Errorf("error during commit: %w", err)
+		}
+	}()
+
+	checks := []struct {
+		kind  entities.DiscrepancyKind
+		check func(*sql.Tx, context.Context) (entities.Discrepancies, error)
+	}{
+		{entities.BalanceMismatch, r.Reconciliation.BalanceMismatchesTx},
+		{entities.UnbalancedLedger, r.Reconciliation.UnbalancedLedgersTx},
+		{entities.CurrencyImbalance, r.Reconciliation.CurrencyImbalancesTx},
+	}
+
+	for _, c := range checks {
+		found, err := c.check(tx, ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("%s check error: %w", c.kind, err)
+		}
+		report.Discrepancies = append(report.Discrepancies, found...)
+	}
+
+	report.FinishedAt = time.Now()
+	report.Consistent = len(report.Discrepancies) == 0
+
+	return report, nil
+}
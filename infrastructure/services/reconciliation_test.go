@@ -0,0 +1,145 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestNewReconciliationService(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	prepareError := xerrors.New("prepare_error")
+
+	dbmock.ExpectPrepare("SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*").
+		WillReturnError(prepareError)
+
+	if _, err := NewReconciliationService(db); !xerrors.Is(err, prepareError) {
+		t.Fatalf("NewReconciliationService() error = %v, wantErr %v", err, prepareError)
+	}
+
+	dbmock.ExpectPrepare("SELECT .* FROM accounts a LEFT JOIN payments p .* HAVING .*")
+	dbmock.ExpectPrepare("SELECT .* FROM payments p GROUP BY p.guid .*")
+	dbmock.ExpectPrepare("SELECT .* FROM accounts a GROUP BY a.currency.*")
+
+	if _, err := NewReconciliationService(db); err != nil {
+		t.Fatalf("NewReconciliationService() error = %v", err)
+	}
+}
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReconciliation := mock.NewMockReconciliation(ctrl)
+
+	beginError := xerrors.New("begin_error")
+	checkError := xerrors.New("check_error")
+
+	mismatch := &entities.Discrepancy{Kind: entities.BalanceMismatch, Account: "bob456", Currency: "USD",
+		Expected: decimal.NewFromFloat(995.5), Actual: decimal.NewFromFloat(996.5)}
+	unbalanced := &entities.Discrepancy{Kind: entities.UnbalancedLedger, Ledger: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", Legs: 1,
+		Expected: decimal.Zero, Actual: decimal.NewFromFloat(-1)}
+
+	tests := []struct {
+		name    string
+		before  func()
+		want    entities.Discrepancies
+		wantErr error
+	}{
+		{
+			name: "begin returns error",
+			before: func() {
+				dbmock.ExpectBegin().WillReturnError(beginError)
+			},
+			wantErr: beginError,
+		},
+		{
+			name: "check returns error",
+			before: func() {
+				dbmock.ExpectBegin()
+				mockReconciliation.EXPECT().BalanceMismatchesTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{}, nil)
+				mockReconciliation.EXPECT().UnbalancedLedgersTx(gomock.Any(), gomock.Any()).Return(nil, checkError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: checkError,
+		},
+		{
+			name: "consistent",
+			before: func() {
+				dbmock.ExpectBegin()
+				mockReconciliation.EXPECT().BalanceMismatchesTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{}, nil)
+				mockReconciliation.EXPECT().UnbalancedLedgersTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{}, nil)
+				mockReconciliation.EXPECT().CurrencyImbalancesTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: entities.Discrepancies{},
+		},
+		{
+			name: "discrepancies found",
+			before: func() {
+				dbmock.ExpectBegin()
+				mockReconciliation.EXPECT().BalanceMismatchesTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{mismatch}, nil)
+				mockReconciliation.EXPECT().UnbalancedLedgersTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{unbalanced}, nil)
+				mockReconciliation.EXPECT().CurrencyImbalancesTx(gomock.Any(), gomock.Any()).Return(entities.Discrepancies{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: entities.Discrepancies{mismatch, unbalanced},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReconciliationService{db: db, Reconciliation: mockReconciliation}
+			tt.before()
+
+			got, err := r.Reconcile(context.Background())
+			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			last, lastErr := r.LastReport(context.Background())
+
+			if tt.wantErr != nil {
+				if got != nil || last != nil || lastErr == nil {
+					t.Fatalf("Reconcile() got = %v, last = %v, want nil", got, last)
+				}
+				return
+			}
+
+			if got.Consistent != (len(tt.want) == 0) || got.FinishedAt.Before(got.StartedAt) {
+				t.Fatalf("Reconcile() got = %+v", got)
+			}
+
+			if !reflect.DeepEqual(got.Discrepancies, tt.want) {
+				t.Fatalf("Reconcile() got = %v, want %v", got.Discrepancies, tt.want)
+			}
+
+			if last != got {
+				t.Fatalf("LastReport() got = %v, want %v", last, got)
+			}
+
+			if err := dbmock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
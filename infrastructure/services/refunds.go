@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"golang.org/x/xerrors"
+)
+
+//Refund returns amount of the transfer back to its sender as a new ledger linked to the original one.
+//Amount without currency is in sender's currency, zero amount refunds everything that isn't refunded yet
+func (w *WalletService) Refund(ctx context.Context, ledgerId string, amount entities.Money) (l *entities.Ledger, err error) {
+	if amount.IsNegative() {
+		return nil, models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	err = w.retrySerializable(ctx, "refund", func() (err error) {
+		l, err = w.tryRefund(ctx, ledgerId, amount)
+		return
+	})
+
+	return
+}
+
+func (w *WalletService) tryRefund(ctx context.Context, ledgerId string, amount entities.Money) (l *entities.Ledger, err error) {
+	var (
+		original      *entities.Ledger
+		refunded      entities.Money
+		source        entities.Money
+		credit, debit *entities.Account
+	)
+
+	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return l, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = xerrors.Errorf("error during commit: %w", err)
+			return
+		}
+		w.observeTransfer(entities.Refund, credit.Currency, source.Amount)
+	}()
+
+	original, err = w.Ledgers.GetByGuidTx(tx, ctx, ledgerId)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			err = models.NotFoundWrapper{xerrors.Errorf("ledger %s not found", ledgerId)}
+			return
+		}
+		err = xerrors.Errorf("get ledger error: %w", err)
+		return
+	}
+
+	sent, received := original.Outgoing(), original.Incoming()
+	if sent.Type != entities.Transfer {
+		err = models.ValidationError{xerrors.Errorf("ledger %s: only transfers can be refunded", ledgerId)}
+		return
+	}
+
+	//both sides are locked before refunded amount and balances are read, so concurrent refunds of the ledger queue
+	if err = w.lockAccountsTx(tx, ctx, string(received.Account), string(sent.Account)); err != nil {
+		return
+	}
+
+	if refunded, err = w.Ledgers.RefundedTx(tx, ctx, sent); err != nil {
+		err = xerrors.Errorf("get refunded amount error: %w", err)
+		return
+	}
+
+	remaining, err := sent.Amount.Neg().Sub(refunded)
+	if err != nil {
+		err = xerrors.Errorf("ledger %s: %w", ledgerId, err)
+		return
+	}
+
+	if amount.IsZero() {
+		amount = remaining
+	}
+
+	if amount, err = amount.In(remaining.Currency); err != nil {
+		err = models.ValidationError{xerrors.Errorf("ledger %s: %w", ledgerId, err)}
+		return
+	}
+
+	switch {
+	case !remaining.IsPositive():
+		err = models.AccountStateError{xerrors.Errorf("ledger %s is already refunded", ledgerId)}
+		return
+	case remaining.Amount.LessThan(amount.Amount):
+		err = models.ValidationError{xerrors.Errorf("ledger %s: can't refund more than %s", ledgerId, remaining)}
+		return
+	}
+
+	//receiver returns amount converted with the rate of the original transfer, rounded half away from zero to minor unit
+	source, fx := amount, (*entities.Conversion)(nil)
+	if sent.FX != nil {
+		currency := received.Amount.Currency
+		source = entities.NewMoney(amount.Amount.Mul(sent.FX.Rate).Round(currency.Exponent()), currency)
+		if !source.IsPositive() {
+			err = models.ValidationError{xerrors.Errorf("ledger %s: %s is too small to refund in %s", ledgerId, amount, currency)}
+			return
+		}
+		fx = &entities.Conversion{Rate: amount.Amount.Div(source.Amount), SourceAmount: source.Amount, TargetAmount: amount.Amount}
+	}
+
+	if credit, err = w.getActiveAccountTx(tx, ctx, string(received.Account)); err != nil {
+		return
+	}
+
+	if err = w.authorizeAccount(ctx, credit); err != nil {
+		return
+	}
+
+	low, err := credit.Spendable(time.Now()).LessThan(source)
+	if err != nil || low {
+		if err == nil {
+			err = models.LowBalanceWrapper{xerrors.Errorf("%s: don't have enough balance", credit.AccountId)}
+		}
+		return
+	}
+
+	if debit, err = w.getActiveAccountTx(tx, ctx, string(sent.Account)); err != nil {
+		return
+	}
+
+	if err = w.Accounts.UpdateBalanceTx(tx, ctx, credit, source.Neg()); err != nil {
+		err = xerrors.Errorf("%s: error during decrease balance: %w", credit.AccountId, err)
+		return
+	}
+
+	if err = w.Accounts.UpdateBalanceTx(tx, ctx, debit, amount); err != nil {
+		err = xerrors.Errorf("%s: error during increase balance: %w", debit.AccountId, err)
+		return
+	}
+
+	l, err = w.Ledgers.AddRefundTx(tx, ctx, ledgerId, credit, debit, source, fx)
+	if err != nil {
+		err = xerrors.Errorf("error during add ledger: %w", err)
+		return
+	}
+
+	if _, err = w.Outbox.AddTx(tx, ctx, entities.PaymentCompleted, l); err != nil {
+		l, err = nil, xerrors.Errorf("error during add event: %w", err)
+	}
+	return
+}
@@ -0,0 +1,276 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func TestWalletService_Refund(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+
+	addError := xerrors.New("add_error")
+	addEventError := xerrors.New("add_event_error")
+
+	testLedgerId := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+
+	original := func(pt entities.PaymentType, fx *entities.Conversion) *entities.Ledger {
+		target := entities.NewMoney(decimal.RequireFromString("5"), "USD")
+		if fx != nil {
+			target = entities.NewMoney(fx.TargetAmount, "EUR")
+		}
+
+		return &entities.Ledger{Payments: [2]*entities.Payment{
+			&entities.Payment{Ledger: testLedgerId, Account: "alice123", Amount: entities.NewMoney(decimal.RequireFromString("-5"), "USD"), ToAccount: "bob456",
+				Direction: entities.Outgoing, Type: pt, FX: fx},
+			&entities.Payment{Ledger: testLedgerId, Account: "bob456", Amount: target, FromAccount: "alice123",
+				Direction: entities.Incoming, Type: pt, FX: fx},
+		}}
+	}
+
+	testRefund := &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.RequireFromString("-2"), "USD"), ToAccount: "alice123", Direction: entities.Outgoing, Type: entities.Refund, RefundOf: testLedgerId},
+		&entities.Payment{Account: "alice123", Amount: entities.NewMoney(decimal.RequireFromString("2"), "USD"), FromAccount: "bob456", Direction: entities.Incoming, Type: entities.Refund, RefundOf: testLedgerId},
+	}}
+
+	sender := func() *entities.Account {
+		return &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+	}
+
+	receiver := func(balance string, currency entities.Currency) *entities.Account {
+		return &entities.Account{AccountId: "bob456", Balance: decimal.RequireFromString(balance), AvailableBalance: decimal.RequireFromString(balance), Currency: currency, Status: entities.AccountActive}
+	}
+
+	type args struct {
+		ctx      context.Context
+		ledgerId string
+		amount   entities.Money
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Ledger
+		wantErr error
+	}{
+		{
+			name:    "negative amount",
+			args:    args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.NewFromFloat(-1), "USD")},
+			before:  func(a *args) {},
+			wantErr: xerrors.New("amount should be positive"),
+		},
+		{
+			name: "ledger not found",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(nil, sql.ErrNoRows)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("ledger %s not found", testLedgerId),
+		},
+		{
+			name: "ledger isn't a transfer",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Refund, nil), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("ledger %s: only transfers can be refunded", testLedgerId),
+		},
+		{
+			name: "ledger is already refunded",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.RequireFromString("5"), "USD"), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("ledger %s is already refunded", testLedgerId),
+		},
+		{
+			name: "amount exceeds the rest of ledger",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("3"), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.RequireFromString("2.5"), "USD"), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("ledger %s: can't refund more than %s", testLedgerId, "2.5 USD"),
+		},
+		{
+			name: "amount in another currency",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("2"), "EUR")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.Zero, "USD"), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: entities.ErrCurrencyMismatch,
+		},
+		{
+			name: "receiver doesn't have enough balance",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("2"), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.Zero, "USD"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("bob456")).Return(receiver("1", "USD"), nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: don't have enough balance", "bob456"),
+		},
+		{
+			name: "add refund returns error",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("2"), "USD")},
+			before: func(a *args) {
+				credit, debit := receiver("10", "USD"), sender()
+
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.Zero, "USD"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("bob456")).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("alice123")).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddRefundTx(gomock.Any(), a.ctx, a.ledgerId, credit, debit, a.amount, nil).Return(nil, addError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: addError,
+		},
+		{
+			name: "add event returns error",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("2"), "USD")},
+			before: func(a *args) {
+				credit, debit := receiver("10", "USD"), sender()
+
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.Zero, "USD"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("bob456")).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("alice123")).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddRefundTx(gomock.Any(), a.ctx, a.ledgerId, credit, debit, a.amount, nil).Return(testRefund, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testRefund).Return(nil, addEventError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: addEventError,
+		},
+		{
+			name: "refunds part of ledger",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("2"), "USD")},
+			before: func(a *args) {
+				credit, debit := receiver("10", "USD"), sender()
+
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.RequireFromString("1"), "USD"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("bob456")).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("alice123")).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddRefundTx(gomock.Any(), a.ctx, a.ledgerId, credit, debit, a.amount, nil).Return(testRefund, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testRefund).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testRefund,
+		},
+		{
+			name: "refunds the rest of ledger",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId},
+			before: func(a *args) {
+				credit, debit := receiver("10", "USD"), sender()
+				rest := entities.NewMoney(decimal.RequireFromString("2"), "USD")
+
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, nil), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.RequireFromString("3"), "USD"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("bob456")).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("alice123")).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, rest.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, rest).Return(nil)
+				mockLedgers.EXPECT().AddRefundTx(gomock.Any(), a.ctx, a.ledgerId, credit, debit, rest, nil).Return(testRefund, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testRefund).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testRefund,
+		},
+		{
+			name: "refunds with rate of original transfer",
+			args: args{ctx: context.Background(), ledgerId: testLedgerId, amount: entities.NewMoney(decimal.RequireFromString("2"), "USD")},
+			before: func(a *args) {
+				credit, debit := receiver("10", "EUR"), sender()
+				fx := &entities.Conversion{Rate: decimal.RequireFromString("0.8"), SourceAmount: decimal.RequireFromString("5"), TargetAmount: decimal.RequireFromString("4")}
+				source := entities.NewMoney(decimal.RequireFromString("2").Mul(fx.Rate).Round(2), "EUR")
+
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().GetByGuidTx(gomock.Any(), a.ctx, a.ledgerId).Return(original(entities.Transfer, fx), nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("bob456"), entities.AccountId("alice123")).Return(nil)
+				mockLedgers.EXPECT().RefundedTx(gomock.Any(), a.ctx, gomock.Any()).Return(entities.NewMoney(decimal.Zero, "USD"), nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("bob456")).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("alice123")).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, source.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddRefundTx(gomock.Any(), a.ctx, a.ledgerId, credit, debit, source, gomock.Any()).
+					DoAndReturn(func(_ *sql.Tx, _ context.Context, _ string, _, _ *entities.Account, _ entities.Money, fx *entities.Conversion) (*entities.Ledger, error) {
+						if !fx.Rate.Equal(decimal.RequireFromString("1.25")) || !fx.SourceAmount.Equal(source.Amount) || !fx.TargetAmount.Equal(a.amount.Amount) {
+							t.Fatalf("AddRefundTx() got unexpected conversion %v", fx)
+						}
+						return testRefund, nil
+					})
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testRefund).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testRefund,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Accounts: mockAccounts, Ledgers: mockLedgers, Outbox: mockOutbox}
+			tt.before(&tt.args)
+
+			got, err := w.Refund(tt.args.ctx, tt.args.ledgerId, tt.args.amount)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Refund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Refund() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+//RetryPolicy bounds repeating of transactions aborted by serialization failure or deadlock.
+//Attempt after n failed ones waits Backoff doubled n-1 times up to MaxBackoff, half of the delay is random
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+//DefaultRetryPolicy is used unless WithRetryPolicy is given
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, Backoff: 10 * time.Millisecond, MaxBackoff: 500 * time.Millisecond}
+
+//WithRetryPolicy sets how transactions aborted by serialization failure or deadlock are repeated,
+//zero fields of the policy keep their defaults
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(w *WalletService) {
+		if policy.MaxAttempts > 0 {
+			w.retryPolicy.MaxAttempts = policy.MaxAttempts
+		}
+		if policy.Backoff > 0 {
+			w.retryPolicy.Backoff = policy.Backoff
+		}
+		if policy.MaxBackoff > 0 {
+			w.retryPolicy.MaxBackoff = policy.MaxBackoff
+		}
+	}
+}
+
+//delay returns how long to wait after given number of failed attempts
+func (rp RetryPolicy) delay(attempts int) time.Duration {
+	delay := rp.Backoff
+	for i := 1; i < attempts && delay < rp.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+
+	if delay < 2 {
+		return delay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+//retryable tells if transaction failed with error which goes away when it's repeated
+func retryable(err error) (string, bool) {
+	var pgError *pq.Error
+	if !xerrors.As(err, &pgError) {
+		return "", false
+	}
+
+	code := string(pgError.Code)
+	return code, code == serializationFailure || code == deadlockDetected
+}
+
+//retrySerializable repeats fn of operation while it fails with serialization error or deadlock, it gives up
+//with ConflictError when attempts of retryPolicy are exhausted or ctx is done before the next one
+func (w *WalletService) retrySerializable(ctx context.Context, operation string, fn func() error) error {
+	for attempts := 1; ; attempts++ {
+		err := fn()
+		code, ok := retryable(err)
+		if !ok {
+			return err
+		}
+
+		if attempts >= w.retryPolicy.MaxAttempts {
+			return models.ConflictError{xerrors.Errorf("%s is aborted after %d attempts: %w", operation, attempts, err)}
+		}
+
+		delay := w.retryPolicy.delay(attempts)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return models.ConflictError{xerrors.Errorf("%s is aborted before deadline after %d attempts: %w", operation, attempts, err)}
+		}
+
+		if w.retries != nil {
+			w.retries.With("operation", operation, "code", code).Add(1)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return models.ConflictError{xerrors.Errorf("%s is canceled after %d attempts: %w", operation, attempts, err)}
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+)
+
+func TestWalletService_retrySerializable(t *testing.T) {
+	serializationError := xerrors.Errorf("error during commit: %w", &pq.Error{Code: serializationFailure})
+	deadlockError := models.DBErrorWrapper{xerrors.Errorf("update balance error: %w", &pq.Error{Code: deadlockDetected})}
+	uniqueError := xerrors.Errorf("error during commit: %w", &pq.Error{Code: uniqueViolation})
+	otherError := xerrors.New("some_error")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expiring, cancelExpiring := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelExpiring()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		errs      []error
+		wantCalls int
+		wantErr   error
+		want      map[string]float64
+	}{
+		{
+			name:      "succeeds at once",
+			ctx:       context.Background(),
+			errs:      []error{nil},
+			wantCalls: 1,
+			want:      map[string]float64{},
+		},
+		{
+			name:      "repeats serialization failures and deadlocks",
+			ctx:       context.Background(),
+			errs:      []error{serializationError, deadlockError, nil},
+			wantCalls: 3,
+			want:      map[string]float64{"operation,send,code,40001": 1, "operation,send,code,40P01": 1},
+		},
+		{
+			name:      "other errors are returned",
+			ctx:       context.Background(),
+			errs:      []error{uniqueError},
+			wantCalls: 1,
+			wantErr:   uniqueError,
+			want:      map[string]float64{},
+		},
+		{
+			name:      "errors without database code are returned",
+			ctx:       context.Background(),
+			errs:      []error{otherError},
+			wantCalls: 1,
+			wantErr:   otherError,
+			want:      map[string]float64{},
+		},
+		{
+			name:      "gives up after max attempts",
+			ctx:       context.Background(),
+			errs:      []error{serializationError, serializationError, deadlockError},
+			wantCalls: 3,
+			wantErr:   models.ConflictError{},
+			want:      map[string]float64{"operation,send,code,40001": 2},
+		},
+		{
+			name:      "gives up when context is canceled",
+			ctx:       canceled,
+			errs:      []error{serializationError},
+			wantCalls: 1,
+			wantErr:   models.ConflictError{},
+			want:      map[string]float64{"operation,send,code,40001": 1},
+		},
+		{
+			name:      "gives up before deadline",
+			ctx:       expiring,
+			errs:      []error{serializationError},
+			wantCalls: 1,
+			wantErr:   models.ConflictError{},
+			want:      map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retries := &testCounter{values: map[string]float64{}}
+			w := &WalletService{retries: retries, retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}}
+			if tt.ctx == expiring {
+				w.retryPolicy.Backoff = time.Second
+			}
+
+			calls := 0
+			err := w.retrySerializable(tt.ctx, "send", func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+
+			if _, ok := tt.wantErr.(models.ConflictError); ok {
+				if !xerrors.As(err, &models.ConflictError{}) || !xerrors.Is(err, tt.errs[calls-1]) {
+					t.Fatalf("retrySerializable() error = %v, want conflict of %v", err, tt.errs[calls-1])
+				}
+			} else if err != tt.wantErr {
+				t.Fatalf("retrySerializable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("retrySerializable() calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			if !reflect.DeepEqual(retries.values, tt.want) {
+				t.Errorf("retrySerializable() retries = %v, want %v", retries.values, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	tests := []struct {
+		attempts int
+		max      time.Duration
+	}{
+		{attempts: 1, max: 10 * time.Millisecond},
+		{attempts: 2, max: 20 * time.Millisecond},
+		{attempts: 3, max: 40 * time.Millisecond},
+		{attempts: 4, max: 50 * time.Millisecond},
+		{attempts: 9, max: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := policy.delay(tt.attempts); got < tt.max/2 || got >= tt.max {
+				t.Fatalf("delay(%d) = %v, want in [%v, %v)", tt.attempts, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	w := &WalletService{retryPolicy: DefaultRetryPolicy}
+
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MaxBackoff: time.Second})(w)
+
+	want := RetryPolicy{MaxAttempts: 2, Backoff: DefaultRetryPolicy.Backoff, MaxBackoff: time.Second}
+	if w.retryPolicy != want {
+		t.Errorf("WithRetryPolicy() policy = %v, want %v", w.retryPolicy, want)
+	}
+}
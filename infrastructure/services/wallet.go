@@ -3,24 +3,113 @@ package services
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/NickRI/wallets-task/db/models"
 	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/repositories"
 	"github.com/NickRI/wallets-task/domain/services"
 	"github.com/NickRI/wallets-task/infrastructure/gateways"
+	"github.com/go-kit/kit/metrics"
 	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"golang.org/x/xerrors"
 )
 
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+	defaultQuoteTTL       = time.Minute
+	defaultHoldTTL        = 7 * 24 * time.Hour
+)
+
+const uniqueViolation = "23505"
+
+//WalletService without Limits repository doesn't limit payments
 type WalletService struct {
-	db       *sql.DB
-	Accounts repositories.Accounts
-	Ledgers  repositories.Ledgers
+	db              *sql.DB
+	idempotencyTTL  time.Duration
+	quoteTTL        time.Duration
+	holdTTL         time.Duration
+	retryPolicy     RetryPolicy
+	fxRates         services.FXRates
+	retries         metrics.Counter
+	volume          metrics.Counter
+	authorization   bool
+	singleStatement bool
+	Accounts        repositories.Accounts
+	Ledgers         repositories.Ledgers
+	IdempotencyKeys repositories.IdempotencyKeys
+	Quotes          repositories.Quotes
+	Holds           repositories.Holds
+	Outbox          repositories.Outbox
+	Limits          repositories.Limits
+}
+
+//Option configures WalletService
+type Option func(*WalletService)
+
+//WithIdempotencyTTL sets retention window of idempotency keys
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(w *WalletService) {
+		if ttl > 0 {
+			w.idempotencyTTL = ttl
+		}
+	}
 }
 
-func NewWalletService(d *sql.DB) (services.Wallet, error) {
+//WithFXRates enables cross-currency payments converted by rates
+func WithFXRates(rates services.FXRates) Option {
+	return func(w *WalletService) {
+		w.fxRates = rates
+	}
+}
+
+//WithQuoteTTL sets how long quoted rate is locked for the client
+func WithQuoteTTL(ttl time.Duration) Option {
+	return func(w *WalletService) {
+		if ttl > 0 {
+			w.quoteTTL = ttl
+		}
+	}
+}
+
+//WithHoldTTL sets how long authorized funds are reserved unless captured or voided
+func WithHoldTTL(ttl time.Duration) Option {
+	return func(w *WalletService) {
+		if ttl > 0 {
+			w.holdTTL = ttl
+		}
+	}
+}
+
+//WithMetrics counts serialization failure retries labeled by operation and
+//amounts of committed payments labeled by currency and type
+func WithMetrics(retries, volume metrics.Counter) Option {
+	return func(w *WalletService) {
+		w.retries = retries
+		w.volume = volume
+	}
+}
+
+//WithAuthorization requires principal in context of operations moving funds, caller has to own
+//the account funds are taken from or hold admin scope
+func WithAuthorization() Option {
+	return func(w *WalletService) {
+		w.authorization = true
+	}
+}
+
+//WithSingleStatementTransfers makes transfers between accounts of the same currency with a single statement
+//balance check, balance updates and both legs, the other transfers and the failed ones take the regular way.
+//The statement doesn't check limits, so it skips every account which has own limits or limits of its currency
+//and such transfers always take the regular way too
+func WithSingleStatementTransfers() Option {
+	return func(w *WalletService) {
+		w.singleStatement = true
+	}
+}
+
+func NewWalletService(d *sql.DB, options ...Option) (services.Wallet, error) {
 
 	AccountTable, err := gateways.NewAccounts(d)
 	if err != nil {
@@ -32,41 +121,296 @@ func NewWalletService(d *sql.DB) (services.Wallet, error) {
 		return nil, xerrors.Errorf("Error in payments table: %w", err)
 	}
 
-	return &WalletService{db: d, Accounts: AccountTable, Ledgers: LedgersTable}, nil
+	IdempotencyKeysTable, err := gateways.NewIdempotencyKeys(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in idempotency keys table: %w", err)
+	}
+
+	QuotesTable, err := gateways.NewQuotes(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in fx quotes table: %w", err)
+	}
+
+	HoldsTable, err := gateways.NewHolds(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in holds table: %w", err)
+	}
+
+	OutboxTable, err := gateways.NewOutbox(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in outbox table: %w", err)
+	}
+
+	LimitsTable, err := gateways.NewLimits(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in account limits table: %w", err)
+	}
+
+	w := &WalletService{
+		db:              d,
+		idempotencyTTL:  defaultIdempotencyTTL,
+		quoteTTL:        defaultQuoteTTL,
+		holdTTL:         defaultHoldTTL,
+		retryPolicy:     DefaultRetryPolicy,
+		Accounts:        AccountTable,
+		Ledgers:         LedgersTable,
+		IdempotencyKeys: IdempotencyKeysTable,
+		Quotes:          QuotesTable,
+		Holds:           HoldsTable,
+		Outbox:          OutboxTable,
+		Limits:          LimitsTable,
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w, nil
 }
 
-func (w *WalletService) LedgersList(ctx context.Context) (entities.Ledgers, error) {
-	return w.Ledgers.List(ctx)
+//LedgersList returns page of ledgers, ledgers of all accounts are listed for admins only,
+//others have to filter them by account they may use
+func (w *WalletService) LedgersList(ctx context.Context, filter *entities.LedgerFilter) (*entities.LedgersPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, models.ValidationError{err}
+	}
+
+	err := w.authorizeAdmin(ctx)
+	if filter.Account != "" {
+		err = w.authorizeAnyAccount(ctx, filter.Account)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w.Ledgers.List(ctx, filter)
 }
 
-func (w *WalletService) AccountsList(ctx context.Context) (entities.Accounts, error) {
-	return w.Accounts.List(ctx)
+//GetLedger returns single ledger by its guid to the caller who may use either side of it
+func (w *WalletService) GetLedger(ctx context.Context, ledgerId string) (*entities.Ledger, error) {
+	ledger, err := w.Ledgers.GetByGuid(ctx, ledgerId)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFoundWrapper{xerrors.Errorf("ledger %s not found", ledgerId)}
+		}
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	if err = w.authorizeAnyAccount(ctx, ledger.Outgoing().Account, ledger.Incoming().Account); err != nil {
+		return nil, err
+	}
+
+	return ledger, nil
 }
 
-func (w *WalletService) Send(ctx context.Context, creditId, debitId string, amount decimal.Decimal) (l *entities.Ledger, err error) {
-	for {
-		l, err = w.trySend(ctx, creditId, debitId, amount)
-		if err != nil {
-			var pgError *pq.Error
-			if xerrors.As(err, &pgError) {
-				tpe := xerrors.Unwrap(err).(*pq.Error)
-				if tpe.Code == "40001" {
-					continue
-				}
-			}
+//AccountLedgers returns account's payments with running balance, newest first
+func (w *WalletService) AccountLedgers(ctx context.Context, accountId string, page *entities.Pagination) (*entities.PaymentsPage, error) {
+	if err := page.Validate(); err != nil {
+		return nil, models.ValidationError{err}
+	}
+
+	account, err := w.GetAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.Ledgers.ListByAccount(ctx, account, page)
+}
+
+//Quote locks the current rate of currency pair for quoteTTL
+func (w *WalletService) Quote(ctx context.Context, from, to string) (*entities.FXQuote, error) {
+	if from == to {
+		return nil, models.ValidationError{xerrors.New("quote requires two different currencies")}
+	}
+
+	if w.fxRates == nil {
+		return nil, xerrors.New("fx rates provider is not configured")
+	}
+
+	rate, err := w.fxRates.Rate(ctx, entities.Currency(from), entities.Currency(to))
+	if err != nil {
+		return nil, xerrors.Errorf("get fx rate error: %w", err)
+	}
+
+	return w.Quotes.Create(ctx, &entities.FXQuote{FXRate: *rate, ExpiresAt: time.Now().Add(w.quoteTTL)})
+}
+
+//AccountsList returns all accounts to admins and own accounts to others
+func (w *WalletService) AccountsList(ctx context.Context) (entities.Accounts, error) {
+	accounts, err := w.Accounts.List(ctx)
+	if err != nil || !w.authorization {
+		return accounts, err
+	}
+
+	p, ok := entities.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, models.UnauthenticatedError{xerrors.New("authentication required")}
+	}
+
+	own := make(entities.Accounts, 0, len(accounts))
+	for _, account := range accounts {
+		if p.CanUse(account) {
+			own = append(own, account)
 		}
-		break
 	}
 
+	return own, nil
+}
+
+//Send transfers amount from credit to debit account, non empty idempotencyKey makes
+//repeated calls with the same arguments return the ledger of the first one.
+//Amount without currency is in credit's currency, it's converted with the rate of quoteId or the current one
+func (w *WalletService) Send(ctx context.Context, idempotencyKey, quoteId, creditId, debitId string, amount entities.Money) (l *entities.Ledger, err error) {
+	if !amount.IsPositive() {
+		return nil, models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	err = w.retrySerializable(ctx, "send", func() (err error) {
+		l, err = w.tryTransfer(ctx, idempotencyKey, quoteId, entities.Transfer, creditId, debitId, amount)
+		return
+	})
+
+	return
+}
+
+//Deposit puts amount to the account from settlement account of its currency
+func (w *WalletService) Deposit(ctx context.Context, idempotencyKey, accountId string, amount entities.Money) (l *entities.Ledger, err error) {
+	if !amount.IsPositive() {
+		return nil, models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	err = w.retrySerializable(ctx, "deposit", func() (err error) {
+		l, err = w.tryTransfer(ctx, idempotencyKey, "", entities.Deposit, "", accountId, amount)
+		return
+	})
+
 	return
 }
 
-func (w *WalletService) trySend(ctx context.Context, creditId, debitId string, amount decimal.Decimal) (l *entities.Ledger, err error) {
-	var credit, debit *entities.Account
+//Withdraw takes amount from the account to settlement account of its currency
+func (w *WalletService) Withdraw(ctx context.Context, idempotencyKey, accountId string, amount entities.Money) (l *entities.Ledger, err error) {
+	if !amount.IsPositive() {
+		return nil, models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	err = w.retrySerializable(ctx, "withdraw", func() (err error) {
+		l, err = w.tryTransfer(ctx, idempotencyKey, "", entities.Withdrawal, accountId, "", amount)
+		return
+	})
+
+	return
+}
+
+//CreateAccount adds active account with zero balance, settlement account of
+//the currency is created along if it doesn't exist yet
+func (w *WalletService) CreateAccount(ctx context.Context, accountId, currency string) (*entities.Account, error) {
+	if entities.AccountId(accountId).IsSystem() {
+		return nil, models.ValidationError{xerrors.Errorf("account id %s is reserved", accountId)}
+	}
+
+	if !entities.Currency(currency).Valid() {
+		return nil, models.ValidationError{xerrors.Errorf("currency %s is unknown", currency)}
+	}
+
+	var owner string
+	if w.authorization {
+		p, ok := entities.PrincipalFromContext(ctx)
+		if !ok {
+			return nil, models.UnauthenticatedError{xerrors.New("authentication required")}
+		}
+		owner = p.Subject
+	}
+
+	if err := w.Accounts.CreateSettlement(ctx, entities.Currency(currency)); err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	account, err := w.Accounts.Create(ctx, &entities.Account{
+		AccountId: entities.AccountId(accountId),
+		Currency:  entities.Currency(currency),
+		Status:    entities.AccountActive,
+		Owner:     owner,
+	})
+	if err != nil {
+		var pgError *pq.Error
+		if xerrors.As(err, &pgError) && pgError.Code == uniqueViolation {
+			return nil, models.AccountStateError{xerrors.Errorf("account %s already exists", accountId)}
+		}
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return account, nil
+}
+
+//GetAccount returns the account to the caller who may use it
+func (w *WalletService) GetAccount(ctx context.Context, accountId string) (*entities.Account, error) {
+	account, err := w.getAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.authorizeAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (w *WalletService) getAccount(ctx context.Context, accountId string) (*entities.Account, error) {
+	account, err := w.Accounts.GetByName(ctx, entities.AccountId(accountId))
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFoundWrapper{xerrors.Errorf("account %s not found", accountId)}
+		}
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return account, nil
+}
+
+//BalanceAt returns account's balance at the moment derived from its payments, zero at means now
+func (w *WalletService) BalanceAt(ctx context.Context, accountId string, at time.Time) (*entities.AccountBalance, error) {
+	now := time.Now()
+	if at.IsZero() {
+		at = now
+	}
+
+	if at.After(now) {
+		return nil, models.ValidationError{xerrors.Errorf("balance at %s is in the future", at.Format(time.RFC3339))}
+	}
+
+	account, err := w.GetAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := w.Accounts.BalanceAt(ctx, account, at)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return &entities.AccountBalance{
+		AccountId: account.AccountId,
+		Balance:   balance.Amount,
+		Currency:  account.Currency,
+		At:        at,
+	}, nil
+}
+
+//ChangeAccountStatus moves account to the status, account could be closed only with zero balance
+func (w *WalletService) ChangeAccountStatus(ctx context.Context, accountId string, status entities.AccountStatus) (a *entities.Account, err error) {
+	err = w.retrySerializable(ctx, "change_account_status", func() (err error) {
+		a, err = w.tryChangeAccountStatus(ctx, accountId, status)
+		return
+	})
 
+	return
+}
+
+func (w *WalletService) tryChangeAccountStatus(ctx context.Context, accountId string, status entities.AccountStatus) (a *entities.Account, err error) {
 	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
-		return l, xerrors.Errorf("begin transaction error: %w", err)
+		return a, xerrors.Errorf("begin transaction error: %w", err)
 	}
 
 	defer func() {
@@ -79,55 +423,452 @@ func (w *WalletService) trySend(ctx context.Context, creditId, debitId string, a
 		}
 	}()
 
-	credit, err = w.Accounts.GetByNameTx(tx, ctx, entities.AccountId(creditId))
+	a, err = w.getAccountTx(tx, ctx, accountId)
 	if err != nil {
-		if xerrors.Is(err, sql.ErrNoRows) {
-			err = models.NotFoundWrapper{xerrors.Errorf("account %s not found", creditId)}
-		} else {
-			err = xerrors.Errorf("get by name error: %w", err)
-		}
+		return
+	}
 
+	if err = w.authorizeAccount(ctx, a); err != nil {
+		a = nil
 		return
 	}
 
-	if credit.Balance.LessThan(amount) {
-		err = models.LowBalanceWrapper{xerrors.Errorf("%s: don't have enough balance", credit.AccountId)}
+	if !a.Status.CanChangeTo(status) {
+		a, err = nil, models.AccountStateError{xerrors.Errorf("%s: can't change status from %s to %s", accountId, a.Status, status)}
 		return
 	}
 
-	debit, err = w.Accounts.GetByNameTx(tx, ctx, entities.AccountId(debitId))
+	if status == entities.AccountClosed && !a.Balance.IsZero() {
+		a, err = nil, models.AccountStateError{xerrors.Errorf("%s: can't close account with non zero balance", accountId)}
+		return
+	}
+
+	if err = w.Accounts.UpdateStatusTx(tx, ctx, a, status); err != nil {
+		a, err = nil, xerrors.Errorf("%s: error during status update: %w", accountId, err)
+		return
+	}
+
+	a.Status = status
+	return
+}
+
+//authorizeAccount checks the caller of ctx may take funds from the account,
+//settlement accounts have no owner, so deposits and withdrawals are admin only
+func (w *WalletService) authorizeAccount(ctx context.Context, account *entities.Account) error {
+	if !w.authorization {
+		return nil
+	}
+
+	p, ok := entities.PrincipalFromContext(ctx)
+	if !ok {
+		return models.UnauthenticatedError{xerrors.New("authentication required")}
+	}
+
+	if !p.CanUse(account) {
+		return models.ForbiddenError{xerrors.Errorf("%s: access denied for %s", account.AccountId, p.Subject)}
+	}
+
+	return nil
+}
+
+//holdPayerKey marks ctx of captured hold with its payer, who isn't authorized again
+type holdPayerKey struct{}
+
+//authorizePayer checks the caller may take funds from the credit account, payer of captured hold is already authorized
+func (w *WalletService) authorizePayer(ctx context.Context, credit *entities.Account) error {
+	if payer, ok := ctx.Value(holdPayerKey{}).(entities.AccountId); ok && payer == credit.AccountId {
+		return nil
+	}
+
+	return w.authorizeAccount(ctx, credit)
+}
+
+//authorizeAnyAccount checks the caller may use at least one of the accounts, so both sides of a payment may see it
+func (w *WalletService) authorizeAnyAccount(ctx context.Context, accountIds ...entities.AccountId) (err error) {
+	if !w.authorization {
+		return nil
+	}
+
+	for _, accountId := range accountIds {
+		var account *entities.Account
+		if account, err = w.getAccount(ctx, string(accountId)); err != nil {
+			return err
+		}
+		if err = w.authorizeAccount(ctx, account); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+//authorizeAdmin checks the caller has admin scope
+func (w *WalletService) authorizeAdmin(ctx context.Context) error {
+	if !w.authorization {
+		return nil
+	}
+
+	p, ok := entities.PrincipalFromContext(ctx)
+	if !ok {
+		return models.UnauthenticatedError{xerrors.New("authentication required")}
+	}
+
+	if !p.HasScope(entities.AdminScope) {
+		return models.ForbiddenError{xerrors.Errorf("access denied for %s", p.Subject)}
+	}
+
+	return nil
+}
+
+func (w *WalletService) getAccountTx(tx *sql.Tx, ctx context.Context, accountId string) (*entities.Account, error) {
+	account, err := w.Accounts.GetByNameTx(tx, ctx, entities.AccountId(accountId))
 	if err != nil {
 		if xerrors.Is(err, sql.ErrNoRows) {
-			err = models.NotFoundWrapper{xerrors.Errorf("account %s not found", debitId)}
-		} else {
-			err = xerrors.Errorf("get by name error: %w", err)
+			return nil, models.NotFoundWrapper{xerrors.Errorf("account %s not found", accountId)}
+		}
+		return nil, xerrors.Errorf("get by name error: %w", err)
+	}
+
+	return account, nil
+}
+
+//observeTransfer adds amount of committed payment to the volume of its currency
+func (w *WalletService) observeTransfer(paymentType entities.PaymentType, currency entities.Currency, amount decimal.Decimal) {
+	if w.volume == nil {
+		return
+	}
+
+	value, _ := amount.Abs().Float64()
+	w.volume.With("currency", string(currency), "type", string(paymentType)).Add(value)
+}
+
+func (w *WalletService) tryTransfer(ctx context.Context, idempotencyKey, quoteId string, paymentType entities.PaymentType, creditId, debitId string, amount entities.Money) (l *entities.Ledger, err error) {
+	var (
+		ikey     *entities.IdempotencyKey
+		currency entities.Currency
+	)
+
+	for _, id := range []string{creditId, debitId} {
+		if entities.AccountId(id).IsSystem() {
+			return l, models.AccountStateError{xerrors.Errorf("%s: system account can't be used in payments", id)}
+		}
+	}
+
+	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return l, xerrors.Errorf("begin transaction error: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = xerrors.Errorf("error during commit: %w", err)
+			return
+		}
+		//replayed requests don't move money again
+		if currency != "" {
+			w.observeTransfer(paymentType, currency, amount.Amount)
+		}
+	}()
+
+	if idempotencyKey != "" {
+		ikey = entities.NewIdempotencyKey(idempotencyKey, quoteId, creditId, debitId, amount)
+
+		var stored *entities.IdempotencyKey
+		stored, err = w.IdempotencyKeys.GetByKeyTx(tx, ctx, idempotencyKey, time.Now().Add(-w.idempotencyTTL))
+		switch {
+		case err == nil && stored.Match(ikey):
+			//replay is given only to the caller who may take funds from the account of the stored ledger
+			if err = w.authorizeReplayTx(tx, ctx, stored.Ledger); err == nil {
+				l = stored.Ledger
+			}
+			return
+		case err == nil:
+			err = models.IdempotencyConflictError{xerrors.Errorf("idempotency key %s was used with another request", idempotencyKey)}
+			return
+		case !xerrors.Is(err, sql.ErrNoRows):
+			err = xerrors.Errorf("get idempotency key error: %w", err)
+			return
 		}
+	}
+
+	transfer := w.transferTx
+	if w.singleStatement && paymentType == entities.Transfer && quoteId == "" {
+		transfer = w.statementTransferTx
+	}
+
+	if l, currency, err = transfer(tx, ctx, quoteId, paymentType, creditId, debitId, amount); err != nil {
 		return
 	}
 
-	if credit.Currency != debit.Currency {
-		err = xerrors.New("currencies for accounts is not equal")
+	if ikey != nil {
+		ikey.Ledger = l
+		if err = w.IdempotencyKeys.AddTx(tx, ctx, ikey); err != nil {
+			l, err = nil, xerrors.Errorf("error during add idempotency key: %w", err)
+		}
+	}
+	return
+}
+
+//authorizeReplayTx checks the caller may use the account the stored ledger took funds from,
+//it's settlement account for deposits, so only admins replay them
+func (w *WalletService) authorizeReplayTx(tx *sql.Tx, ctx context.Context, l *entities.Ledger) error {
+	if !w.authorization {
+		return nil
+	}
+
+	account, err := w.getAccountTx(tx, ctx, string(l.Outgoing().Account))
+	if err != nil {
+		return err
+	}
+
+	return w.authorizeAccount(ctx, account)
+}
+
+//transferTx moves amount between accounts and records the ledger with its payment.completed event within tx,
+//currency is the one of amount taken from credit account
+func (w *WalletService) transferTx(tx *sql.Tx, ctx context.Context, quoteId string, paymentType entities.PaymentType, creditId, debitId string, amount entities.Money) (l *entities.Ledger, currency entities.Currency, err error) {
+	var (
+		credit, debit *entities.Account
+		fx            *entities.Conversion
+	)
+
+	credit, debit, amount, err = w.transferAccountsTx(tx, ctx, paymentType, creditId, debitId, amount)
+	if err != nil {
 		return
 	}
 
-	if err = w.Accounts.LockTx(tx, ctx); err != nil {
-		err = xerrors.Errorf("lock accounts table error: %w", err)
+	fx, err = w.conversionTx(tx, ctx, quoteId, credit, debit, amount.Amount)
+	if err != nil {
 		return
 	}
 
+	target := amount
+	if fx != nil {
+		target = entities.NewMoney(fx.TargetAmount, debit.Currency)
+	}
+
 	if err = w.Accounts.UpdateBalanceTx(tx, ctx, credit, amount.Neg()); err != nil {
 		err = xerrors.Errorf("%s: error during decrease balance: %w", credit.AccountId, err)
 		return
 	}
 
-	if err = w.Accounts.UpdateBalanceTx(tx, ctx, debit, amount); err != nil {
-		err = xerrors.Errorf("%s: error during increase balance: %w", credit.AccountId, err)
+	if err = w.Accounts.UpdateBalanceTx(tx, ctx, debit, target); err != nil {
+		err = xerrors.Errorf("%s: error during increase balance: %w", debit.AccountId, err)
 		return
 	}
 
-	l, err = w.Ledgers.AddTx(tx, ctx, credit, debit, amount)
+	l, err = w.Ledgers.AddTx(tx, ctx, credit, debit, amount, paymentType, fx)
 	if err != nil {
 		err = xerrors.Errorf("error during add ledger: %w", err)
+		return
+	}
+
+	if _, err = w.Outbox.AddTx(tx, ctx, entities.PaymentCompleted, l); err != nil {
+		l, err = nil, xerrors.Errorf("error during add event: %w", err)
+		return
+	}
+
+	currency = credit.Currency
+	return
+}
+
+//statementTransferTx makes transfer with one statement, when the statement doesn't make it transferTx repeats
+//the transfer in the same tx to report why it fails or to convert amount. Checks which don't need database are
+//made after the statement and failed one rolls the transfer back with tx
+func (w *WalletService) statementTransferTx(tx *sql.Tx, ctx context.Context, quoteId string, paymentType entities.PaymentType, creditId, debitId string, amount entities.Money) (l *entities.Ledger, currency entities.Currency, err error) {
+	attempt, err := w.Ledgers.TransferTx(tx, ctx, entities.AccountId(creditId), entities.AccountId(debitId), amount)
+	if err != nil {
+		err = xerrors.Errorf("error during transfer: %w", err)
+		return
+	}
+
+	if attempt.Ledger == nil {
+		return w.transferTx(tx, ctx, quoteId, paymentType, creditId, debitId, amount)
+	}
+
+	if _, err = accountMoney(attempt.Credit, amount); err != nil {
+		return
+	}
+
+	if err = w.authorizeAccount(ctx, attempt.Credit); err != nil {
+		return
+	}
+
+	if _, err = w.Outbox.AddTx(tx, ctx, entities.PaymentCompleted, attempt.Ledger); err != nil {
+		err = xerrors.Errorf("error during add event: %w", err)
+		return
 	}
+
+	l, currency = attempt.Ledger, attempt.Credit.Currency
 	return
 }
+
+//transferAccountsTx locks, fetches and checks both sides of the payment, settlement account
+//takes the missing side of deposits and withdrawals and is allowed to go negative.
+//Money is amount in the currency of credit account
+func (w *WalletService) transferAccountsTx(tx *sql.Tx, ctx context.Context, paymentType entities.PaymentType, creditId, debitId string, amount entities.Money) (credit, debit *entities.Account, money entities.Money, err error) {
+	var low bool
+
+	if paymentType == entities.Deposit {
+		if err = w.lockAccountsTx(tx, ctx, debitId); err != nil {
+			return
+		}
+		if debit, err = w.getActiveAccountTx(tx, ctx, debitId); err != nil {
+			return
+		}
+		if money, err = accountMoney(debit, amount); err != nil {
+			return
+		}
+		settlementId := string(entities.SettlementAccountId(debit.Currency))
+		if err = w.lockAccountsTx(tx, ctx, settlementId); err != nil {
+			return
+		}
+		if credit, err = w.getActiveAccountTx(tx, ctx, settlementId); err != nil {
+			return
+		}
+		err = w.authorizeAccount(ctx, credit)
+		return
+	}
+
+	if paymentType == entities.Withdrawal {
+		err = w.lockAccountsTx(tx, ctx, creditId)
+	} else {
+		err = w.lockAccountsTx(tx, ctx, creditId, debitId)
+	}
+	if err != nil {
+		return
+	}
+
+	if credit, err = w.getActiveAccountTx(tx, ctx, creditId); err != nil {
+		return
+	}
+
+	if money, err = accountMoney(credit, amount); err != nil {
+		return
+	}
+
+	if err = w.authorizePayer(ctx, credit); err != nil {
+		return
+	}
+
+	//approved credit line lets account go below zero
+	if low, err = credit.Spendable(time.Now()).LessThan(money); err != nil || low {
+		if err == nil {
+			err = models.LowBalanceWrapper{xerrors.Errorf("%s: don't have enough balance", credit.AccountId)}
+		}
+		return
+	}
+
+	if err = w.checkLimitsTx(tx, ctx, credit, money); err != nil {
+		return
+	}
+
+	if paymentType == entities.Withdrawal {
+		debitId = string(entities.SettlementAccountId(credit.Currency))
+		if err = w.lockAccountsTx(tx, ctx, debitId); err != nil {
+			return
+		}
+	}
+
+	debit, err = w.getActiveAccountTx(tx, ctx, debitId)
+	return
+}
+
+//lockAccountsTx locks accounts before they are read, so balances checked within tx can't change until it ends.
+//Settlement account of deposits and withdrawals is locked after the user's one, its name depends on currency
+//of the user's account, and as settlement accounts are always locked last transactions still don't deadlock
+func (w *WalletService) lockAccountsTx(tx *sql.Tx, ctx context.Context, accountIds ...string) error {
+	ids := make([]entities.AccountId, 0, len(accountIds))
+	for _, id := range accountIds {
+		ids = append(ids, entities.AccountId(id))
+	}
+
+	if err := w.Accounts.LockTx(tx, ctx, ids...); err != nil {
+		return xerrors.Errorf("lock accounts error: %w", err)
+	}
+
+	return nil
+}
+
+//accountMoney takes amount in the currency of account and checks it against minor unit of the currency
+func accountMoney(account *entities.Account, amount entities.Money) (entities.Money, error) {
+	money, err := amount.In(account.Currency)
+	if err != nil {
+		return money, models.ValidationError{xerrors.Errorf("%s: %w", account.AccountId, err)}
+	}
+
+	if err := money.Validate(); err != nil {
+		return money, models.ValidationError{err}
+	}
+
+	return money, nil
+}
+
+//conversionTx returns nil for payments in a single currency, rate locked by quote takes precedence over
+//the current one of the provider. Amount which converts to less than minor unit of debit's currency is rejected
+func (w *WalletService) conversionTx(tx *sql.Tx, ctx context.Context, quoteId string, credit, debit *entities.Account, amount decimal.Decimal) (*entities.Conversion, error) {
+	fx, err := w.rateConversionTx(tx, ctx, quoteId, credit, debit, amount)
+	if err != nil || fx == nil {
+		return fx, err
+	}
+
+	if !fx.TargetAmount.IsPositive() {
+		return nil, models.ValidationError{xerrors.Errorf("amount %s %s is too small to convert to %s", amount, credit.Currency, debit.Currency)}
+	}
+
+	return fx, nil
+}
+
+func (w *WalletService) rateConversionTx(tx *sql.Tx, ctx context.Context, quoteId string, credit, debit *entities.Account, amount decimal.Decimal) (*entities.Conversion, error) {
+	if quoteId == "" {
+		if credit.Currency == debit.Currency {
+			return nil, nil
+		}
+
+		if w.fxRates == nil {
+			return nil, models.ValidationError{xerrors.New("currencies for accounts is not equal")}
+		}
+
+		rate, err := w.fxRates.Rate(ctx, credit.Currency, debit.Currency)
+		if err != nil {
+			return nil, xerrors.Errorf("get fx rate error: %w", err)
+		}
+
+		return rate.Convert(amount), nil
+	}
+
+	quote, err := w.Quotes.GetByIdTx(tx, ctx, quoteId)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFoundWrapper{xerrors.Errorf("quote %s not found", quoteId)}
+		}
+		return nil, xerrors.Errorf("get quote error: %w", err)
+	}
+
+	if quote.From != credit.Currency || quote.To != debit.Currency {
+		return nil, models.QuoteError{xerrors.Errorf("quote %s is for %s/%s, not for %s/%s", quoteId, quote.From, quote.To, credit.Currency, debit.Currency)}
+	}
+
+	if quote.Expired(time.Now()) {
+		return nil, models.QuoteError{xerrors.Errorf("quote %s is expired", quoteId)}
+	}
+
+	return quote.Convert(amount), nil
+}
+
+func (w *WalletService) getActiveAccountTx(tx *sql.Tx, ctx context.Context, accountId string) (*entities.Account, error) {
+	account, err := w.getAccountTx(tx, ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !account.IsActive() {
+		return nil, models.AccountStateError{xerrors.Errorf("%s: account is %s", account.AccountId, account.Status)}
+	}
+
+	return account, nil
+}
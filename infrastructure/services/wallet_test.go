@@ -6,12 +6,16 @@ import (
 	"context"
 	"database/sql"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/db/models"
 	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/services"
 	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/go-kit/kit/metrics"
 	"github.com/golang/mock/gomock"
 	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
@@ -27,6 +31,11 @@ func TestNewWalletService(t *testing.T) {
 
 	newAccountsError := xerrors.New("new_accounts_error")
 	newLedgersError := xerrors.New("new_ledgers_error")
+	newIdempotencyKeysError := xerrors.New("new_idempotency_keys_error")
+	newQuotesError := xerrors.New("new_quotes_error")
+	newHoldsError := xerrors.New("new_holds_error")
+	newOutboxError := xerrors.New("new_outbox_error")
+	newLimitsError := xerrors.New("new_limits_error")
 
 	tests := []struct {
 		name    string
@@ -40,7 +49,7 @@ func TestNewWalletService(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE").
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE").
 					WillReturnError(newAccountsError)
 			},
 			wantErr: newAccountsError,
@@ -51,26 +60,211 @@ func TestNewWalletService(t *testing.T) {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .* ")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)").
 					WillReturnError(newLedgersError)
 			},
 			wantErr: newLedgersError,
 		},
+		{
+			name: "NewIdempotencyKeys returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*").
+					WillReturnError(newIdempotencyKeysError)
+			},
+			wantErr: newIdempotencyKeysError,
+		},
+		{
+			name: "NewQuotes returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*").
+					WillReturnError(newQuotesError)
+			},
+			wantErr: newQuotesError,
+		},
+		{
+			name: "NewHolds returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*").
+					WillReturnError(newHoldsError)
+			},
+			wantErr: newHoldsError,
+		},
+		{
+			name: "NewOutbox returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*")
+				mock.ExpectPrepare("INSERT INTO holds (.*) VALUES (.*)")
+				mock.ExpectPrepare("UPDATE holds SET .*")
+
+				mock.ExpectPrepare("INSERT INTO outbox_events (.*) VALUES (.*)").
+					WillReturnError(newOutboxError)
+			},
+			wantErr: newOutboxError,
+		},
+		{
+			name: "NewLimits returns error",
+			before: func() {
+				mock.ExpectPrepare("SELECT .* FROM accounts")
+				mock.ExpectPrepare("UPDATE accounts SET .*")
+				mock.ExpectPrepare("SELECT .* WHERE .*")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
+				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*")
+				mock.ExpectPrepare("INSERT INTO holds (.*) VALUES (.*)")
+				mock.ExpectPrepare("UPDATE holds SET .*")
+
+				mock.ExpectPrepare("INSERT INTO outbox_events (.*) VALUES (.*)")
+				mock.ExpectPrepare("WITH events AS (.*) INSERT INTO webhook_deliveries .*")
+				mock.ExpectPrepare("UPDATE webhook_deliveries d SET .* RETURNING .*")
+				mock.ExpectPrepare("UPDATE webhook_deliveries SET .* WHERE id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*").
+					WillReturnError(newLimitsError)
+			},
+			wantErr: newLimitsError,
+		},
 		{
 			name: "works fine",
 			before: func() {
 				mock.ExpectPrepare("SELECT .* FROM accounts")
 				mock.ExpectPrepare("UPDATE accounts SET .*")
 				mock.ExpectPrepare("SELECT .* WHERE .*")
-				mock.ExpectPrepare("LOCK TABLE accounts IN .* MODE")
+				mock.ExpectPrepare("SELECT a.id FROM accounts a WHERE a.user_name = ANY(.*) ORDER BY a.id FOR UPDATE")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) RETURNING .*")
+				mock.ExpectPrepare("UPDATE accounts SET status .*")
+				mock.ExpectPrepare("INSERT INTO accounts (.*) VALUES (.*) ON CONFLICT .*")
+				mock.ExpectPrepare("SELECT CASE .* FROM accounts a WHERE a.id = .*")
 
 				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE .*")
+				mock.ExpectPrepare("SELECT .* p.balance_after FROM .* WHERE p.account_id = .*")
 				mock.ExpectPrepare("INSERT INTO payments (.*) VALUES (.*), (.*)")
+				mock.ExpectPrepare("SELECT .* FROM payments .* WHERE p1.guid = .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE p.refund_of = .*")
+				mock.ExpectPrepare("WITH src AS .* INSERT INTO payments .*")
+
+				mock.ExpectPrepare("SELECT .* FROM idempotency_keys WHERE .*")
+				mock.ExpectPrepare("INSERT INTO idempotency_keys (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM fx_quotes WHERE .*")
+				mock.ExpectPrepare("INSERT INTO fx_quotes (.*) VALUES (.*)")
+
+				mock.ExpectPrepare("SELECT .* FROM holds .* WHERE .*")
+				mock.ExpectPrepare("INSERT INTO holds (.*) VALUES (.*)")
+				mock.ExpectPrepare("UPDATE holds SET .*")
+
+				mock.ExpectPrepare("INSERT INTO outbox_events (.*) VALUES (.*)")
+				mock.ExpectPrepare("WITH events AS (.*) INSERT INTO webhook_deliveries .*")
+				mock.ExpectPrepare("UPDATE webhook_deliveries d SET .* RETURNING .*")
+				mock.ExpectPrepare("UPDATE webhook_deliveries SET .* WHERE id = .*")
+
+				mock.ExpectPrepare("SELECT .* FROM account_limits l WHERE .*")
+				mock.ExpectPrepare("SELECT .* FROM payments p WHERE .*")
 			},
-			want: &WalletService{db: db},
+			want: &WalletService{db: db, idempotencyTTL: defaultIdempotencyTTL, quoteTTL: defaultQuoteTTL, holdTTL: defaultHoldTTL, retryPolicy: DefaultRetryPolicy},
 		},
 	}
 	for _, tt := range tests {
@@ -85,6 +279,11 @@ func TestNewWalletService(t *testing.T) {
 			if tt.want != nil {
 				tt.want.(*WalletService).Accounts = got.(*WalletService).Accounts
 				tt.want.(*WalletService).Ledgers = got.(*WalletService).Ledgers
+				tt.want.(*WalletService).IdempotencyKeys = got.(*WalletService).IdempotencyKeys
+				tt.want.(*WalletService).Quotes = got.(*WalletService).Quotes
+				tt.want.(*WalletService).Holds = got.(*WalletService).Holds
+				tt.want.(*WalletService).Outbox = got.(*WalletService).Outbox
+				tt.want.(*WalletService).Limits = got.(*WalletService).Limits
 			}
 
 			if !reflect.DeepEqual(got, tt.want) {
@@ -101,19 +300,21 @@ func TestWalletService_AccountsList(t *testing.T) {
 	mockAccounts := mock.NewMockAccounts(ctrl)
 	testError := xerrors.New("test_error")
 	testAccounts := entities.Accounts{
-		&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(4.124), Currency: "USD"},
-		&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(3.124), Currency: "USD"},
+		&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(4.124), AvailableBalance: decimal.NewFromFloat(4.124), Currency: "USD"},
+		&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(3.124), AvailableBalance: decimal.NewFromFloat(3.124), Currency: "USD"},
 	}
+	ownedAccount := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(3.124), AvailableBalance: decimal.NewFromFloat(3.124), Currency: "USD", Owner: "bob"}
 
 	type args struct {
 		ctx context.Context
 	}
 	tests := []struct {
-		name    string
-		args    args
-		before  func(*args)
-		want    entities.Accounts
-		wantErr error
+		name          string
+		args          args
+		authorization bool
+		before        func(*args)
+		want          entities.Accounts
+		wantErr       error
 	}{
 		{
 			name: "return error",
@@ -131,10 +332,19 @@ func TestWalletService_AccountsList(t *testing.T) {
 			},
 			want: testAccounts,
 		},
+		{
+			name:          "lists only own accounts of principal",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "bob"})},
+			authorization: true,
+			before: func(a *args) {
+				mockAccounts.EXPECT().List(a.ctx).Return(entities.Accounts{testAccounts[0], ownedAccount}, nil)
+			},
+			want: entities.Accounts{ownedAccount},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			w := &WalletService{Accounts: mockAccounts}
+			w := &WalletService{Accounts: mockAccounts, authorization: tt.authorization}
 			tt.before(&tt.args)
 
 			got, err := w.AccountsList(tt.args.ctx)
@@ -159,61 +369,266 @@ func TestWalletService_LedgersList(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockAccounts := mock.NewMockAccounts(ctrl)
 	mockLedgers := mock.NewMockLedgers(ctrl)
 	testError := xerrors.New("test_error")
-	testAmount := decimal.NewFromFloat(4.124)
-	testLedgers := entities.Ledgers{
-		&entities.Ledger{
-			&entities.Payment{Account: "alice123", Amount: testAmount, ToAccount: "bob456", Direction: entities.Outgoing},
-			&entities.Payment{Account: "bob456", Amount: testAmount, FromAccount: "alice123", Direction: entities.Incoming},
+	testAmount := entities.NewMoney(decimal.NewFromFloat(4.124), "USD")
+	testDirection := entities.Outgoing
+	testPage := &entities.LedgersPage{
+		Ledgers: entities.Ledgers{
+			&entities.Ledger{Payments: [2]*entities.Payment{
+				&entities.Payment{Account: "alice123", Amount: testAmount, ToAccount: "bob456", Direction: entities.Outgoing},
+				&entities.Payment{Account: "bob456", Amount: testAmount, FromAccount: "alice123", Direction: entities.Incoming},
+			}},
 		},
+		NextCursor: "cursor",
 	}
 
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		filter *entities.LedgerFilter
+	}
+	tests := []struct {
+		name          string
+		before        func(*args)
+		args          args
+		authorization bool
+		want          *entities.LedgersPage
+		wantErr       error
+	}{
+		{
+			name:    "direction without account",
+			args:    args{ctx: context.Background(), filter: &entities.LedgerFilter{Direction: &testDirection}},
+			before:  func(a *args) {},
+			wantErr: xerrors.New("counterparty and direction filters require account"),
+		},
+		{
+			name:    "limit is too big",
+			args:    args{ctx: context.Background(), filter: &entities.LedgerFilter{Pagination: entities.Pagination{Limit: entities.MaxPageSize + 1}}},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("limit should be from 1 to %d", entities.MaxPageSize),
+		},
+		{
+			name: "return error",
+			args: args{ctx: context.Background(), filter: &entities.LedgerFilter{}},
+			before: func(a *args) {
+				mockLedgers.EXPECT().List(a.ctx, a.filter).Return(nil, testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), filter: &entities.LedgerFilter{Account: "alice123", Direction: &testDirection}},
+			before: func(a *args) {
+				mockLedgers.EXPECT().List(a.ctx, a.filter).Return(testPage, nil)
+			},
+			want: testPage,
+		},
+		{
+			name:          "ledgers of all accounts require admin",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "alice"}), filter: &entities.LedgerFilter{}},
+			authorization: true,
+			before:        func(a *args) {},
+			wantErr:       xerrors.Errorf("access denied for %s", "alice"),
+		},
+		{
+			name:          "ledgers of another's account",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "alice"}), filter: &entities.LedgerFilter{Account: "bob456"}},
+			authorization: true,
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId("bob456")).Return(&entities.Account{AccountId: "bob456", Owner: "bob"}, nil)
+			},
+			wantErr: xerrors.Errorf("%s: access denied for %s", "bob456", "alice"),
+		},
+		{
+			name:          "ledgers of own account",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "alice"}), filter: &entities.LedgerFilter{Account: "alice123"}},
+			authorization: true,
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId("alice123")).Return(&entities.Account{AccountId: "alice123", Owner: "alice"}, nil)
+				mockLedgers.EXPECT().List(a.ctx, a.filter).Return(testPage, nil)
+			},
+			want: testPage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{Accounts: mockAccounts, Ledgers: mockLedgers, authorization: tt.authorization}
+			tt.before(&tt.args)
+			got, err := w.LedgersList(tt.args.ctx, tt.args.filter)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Errorf("LedgersList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("LedgersList() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_GetLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	testError := xerrors.New("test_error")
+	testLedger := &entities.Ledger{Id: "e9b0c72f-c08f-4e00-b158-42ae88f0c18e", CreatedAt: time.Now(), Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: entities.NewMoney(decimal.NewFromFloat(-4.124), "USD"), ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(4.124), "USD"), FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
+	type args struct {
+		ctx      context.Context
+		ledgerId string
+	}
+	tests := []struct {
+		name          string
+		args          args
+		authorization bool
+		before        func(*args)
+		want          *entities.Ledger
+		wantErr       error
+	}{
+		{
+			name: "return error",
+			args: args{ctx: context.Background(), ledgerId: testLedger.Id},
+			before: func(a *args) {
+				mockLedgers.EXPECT().GetByGuid(a.ctx, a.ledgerId).Return(nil, testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "ledger not found",
+			args: args{ctx: context.Background(), ledgerId: "123"},
+			before: func(a *args) {
+				mockLedgers.EXPECT().GetByGuid(a.ctx, a.ledgerId).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: xerrors.Errorf("ledger %s not found", "123"),
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), ledgerId: testLedger.Id},
+			before: func(a *args) {
+				mockLedgers.EXPECT().GetByGuid(a.ctx, a.ledgerId).Return(testLedger, nil)
+			},
+			want: testLedger,
+		},
+		{
+			name:          "principal uses neither side of ledger",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "carol"}), ledgerId: testLedger.Id},
+			authorization: true,
+			before: func(a *args) {
+				mockLedgers.EXPECT().GetByGuid(a.ctx, a.ledgerId).Return(testLedger, nil)
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId("alice123")).Return(&entities.Account{AccountId: "alice123", Owner: "alice"}, nil)
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId("bob456")).Return(&entities.Account{AccountId: "bob456", Owner: "bob"}, nil)
+			},
+			wantErr: xerrors.Errorf("%s: access denied for %s", "bob456", "carol"),
+		},
+		{
+			name:          "receiver sees ledger",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "bob"}), ledgerId: testLedger.Id},
+			authorization: true,
+			before: func(a *args) {
+				mockLedgers.EXPECT().GetByGuid(a.ctx, a.ledgerId).Return(testLedger, nil)
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId("alice123")).Return(&entities.Account{AccountId: "alice123", Owner: "alice"}, nil)
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId("bob456")).Return(&entities.Account{AccountId: "bob456", Owner: "bob"}, nil)
+			},
+			want: testLedger,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{Accounts: mockAccounts, Ledgers: mockLedgers, authorization: tt.authorization}
+			tt.before(&tt.args)
+
+			got, err := w.GetLedger(tt.args.ctx, tt.args.ledgerId)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetLedger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetLedger() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_AccountLedgers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	testError := xerrors.New("test_error")
+	testAmount := entities.NewMoney(decimal.NewFromFloat(4.124), "USD")
+	testAccount := &entities.Account{AccountId: "bob456", Balance: testAmount.Amount, Currency: "USD", Status: entities.AccountActive}
+	testPage := &entities.PaymentsPage{
+		Payments: entities.Payments{
+			&entities.Payment{Account: "bob456", Amount: testAmount, FromAccount: "alice123", Direction: entities.Incoming, BalanceAfter: &testAmount},
+		},
+		NextCursor: "cursor",
+	}
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+		page      *entities.Pagination
 	}
 	tests := []struct {
 		name    string
 		before  func(*args)
 		args    args
-		want    entities.Ledgers
+		want    *entities.PaymentsPage
 		wantErr error
 	}{
+		{
+			name:    "limit is too big",
+			args:    args{ctx: context.Background(), accountId: "bob456", page: &entities.Pagination{Limit: entities.MaxPageSize + 1}},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("limit should be from 1 to %d", entities.MaxPageSize),
+		},
+		{
+			name: "account not found",
+			args: args{ctx: context.Background(), accountId: "bob4567", page: &entities.Pagination{}},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: xerrors.Errorf("account %s not found", "bob4567"),
+		},
 		{
 			name: "return error",
-			args: args{ctx: context.Background()},
+			args: args{ctx: context.Background(), accountId: "bob456", page: &entities.Pagination{}},
 			before: func(a *args) {
-				mockLedgers.EXPECT().List(a.ctx).Return(nil, testError)
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+				mockLedgers.EXPECT().ListByAccount(a.ctx, testAccount, a.page).Return(nil, testError)
 			},
 			wantErr: testError,
 		},
 		{
 			name: "works fine",
-			args: args{ctx: context.Background()},
+			args: args{ctx: context.Background(), accountId: "bob456", page: &entities.Pagination{Limit: 1}},
 			before: func(a *args) {
-				mockLedgers.EXPECT().List(a.ctx).Return(testLedgers, nil)
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+				mockLedgers.EXPECT().ListByAccount(a.ctx, testAccount, a.page).Return(testPage, nil)
 			},
-			want: testLedgers,
+			want: testPage,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			w := &WalletService{Ledgers: mockLedgers}
+			w := &WalletService{Accounts: mockAccounts, Ledgers: mockLedgers}
 			tt.before(&tt.args)
-			got, err := w.LedgersList(tt.args.ctx)
-			if err != nil && !xerrors.Is(err, tt.wantErr) || tt.wantErr != nil && err == nil {
-				t.Errorf("PaymentsList() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := w.AccountLedgers(tt.args.ctx, tt.args.accountId, tt.args.page)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Errorf("AccountLedgers() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if len(got) != len(tt.want) {
-				t.Fatalf("PaymentsList() got = %d, want %d length", len(got), len(tt.want))
-			}
-
-			for i := range got {
-				if !reflect.DeepEqual(got[i], tt.want[i]) {
-					t.Fatalf("PaymentsList() got = %v, want %v", got[i], tt.want[i])
-				}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("AccountLedgers() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -231,6 +646,10 @@ func TestWalletService_Send(t *testing.T) {
 
 	mockAccounts := mock.NewMockAccounts(ctrl)
 	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+	mockIdempotencyKeys := mock.NewMockIdempotencyKeys(ctrl)
+	mockQuotes := mock.NewMockQuotes(ctrl)
+	mockFXRates := mock.NewMockFXRates(ctrl)
 
 	beginError := xerrors.New("begin_tx_error")
 
@@ -243,28 +662,82 @@ func TestWalletService_Send(t *testing.T) {
 	updateBalanceError2 := xerrors.New("update_balance_error_2")
 
 	addLedgerError := xerrors.New("add_ledger_error")
+	addEventError := xerrors.New("add_event_error")
 	commitError := &pq.Error{}
 
+	getByKeyTxError := xerrors.New("get_by_key_error")
+	addKeyError := xerrors.New("add_key_error")
+	rateError := xerrors.New("rate_error")
+
+	testLedger := &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
 	type args struct {
-		ctx      context.Context
-		creditId string
-		debitId  string
-		amount   decimal.Decimal
+		ctx            context.Context
+		idempotencyKey string
+		quoteId        string
+		creditId       string
+		debitId        string
+		amount         entities.Money
 	}
 	tests := []struct {
-		name    string
-		args    args
-		before  func(*args)
-		want    *entities.Ledger
-		wantErr error
+		name          string
+		args          args
+		fxRates       services.FXRates
+		authorization bool
+		before        func(*args)
+		want          *entities.Ledger
+		wantErr       error
 	}{
+		{
+			name: "system account can't be used",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "settlement:USD",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("%s: system account can't be used in payments", "settlement:USD"),
+		},
+		{
+			name: "amount isn't positive",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(-3.21), "USD"),
+			},
+			before:  func(a *args) {},
+			wantErr: xerrors.New("amount should be positive"),
+		},
+		{
+			name: "amount is more precise than currency",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.RequireFromString("6.00013"), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.New("amount 6.00013 has more than 2 decimal places of USD"),
+		},
 		{
 			name: "BeginTx returns error",
 			args: args{
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin().WillReturnError(beginError)
@@ -277,10 +750,11 @@ func TestWalletService_Send(t *testing.T) {
 				ctx:      context.Background(),
 				creditId: "alice1234",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(nil, sql.ErrNoRows)
 
 				dbmock.ExpectRollback()
@@ -293,10 +767,11 @@ func TestWalletService_Send(t *testing.T) {
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(nil, getByNameTxError)
 
 				dbmock.ExpectRollback()
@@ -309,94 +784,474 @@ func TestWalletService_Send(t *testing.T) {
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
-					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(1.21), Currency: "USD"}, nil)
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(1.21), AvailableBalance: decimal.NewFromFloat(1.21), Currency: "USD", Status: entities.AccountActive}, nil)
 
 				dbmock.ExpectRollback()
 			},
 			wantErr: xerrors.Errorf("%s: don't have enough balance", "alice123"),
 		},
 		{
-			name: "debitId not found",
+			name: "credit's overdraft is expired",
 			args: args{
 				ctx:      context.Background(),
 				creditId: "alice123",
-				debitId:  "bob4567",
-				amount:   decimal.NewFromFloat(3.21),
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
-				dbmock.ExpectBegin()
+				overdraft, expiresAt := decimal.NewFromFloat(100), time.Now().Add(-time.Hour)
 
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
-					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}, nil)
-				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
-					Return(nil, sql.ErrNoRows)
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(1.21), AvailableBalance: decimal.NewFromFloat(1.21), Currency: "USD", Status: entities.AccountActive,
+						OverdraftLimit: &overdraft, OverdraftExpiresAt: &expiresAt}, nil)
 
 				dbmock.ExpectRollback()
 			},
-			wantErr: xerrors.Errorf("account %s not found", "bob4567"),
+			wantErr: xerrors.Errorf("%s: don't have enough balance", "alice123"),
 		},
 		{
-			name: "get debitId returns error",
+			name: "credit's overdraft covers amount",
 			args: args{
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
+				overdraft, expiresAt := decimal.NewFromFloat(2), time.Now().Add(time.Hour)
+
 				dbmock.ExpectBegin()
 
-				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
-					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}, nil)
-				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
-					Return(nil, getByNameTxError2)
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(1.21), AvailableBalance: decimal.NewFromFloat(1.21), Currency: "USD", Status: entities.AccountActive,
+					OverdraftLimit: &overdraft, OverdraftExpiresAt: &expiresAt}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
 
-				dbmock.ExpectRollback()
-			},
-			wantErr: getByNameTxError2,
-		},
-		{
-			name: "currencies is not equal",
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(testLedger, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name: "amount without currency is in credit's currency",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), ""),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
+				amount := entities.NewMoney(a.amount.Amount, "USD")
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, amount).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, amount, entities.Transfer, nil).Return(testLedger, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name: "amount in another currency",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "EUR"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: entities.ErrCurrencyMismatch,
+		},
+		{
+			name: "credit account is not active",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountFrozen}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: account is %s", "alice123", entities.AccountFrozen),
+		},
+		{
+			name: "debitId not found",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob4567",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(nil, sql.ErrNoRows)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("account %s not found", "bob4567"),
+		},
+		{
+			name: "get debitId returns error",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(nil, getByNameTxError2)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: getByNameTxError2,
+		},
+		{
+			name: "debit account is not active",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountClosed}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: account is %s", "bob456", entities.AccountClosed),
+		},
+		{
+			name: "currencies is not equal",
 			args: args{
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
-					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}, nil)
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
-					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), Currency: "EUR"}, nil)
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "EUR", Status: entities.AccountActive}, nil)
 
 				dbmock.ExpectRollback()
 			},
 			wantErr: xerrors.New("currencies for accounts is not equal"),
 		},
 		{
-			name: "account table lock returns error",
+			name:    "fx rate returns error",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "EUR", Status: entities.AccountActive}, nil)
+
+				mockFXRates.EXPECT().Rate(a.ctx, entities.Currency("USD"), entities.Currency("EUR")).Return(nil, rateError)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: rateError,
+		},
+		{
+			name:    "quote not found",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				quoteId:  "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "EUR", Status: entities.AccountActive}, nil)
+
+				mockQuotes.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.quoteId).Return(nil, sql.ErrNoRows)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("quote %s not found", "c5417ca1-c06b-4a45-9cd9-85936d4b9665"),
+		},
+		{
+			name:    "quote is for another currency pair",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				quoteId:  "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "EUR", Status: entities.AccountActive}, nil)
+
+				mockQuotes.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.quoteId).Return(&entities.FXQuote{
+					Id:        a.quoteId,
+					FXRate:    entities.FXRate{From: "EUR", To: "USD", Rate: decimal.RequireFromString("1.1")},
+					ExpiresAt: time.Now().Add(time.Minute),
+				}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("quote %s is for %s/%s, not for %s/%s", "c5417ca1-c06b-4a45-9cd9-85936d4b9665", "EUR", "USD", "USD", "EUR"),
+		},
+		{
+			name:    "quote is expired",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				quoteId:  "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "EUR", Status: entities.AccountActive}, nil)
+
+				mockQuotes.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.quoteId).Return(&entities.FXQuote{
+					Id:        a.quoteId,
+					FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.9")},
+					ExpiresAt: time.Now().Add(-time.Second),
+				}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("quote %s is expired", "c5417ca1-c06b-4a45-9cd9-85936d4b9665"),
+		},
+		{
+			name:    "converts with current rate",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.RequireFromString("3"), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "EUR", Status: entities.AccountActive}
+				rate := &entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.9")}
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockFXRates.EXPECT().Rate(a.ctx, credit.Currency, debit.Currency).Return(rate, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, entities.NewMoney(rate.Convert(a.amount.Amount).TargetAmount, "EUR")).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, rate.Convert(a.amount.Amount)).Return(testLedger, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name:    "rounds converted amount to minor unit of receiver's currency",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.RequireFromString("3.33"), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "SGD", Status: entities.AccountActive}
+				rate := &entities.FXRate{From: "USD", To: "SGD", Rate: decimal.RequireFromString("1.3774")}
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockFXRates.EXPECT().Rate(a.ctx, credit.Currency, debit.Currency).Return(rate, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, gomock.Any()).
+					DoAndReturn(func(_ *sql.Tx, _ context.Context, _ *entities.Account, target entities.Money) error {
+						if !target.Equal(entities.NewMoney(decimal.RequireFromString("4.59"), "SGD")) {
+							t.Fatalf("UpdateBalanceTx() got unrounded target %s", target)
+						}
+						return nil
+					})
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, gomock.Any()).
+					DoAndReturn(func(_ *sql.Tx, _ context.Context, _, _ *entities.Account, _ entities.Money, _ entities.PaymentType, fx *entities.Conversion) (*entities.Ledger, error) {
+						if !fx.TargetAmount.Equal(decimal.RequireFromString("4.59")) || !fx.Rate.Equal(fx.TargetAmount.Div(fx.SourceAmount)) {
+							t.Fatalf("AddTx() got unexpected conversion %v", fx)
+						}
+						return testLedger, nil
+					})
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name:    "converted amount is less than minor unit",
+			fxRates: mockFXRates,
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.RequireFromString("0.01"), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "JPY", Status: entities.AccountActive}
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockFXRates.EXPECT().Rate(a.ctx, credit.Currency, debit.Currency).
+					Return(&entities.FXRate{From: "USD", To: "JPY", Rate: decimal.RequireFromString("1.07")}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.New("amount 0.01 USD is too small to convert to JPY"),
+		},
+		{
+			name: "converts with quoted rate",
 			args: args{
 				ctx:      context.Background(),
+				quoteId:  "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.RequireFromString("3"), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
 
-				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}
-				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), Currency: "USD"}
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "EUR", Status: entities.AccountActive}
+				quote := &entities.FXQuote{
+					Id:        a.quoteId,
+					FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.88")},
+					ExpiresAt: time.Now().Add(time.Minute),
+				}
 
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
 
-				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx).Return(lockError)
+				mockQuotes.EXPECT().GetByIdTx(gomock.Any(), a.ctx, a.quoteId).Return(quote, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, entities.NewMoney(quote.Convert(a.amount.Amount).TargetAmount, "EUR")).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, quote.Convert(a.amount.Amount)).Return(testLedger, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name: "accounts lock returns error before accounts are read",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(lockError)
 
 				dbmock.ExpectRollback()
 			},
@@ -408,19 +1263,18 @@ func TestWalletService_Send(t *testing.T) {
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
 
-				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}
-				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), Currency: "USD"}
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
 
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
 
-				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx).Return(nil)
-
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(updateBalanceError)
 
 				dbmock.ExpectRollback()
@@ -433,19 +1287,18 @@ func TestWalletService_Send(t *testing.T) {
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
 
-				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}
-				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), Currency: "USD"}
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
 
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
 
-				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx).Return(nil)
-
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(updateBalanceError2)
 
@@ -459,23 +1312,22 @@ func TestWalletService_Send(t *testing.T) {
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
 
-				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}
-				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), Currency: "USD"}
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
 
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
 
-				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx).Return(nil)
-
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
 
-				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount).Return(nil, addLedgerError)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(nil, addLedgerError)
 
 				dbmock.ExpectRollback()
 
@@ -483,59 +1335,300 @@ func TestWalletService_Send(t *testing.T) {
 			wantErr: addLedgerError,
 		},
 		{
-			name: "commit returns error",
+			name: "add event returns error",
 			args: args{
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
 
-				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}
-				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), Currency: "USD"}
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
+				ledger := &entities.Ledger{Id: "e9b0c72f-c08f-4e00-b158-42ae88f0c18e"}
 
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
 
-				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx).Return(nil)
-
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
 
-				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount).Return(nil, nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(ledger, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, ledger).Return(nil, addEventError)
 
-				dbmock.ExpectCommit().WillReturnError(commitError)
+				dbmock.ExpectRollback()
 			},
-			wantErr: commitError,
+			wantErr: addEventError,
 		},
 		{
-			name: "commit works fine",
+			name: "commit returns error",
 			args: args{
 				ctx:      context.Background(),
 				creditId: "alice123",
 				debitId:  "bob456",
-				amount:   decimal.NewFromFloat(3.21),
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
 			},
 			before: func(a *args) {
 				dbmock.ExpectBegin()
 
-				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), Currency: "USD"}
-				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), Currency: "USD"}
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
 
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
 				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
 
-				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx).Return(nil)
-
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
 				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
 
-				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount).Return(nil, nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(nil, nil)
 
-				dbmock.ExpectCommit()
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, gomock.Nil()).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit().WillReturnError(commitError)
 			},
+			wantErr: commitError,
+		},
+		{
+			name: "commit works fine",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
+
+				//rows are locked before they are read and checked
+				gomock.InOrder(
+					mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil),
+					mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil),
+					mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil),
+				)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(nil, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, gomock.Nil()).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+		},
+		{
+			name: "get idempotency key returns error",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(nil, getByKeyTxError)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: getByKeyTxError,
+		},
+		{
+			name: "idempotency key used with another request",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				stored := entities.NewIdempotencyKey(a.idempotencyKey, a.quoteId, a.creditId, a.debitId, entities.NewMoney(a.amount.Amount.Add(decimal.New(1, 0)), "USD"))
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(stored, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("idempotency key %s was used with another request", "key"),
+		},
+		{
+			name: "idempotency key replays stored ledger",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				stored := entities.NewIdempotencyKey(a.idempotencyKey, a.quoteId, a.creditId, a.debitId, a.amount)
+				stored.Ledger = testLedger
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(stored, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name: "idempotency key replays stored ledger to amount with trailing zeros",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.RequireFromString("3.210"), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				stored := entities.NewIdempotencyKey(a.idempotencyKey, a.quoteId, a.creditId, a.debitId, entities.NewMoney(decimal.RequireFromString("3.21"), "USD"))
+				stored.Ledger = testLedger
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(stored, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+		{
+			name: "idempotency key used with another currency",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				stored := entities.NewIdempotencyKey(a.idempotencyKey, a.quoteId, a.creditId, a.debitId, entities.NewMoney(a.amount.Amount, "EUR"))
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(stored, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("idempotency key %s was used with another request", "key"),
+		},
+		{
+			name: "idempotency key used with another quote",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				stored := entities.NewIdempotencyKey(a.idempotencyKey, "quote", a.creditId, a.debitId, a.amount)
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(stored, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("idempotency key %s was used with another request", "key"),
+		},
+		{
+			name: "idempotency key isn't replayed to another principal",
+			args: args{
+				ctx:            entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "carol"}),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			authorization: true,
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				stored := entities.NewIdempotencyKey(a.idempotencyKey, a.quoteId, a.creditId, a.debitId, a.amount)
+				stored.Ledger = testLedger
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(stored, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(&entities.Account{AccountId: "alice123", Owner: "alice"}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: access denied for %s", "alice123", "carol"),
+		},
+		{
+			name: "add idempotency key returns error",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
+
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(nil, sql.ErrNoRows)
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(testLedger, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				mockIdempotencyKeys.EXPECT().AddTx(gomock.Any(), a.ctx, gomock.Any()).Return(addKeyError)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: addKeyError,
+		},
+		{
+			name: "commit works fine with idempotency key",
+			args: args{
+				ctx:            context.Background(),
+				idempotencyKey: "key",
+				creditId:       "alice123",
+				debitId:        "bob456",
+				amount:         entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+
+				credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
+
+				mockIdempotencyKeys.EXPECT().GetByKeyTx(gomock.Any(), a.ctx, a.idempotencyKey, gomock.Any()).Return(nil, sql.ErrNoRows)
+
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.debitId)).Return(debit, nil)
+
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Transfer, nil).Return(testLedger, nil)
+
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				ikey := entities.NewIdempotencyKey(a.idempotencyKey, a.quoteId, a.creditId, a.debitId, a.amount)
+				ikey.Ledger = testLedger
+				mockIdempotencyKeys.EXPECT().AddTx(gomock.Any(), a.ctx, ikey).Return(nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
 		},
 	}
 	for _, tt := range tests {
@@ -544,9 +1637,15 @@ func TestWalletService_Send(t *testing.T) {
 				db:       db,
 				Accounts: mockAccounts,
 				Ledgers:  mockLedgers,
+				Outbox:   mockOutbox,
+
+				IdempotencyKeys: mockIdempotencyKeys,
+				Quotes:          mockQuotes,
+				fxRates:         tt.fxRates,
+				authorization:   tt.authorization,
 			}
 			tt.before(&tt.args)
-			got, err := w.Send(tt.args.ctx, tt.args.creditId, tt.args.debitId, tt.args.amount)
+			got, err := w.Send(tt.args.ctx, tt.args.idempotencyKey, tt.args.quoteId, tt.args.creditId, tt.args.debitId, tt.args.amount)
 			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
 				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -558,3 +1657,947 @@ func TestWalletService_Send(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletService_SendSingleStatement(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+	mockLimits := mock.NewMockLimits(ctrl)
+
+	transferError := xerrors.New("transfer_error")
+	addEventError := xerrors.New("add_event_error")
+
+	credit := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+	debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(12.21), AvailableBalance: decimal.NewFromFloat(12.21), Currency: "USD", Status: entities.AccountActive}
+
+	testLedger := &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "alice123", Amount: entities.NewMoney(decimal.NewFromFloat(-3.21), "USD"), ToAccount: "bob456", Direction: entities.Outgoing},
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), FromAccount: "alice123", Direction: entities.Incoming},
+	}}
+
+	type args struct {
+		ctx      context.Context
+		creditId string
+		debitId  string
+		amount   entities.Money
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Ledger
+		wantErr error
+	}{
+		{
+			name: "TransferTx returns error",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().TransferTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId), a.amount).
+					Return(nil, transferError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: transferError,
+		},
+		{
+			name: "transfer isn't made and regular way reports low balance",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(30.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().TransferTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId), a.amount).
+					Return(&entities.TransferAttempt{Credit: credit, Debit: debit}, nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: don't have enough balance", "alice123"),
+		},
+		{
+			name: "transfer of limited account isn't made and regular way reports exceeded limit",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().TransferTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId), a.amount).
+					Return(&entities.TransferAttempt{Credit: credit, Debit: debit}, nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId)).Return(credit, nil)
+				mockLimits.EXPECT().GetTx(gomock.Any(), a.ctx, credit).Return(&entities.Limits{MaxAmount: amountOf("3")}, nil)
+				mockLimits.EXPECT().UsageTx(gomock.Any(), a.ctx, credit, gomock.Any()).Return(&entities.LimitsUsage{}, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: amount 3.21 exceeds single payment limit 3", "alice123"),
+		},
+		{
+			name: "amount is more precise than currency",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.RequireFromString("3.2101"), ""),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().TransferTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId), a.amount).
+					Return(&entities.TransferAttempt{Credit: credit, Debit: debit, Ledger: testLedger}, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.New("amount 3.2101 has more than 2 decimal places of USD"),
+		},
+		{
+			name: "add event returns error",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().TransferTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId), a.amount).
+					Return(&entities.TransferAttempt{Credit: credit, Debit: debit, Ledger: testLedger}, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(nil, addEventError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: addEventError,
+		},
+		{
+			name: "commit works fine",
+			args: args{
+				ctx:      context.Background(),
+				creditId: "alice123",
+				debitId:  "bob456",
+				amount:   entities.NewMoney(decimal.NewFromFloat(3.21), "USD"),
+			},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockLedgers.EXPECT().TransferTx(gomock.Any(), a.ctx, entities.AccountId(a.creditId), entities.AccountId(a.debitId), a.amount).
+					Return(&entities.TransferAttempt{Credit: credit, Debit: debit, Ledger: testLedger}, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{
+				db:              db,
+				singleStatement: true,
+				Accounts:        mockAccounts,
+				Ledgers:         mockLedgers,
+				Outbox:          mockOutbox,
+				Limits:          mockLimits,
+			}
+			tt.before(&tt.args)
+			got, err := w.Send(tt.args.ctx, "", "", tt.args.creditId, tt.args.debitId, tt.args.amount)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Send() got = %v, want %v", got, tt.want)
+			}
+
+			if err := dbmock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("Send() unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestWalletService_Deposit(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+
+	testLedger := &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "settlement:USD", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), ToAccount: "bob456", Direction: entities.Outgoing, Type: entities.Deposit},
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), FromAccount: "settlement:USD", Direction: entities.Incoming, Type: entities.Deposit},
+	}}
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+		amount    entities.Money
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Ledger
+		wantErr error
+	}{
+		{
+			name:    "deposit to system account",
+			args:    args{ctx: context.Background(), accountId: "settlement:USD", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("%s: system account can't be used in payments", "settlement:USD"),
+		},
+		{
+			name: "account is not active",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "bob456", Currency: "USD", Status: entities.AccountClosed}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: account is %s", "bob456", entities.AccountClosed),
+		},
+		{
+			name: "amount in another currency",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "bob456", Currency: "EUR", Status: entities.AccountActive}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: entities.ErrCurrencyMismatch,
+		},
+		{
+			name: "settlement account not found",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "bob456", Currency: "EUR", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("settlement:EUR")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("settlement:EUR")).
+					Return(nil, sql.ErrNoRows)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("account %s not found", "settlement:EUR"),
+		},
+		{
+			name: "negative settlement balance is fine",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				debit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}
+				credit := &entities.Account{AccountId: "settlement:USD", Balance: decimal.NewFromFloat(-10), AvailableBalance: decimal.NewFromFloat(-10), Currency: "USD", Status: entities.AccountActive}
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(debit, nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("settlement:USD")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("settlement:USD")).Return(credit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Deposit, nil).Return(testLedger, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Accounts: mockAccounts, Ledgers: mockLedgers, Outbox: mockOutbox}
+			tt.before(&tt.args)
+			got, err := w.Deposit(tt.args.ctx, "", tt.args.accountId, tt.args.amount)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Deposit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Deposit() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_Withdraw(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	mockLedgers := mock.NewMockLedgers(ctrl)
+	mockOutbox := mock.NewMockOutbox(ctrl)
+
+	testLedger := &entities.Ledger{Payments: [2]*entities.Payment{
+		&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), ToAccount: "settlement:USD", Direction: entities.Outgoing, Type: entities.Withdrawal},
+		&entities.Payment{Account: "settlement:USD", Amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD"), FromAccount: "bob456", Direction: entities.Incoming, Type: entities.Withdrawal},
+	}}
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+		amount    entities.Money
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Ledger
+		wantErr error
+	}{
+		{
+			name: "balance less than amount",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1), AvailableBalance: decimal.NewFromFloat(1), Currency: "USD", Status: entities.AccountActive}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: don't have enough balance", "bob456"),
+		},
+		{
+			name: "settlement account is frozen",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10), AvailableBalance: decimal.NewFromFloat(10), Currency: "USD", Status: entities.AccountActive}, nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("settlement:USD")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("settlement:USD")).
+					Return(&entities.Account{AccountId: "settlement:USD", Currency: "USD", Status: entities.AccountFrozen}, nil)
+
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: account is %s", "settlement:USD", entities.AccountFrozen),
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), accountId: "bob456", amount: entities.NewMoney(decimal.NewFromFloat(3.21), "USD")},
+			before: func(a *args) {
+				credit := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(10), AvailableBalance: decimal.NewFromFloat(10), Currency: "USD", Status: entities.AccountActive}
+				debit := &entities.Account{AccountId: "settlement:USD", Balance: decimal.NewFromFloat(-10), AvailableBalance: decimal.NewFromFloat(-10), Currency: "USD", Status: entities.AccountActive}
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(credit, nil)
+				mockAccounts.EXPECT().LockTx(gomock.Any(), a.ctx, entities.AccountId("settlement:USD")).Return(nil)
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId("settlement:USD")).Return(debit, nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, credit, a.amount.Neg()).Return(nil)
+				mockAccounts.EXPECT().UpdateBalanceTx(gomock.Any(), a.ctx, debit, a.amount).Return(nil)
+				mockLedgers.EXPECT().AddTx(gomock.Any(), a.ctx, credit, debit, a.amount, entities.Withdrawal, nil).Return(testLedger, nil)
+				mockOutbox.EXPECT().AddTx(gomock.Any(), a.ctx, entities.PaymentCompleted, testLedger).Return(&entities.Event{}, nil)
+
+				dbmock.ExpectCommit()
+			},
+			want: testLedger,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Accounts: mockAccounts, Ledgers: mockLedgers, Outbox: mockOutbox}
+			tt.before(&tt.args)
+			got, err := w.Withdraw(tt.args.ctx, "", tt.args.accountId, tt.args.amount)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Withdraw() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Withdraw() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_CreateAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	testError := xerrors.New("test_error")
+	testAccount := &entities.Account{AccountId: "carol789", Balance: decimal.Zero, Currency: "USD", Status: entities.AccountActive}
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+		currency  string
+	}
+	tests := []struct {
+		name          string
+		args          args
+		authorization bool
+		before        func(*args)
+		want          *entities.Account
+		wantErr       error
+	}{
+		{
+			name:    "reserved account id",
+			args:    args{ctx: context.Background(), accountId: "settlement:USD", currency: "USD"},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("account id %s is reserved", "settlement:USD"),
+		},
+		{
+			name:    "unknown currency",
+			args:    args{ctx: context.Background(), accountId: "carol789", currency: "XYZ"},
+			before:  func(a *args) {},
+			wantErr: xerrors.Errorf("currency %s is unknown", "XYZ"),
+		},
+		{
+			name: "settlement account creation error",
+			args: args{ctx: context.Background(), accountId: "carol789", currency: "USD"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().CreateSettlement(a.ctx, entities.Currency(a.currency)).Return(testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "return error",
+			args: args{ctx: context.Background(), accountId: "carol789", currency: "USD"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().CreateSettlement(a.ctx, entities.Currency(a.currency)).Return(nil)
+				mockAccounts.EXPECT().Create(a.ctx, &entities.Account{AccountId: "carol789", Currency: "USD", Status: entities.AccountActive}).
+					Return(nil, testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "account already exists",
+			args: args{ctx: context.Background(), accountId: "alice123", currency: "USD"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().CreateSettlement(a.ctx, entities.Currency(a.currency)).Return(nil)
+				mockAccounts.EXPECT().Create(a.ctx, gomock.Any()).Return(nil, &pq.Error{Code: uniqueViolation})
+			},
+			wantErr: xerrors.Errorf("account %s already exists", "alice123"),
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), accountId: "carol789", currency: "USD"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().CreateSettlement(a.ctx, entities.Currency(a.currency)).Return(nil)
+				mockAccounts.EXPECT().Create(a.ctx, &entities.Account{AccountId: "carol789", Currency: "USD", Status: entities.AccountActive}).
+					Return(testAccount, nil)
+			},
+			want: testAccount,
+		},
+		{
+			name:          "authorization without principal",
+			args:          args{ctx: context.Background(), accountId: "carol789", currency: "USD"},
+			authorization: true,
+			before:        func(a *args) {},
+			wantErr:       xerrors.New("authentication required"),
+		},
+		{
+			name:          "owned by principal",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "carol"}), accountId: "carol789", currency: "USD"},
+			authorization: true,
+			before: func(a *args) {
+				mockAccounts.EXPECT().CreateSettlement(a.ctx, entities.Currency(a.currency)).Return(nil)
+				mockAccounts.EXPECT().Create(a.ctx, &entities.Account{AccountId: "carol789", Currency: "USD", Status: entities.AccountActive, Owner: "carol"}).
+					Return(testAccount, nil)
+			},
+			want: testAccount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{Accounts: mockAccounts, authorization: tt.authorization}
+			tt.before(&tt.args)
+
+			got, err := w.CreateAccount(tt.args.ctx, tt.args.accountId, tt.args.currency)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("CreateAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CreateAccount() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_GetAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	testError := xerrors.New("test_error")
+	testAccount := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(4.124), AvailableBalance: decimal.NewFromFloat(4.124), Currency: "USD", Status: entities.AccountActive}
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+	}
+	tests := []struct {
+		name          string
+		args          args
+		authorization bool
+		before        func(*args)
+		want          *entities.Account
+		wantErr       error
+	}{
+		{
+			name: "return error",
+			args: args{ctx: context.Background(), accountId: "alice123"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(nil, testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "account not found",
+			args: args{ctx: context.Background(), accountId: "alice1234"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: xerrors.Errorf("account %s not found", "alice1234"),
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), accountId: "alice123"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+			},
+			want: testAccount,
+		},
+		{
+			name:          "account of another principal",
+			args:          args{ctx: entities.ContextWithPrincipal(context.Background(), &entities.Principal{Subject: "bob"}), accountId: "alice123"},
+			authorization: true,
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+			},
+			wantErr: xerrors.Errorf("%s: access denied for %s", "alice123", "bob"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{Accounts: mockAccounts, authorization: tt.authorization}
+			tt.before(&tt.args)
+
+			got, err := w.GetAccount(tt.args.ctx, tt.args.accountId)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("GetAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("GetAccount() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_BalanceAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+	testError := xerrors.New("test_error")
+	testAccount := &entities.Account{AccountId: "bob456", Balance: decimal.NewFromFloat(1000.5), AvailableBalance: decimal.NewFromFloat(1000.5), Currency: "USD", Status: entities.AccountActive}
+	testAt := time.Date(2019, 7, 31, 23, 59, 59, 0, time.UTC)
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+		at        time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.AccountBalance
+		wantErr error
+	}{
+		{
+			name:    "at is in the future",
+			args:    args{ctx: context.Background(), accountId: "bob456", at: time.Date(2119, 7, 31, 0, 0, 0, 0, time.UTC)},
+			before:  func(*args) {},
+			wantErr: xerrors.Errorf("balance at %s is in the future", "2119-07-31T00:00:00Z"),
+		},
+		{
+			name: "account not found",
+			args: args{ctx: context.Background(), accountId: "bob4567", at: testAt},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: xerrors.Errorf("account %s not found", "bob4567"),
+		},
+		{
+			name: "BalanceAt returns error",
+			args: args{ctx: context.Background(), accountId: "bob456", at: testAt},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+				mockAccounts.EXPECT().BalanceAt(a.ctx, testAccount, a.at).Return(entities.NewMoney(decimal.Zero, "USD"), testError)
+			},
+			wantErr: testError,
+		},
+		{
+			name: "zero at means now",
+			args: args{ctx: context.Background(), accountId: "bob456"},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+				mockAccounts.EXPECT().BalanceAt(a.ctx, testAccount, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ *entities.Account, at time.Time) (entities.Money, error) {
+						a.at = at
+						return entities.NewMoney(testAccount.Balance, "USD"), nil
+					})
+			},
+			want: &entities.AccountBalance{AccountId: "bob456", Balance: testAccount.Balance, Currency: "USD"},
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), accountId: "bob456", at: testAt},
+			before: func(a *args) {
+				mockAccounts.EXPECT().GetByName(a.ctx, entities.AccountId(a.accountId)).Return(testAccount, nil)
+				mockAccounts.EXPECT().BalanceAt(a.ctx, testAccount, a.at).Return(entities.NewMoney(decimal.NewFromFloat(995.5), "USD"), nil)
+			},
+			want: &entities.AccountBalance{AccountId: "bob456", Balance: decimal.NewFromFloat(995.5), Currency: "USD", At: testAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{Accounts: mockAccounts}
+			tt.before(&tt.args)
+
+			got, err := w.BalanceAt(tt.args.ctx, tt.args.accountId, tt.args.at)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("BalanceAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want != nil && tt.want.At.IsZero() {
+				if got.At.IsZero() || !got.At.Equal(tt.args.at) {
+					t.Fatalf("BalanceAt() got at = %v, want now", got.At)
+				}
+				tt.want.At = got.At
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BalanceAt() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_Quote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuotes := mock.NewMockQuotes(ctrl)
+	mockFXRates := mock.NewMockFXRates(ctrl)
+
+	rateError := xerrors.New("rate_error")
+	createError := xerrors.New("create_error")
+	testRate := &entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.9")}
+	testQuote := &entities.FXQuote{Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", FXRate: *testRate}
+
+	type args struct {
+		ctx  context.Context
+		from string
+		to   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.FXQuote
+		wantErr error
+	}{
+		{
+			name:    "same currencies",
+			args:    args{ctx: context.Background(), from: "USD", to: "USD"},
+			before:  func(a *args) {},
+			wantErr: xerrors.New("quote requires two different currencies"),
+		},
+		{
+			name: "rate returns error",
+			args: args{ctx: context.Background(), from: "USD", to: "EUR"},
+			before: func(a *args) {
+				mockFXRates.EXPECT().Rate(a.ctx, entities.Currency(a.from), entities.Currency(a.to)).Return(nil, rateError)
+			},
+			wantErr: rateError,
+		},
+		{
+			name: "create returns error",
+			args: args{ctx: context.Background(), from: "USD", to: "EUR"},
+			before: func(a *args) {
+				mockFXRates.EXPECT().Rate(a.ctx, entities.Currency(a.from), entities.Currency(a.to)).Return(testRate, nil)
+				mockQuotes.EXPECT().Create(a.ctx, gomock.Any()).Return(nil, createError)
+			},
+			wantErr: createError,
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), from: "USD", to: "EUR"},
+			before: func(a *args) {
+				mockFXRates.EXPECT().Rate(a.ctx, entities.Currency(a.from), entities.Currency(a.to)).Return(testRate, nil)
+				mockQuotes.EXPECT().Create(a.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, q *entities.FXQuote) (*entities.FXQuote, error) {
+					if q.FXRate != *testRate || q.Expired(time.Now()) {
+						t.Fatalf("Create() got unexpected quote %v", q)
+					}
+					return testQuote, nil
+				})
+			},
+			want: testQuote,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{quoteTTL: defaultQuoteTTL, fxRates: mockFXRates, Quotes: mockQuotes}
+			tt.before(&tt.args)
+
+			got, err := w.Quote(tt.args.ctx, tt.args.from, tt.args.to)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("Quote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Quote() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_ChangeAccountStatus(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := mock.NewMockAccounts(ctrl)
+
+	beginError := xerrors.New("begin_tx_error")
+	updateStatusError := xerrors.New("update_status_error")
+
+	type args struct {
+		ctx       context.Context
+		accountId string
+		status    entities.AccountStatus
+	}
+	tests := []struct {
+		name    string
+		args    args
+		before  func(*args)
+		want    *entities.Account
+		wantErr error
+	}{
+		{
+			name: "BeginTx returns error",
+			args: args{ctx: context.Background(), accountId: "alice123", status: entities.AccountFrozen},
+			before: func(a *args) {
+				dbmock.ExpectBegin().WillReturnError(beginError)
+			},
+			wantErr: beginError,
+		},
+		{
+			name: "account not found",
+			args: args{ctx: context.Background(), accountId: "alice1234", status: entities.AccountFrozen},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(nil, sql.ErrNoRows)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("account %s not found", "alice1234"),
+		},
+		{
+			name: "closed account can't be activated",
+			args: args{ctx: context.Background(), accountId: "alice123", status: entities.AccountActive},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.Zero, Currency: "USD", Status: entities.AccountClosed}, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: can't change status from %s to %s", "alice123", entities.AccountClosed, entities.AccountActive),
+		},
+		{
+			name: "account with balance can't be closed",
+			args: args{ctx: context.Background(), accountId: "alice123", status: entities.AccountClosed},
+			before: func(a *args) {
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).
+					Return(&entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(0.01), AvailableBalance: decimal.NewFromFloat(0.01), Currency: "USD", Status: entities.AccountFrozen}, nil)
+				dbmock.ExpectRollback()
+			},
+			wantErr: xerrors.Errorf("%s: can't close account with non zero balance", "alice123"),
+		},
+		{
+			name: "status update returns error",
+			args: args{ctx: context.Background(), accountId: "alice123", status: entities.AccountFrozen},
+			before: func(a *args) {
+				account := &entities.Account{AccountId: "alice123", Balance: decimal.NewFromFloat(10.21), AvailableBalance: decimal.NewFromFloat(10.21), Currency: "USD", Status: entities.AccountActive}
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(account, nil)
+				mockAccounts.EXPECT().UpdateStatusTx(gomock.Any(), a.ctx, account, a.status).Return(updateStatusError)
+				dbmock.ExpectRollback()
+			},
+			wantErr: updateStatusError,
+		},
+		{
+			name: "works fine",
+			args: args{ctx: context.Background(), accountId: "alice123", status: entities.AccountClosed},
+			before: func(a *args) {
+				account := &entities.Account{AccountId: "alice123", Balance: decimal.Zero, Currency: "USD", Status: entities.AccountActive}
+
+				dbmock.ExpectBegin()
+				mockAccounts.EXPECT().GetByNameTx(gomock.Any(), a.ctx, entities.AccountId(a.accountId)).Return(account, nil)
+				mockAccounts.EXPECT().UpdateStatusTx(gomock.Any(), a.ctx, account, a.status).Return(nil)
+				dbmock.ExpectCommit()
+			},
+			want: &entities.Account{AccountId: "alice123", Balance: decimal.Zero, Currency: "USD", Status: entities.AccountClosed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{db: db, Accounts: mockAccounts}
+			tt.before(&tt.args)
+
+			got, err := w.ChangeAccountStatus(tt.args.ctx, tt.args.accountId, tt.args.status)
+			if err != nil && (!xerrors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) || tt.wantErr != nil && err == nil {
+				t.Fatalf("ChangeAccountStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ChangeAccountStatus() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+//testCounter sums counted values by joined label values
+type testCounter struct {
+	labels []string
+	values map[string]float64
+}
+
+func (c *testCounter) With(labelValues ...string) metrics.Counter {
+	return &testCounter{labels: append(append([]string{}, c.labels...), labelValues...), values: c.values}
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func TestWalletService_authorizeAccount(t *testing.T) {
+	owned := &entities.Account{AccountId: "alice123", Owner: "alice"}
+	settlement := &entities.Account{AccountId: "settlement:USD"}
+
+	tests := []struct {
+		name          string
+		authorization bool
+		principal     *entities.Principal
+		account       *entities.Account
+		wantErr       error
+	}{
+		{
+			name:    "authorization disabled",
+			account: owned,
+		},
+		{
+			name:          "no principal",
+			authorization: true,
+			account:       owned,
+			wantErr:       models.UnauthenticatedError{xerrors.New("authentication required")},
+		},
+		{
+			name:          "owner",
+			authorization: true,
+			principal:     &entities.Principal{Subject: "alice"},
+			account:       owned,
+		},
+		{
+			name:          "not owner",
+			authorization: true,
+			principal:     &entities.Principal{Subject: "bob"},
+			account:       owned,
+			wantErr:       models.ForbiddenError{xerrors.New("alice123: access denied for bob")},
+		},
+		{
+			name:          "settlement without admin scope",
+			authorization: true,
+			principal:     &entities.Principal{Subject: "alice"},
+			account:       settlement,
+			wantErr:       models.ForbiddenError{xerrors.New("settlement:USD: access denied for alice")},
+		},
+		{
+			name:          "admin",
+			authorization: true,
+			principal:     &entities.Principal{Subject: "ops", Scopes: []string{entities.AdminScope}},
+			account:       settlement,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &WalletService{authorization: tt.authorization}
+
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = entities.ContextWithPrincipal(ctx, tt.principal)
+			}
+
+			err := w.authorizeAccount(ctx, tt.account)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Fatalf("authorizeAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil && reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+				t.Fatalf("authorizeAccount() error type = %T, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWalletService_observeTransfer(t *testing.T) {
+	volume := &testCounter{values: map[string]float64{}}
+	w := &WalletService{volume: volume}
+
+	w.observeTransfer(entities.Transfer, "USD", decimal.NewFromFloat(3.21))
+	w.observeTransfer(entities.Transfer, "USD", decimal.NewFromFloat(1.79))
+	w.observeTransfer(entities.Refund, "EUR", decimal.NewFromFloat(-2))
+
+	want := map[string]float64{"currency,USD,type,transfer": 5, "currency,EUR,type,refund": 2}
+	if !reflect.DeepEqual(volume.values, want) {
+		t.Errorf("observeTransfer() volume = %v, want %v", volume.values, want)
+	}
+
+	(&WalletService{}).observeTransfer(entities.Transfer, "USD", decimal.NewFromFloat(1))
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/NickRI/wallets-task/infrastructure/gateways"
+	"golang.org/x/xerrors"
+)
+
+const (
+	generatedSecretBytes = 32
+	maxWebhookDeliveries = 100
+)
+
+//WebhookService manages subscriptions of webhooks to wallet events
+type WebhookService struct {
+	Webhooks repositories.Webhooks
+}
+
+func NewWebhookService(d *sql.DB) (services.Webhooks, error) {
+	WebhooksTable, err := gateways.NewWebhooks(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in webhooks table: %w", err)
+	}
+
+	return &WebhookService{Webhooks: WebhooksTable}, nil
+}
+
+//CreateWebhook subscribes url to events, random secret is generated when it is empty
+func (ws *WebhookService) CreateWebhook(ctx context.Context, url, secret string) (*entities.Webhook, error) {
+	if secret == "" {
+		b := make([]byte, generatedSecretBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, xerrors.Errorf("secret generation error: %w", err)
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	webhook := &entities.Webhook{URL: url, Secret: secret}
+	if err := webhook.Validate(); err != nil {
+		return nil, models.ValidationError{err}
+	}
+
+	created, err := ws.Webhooks.Create(ctx, webhook)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return created, nil
+}
+
+func (ws *WebhookService) ListWebhooks(ctx context.Context) (entities.Webhooks, error) {
+	webhooks, err := ws.Webhooks.List(ctx)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return webhooks, nil
+}
+
+//DeleteWebhook deactivates webhook, its delivery history is kept
+func (ws *WebhookService) DeleteWebhook(ctx context.Context, webhookId string) (*entities.Webhook, error) {
+	webhook, err := ws.Webhooks.Deactivate(ctx, webhookId)
+	if err != nil {
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFoundWrapper{xerrors.Errorf("webhook %s not found", webhookId)}
+		}
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return webhook, nil
+}
+
+//WebhookDeliveries returns latest deliveries of webhook, empty status means any
+func (ws *WebhookService) WebhookDeliveries(ctx context.Context, webhookId string, status entities.DeliveryStatus) (entities.Deliveries, error) {
+	deliveries, err := ws.Webhooks.Deliveries(ctx, webhookId, status, maxWebhookDeliveries)
+	if err != nil {
+		return nil, models.DBErrorWrapper{err}
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,233 @@
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/xerrors"
+)
+
+const testWebhookId = "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+
+func TestNewWebhookService(t *testing.T) {
+	db, dbmock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	prepareError := xerrors.New("prepare_error")
+
+	dbmock.ExpectPrepare("SELECT .* FROM webhook_subscriptions s ORDER BY s.id").
+		WillReturnError(prepareError)
+
+	if _, err := NewWebhookService(db); !xerrors.Is(err, prepareError) {
+		t.Fatalf("NewWebhookService() error = %v, wantErr %v", err, prepareError)
+	}
+
+	dbmock.ExpectPrepare("SELECT .* FROM webhook_subscriptions s ORDER BY s.id")
+	dbmock.ExpectPrepare("INSERT INTO webhook_subscriptions (.*) VALUES (.*)")
+	dbmock.ExpectPrepare("UPDATE webhook_subscriptions SET active = false.*")
+	dbmock.ExpectPrepare("SELECT .* FROM webhook_deliveries d .*")
+
+	if _, err := NewWebhookService(db); err != nil {
+		t.Fatalf("NewWebhookService() error = %v", err)
+	}
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhooks(ctrl)
+
+	dbError := xerrors.New("db_error")
+	secret := "0123456789abcdef"
+
+	type args struct {
+		url    string
+		secret string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		mocks   func()
+		want    *entities.Webhook
+		wantErr error
+	}{
+		{
+			name:    "wrong url",
+			args:    args{url: "/hooks", secret: secret},
+			wantErr: models.ValidationError{xerrors.New(`url "/hooks" should be absolute http or https one`)},
+		},
+		{
+			name:    "short secret",
+			args:    args{url: "https://example.com/hooks", secret: "short"},
+			wantErr: models.ValidationError{xerrors.New("secret should be at least 16 characters long")},
+		},
+		{
+			name: "create returns error",
+			args: args{url: "https://example.com/hooks", secret: secret},
+			mocks: func() {
+				mockWebhooks.EXPECT().Create(gomock.Any(), &entities.Webhook{URL: "https://example.com/hooks", Secret: secret}).Return(nil, dbError)
+			},
+			wantErr: models.DBErrorWrapper{dbError},
+		},
+		{
+			name: "works fine",
+			args: args{url: "https://example.com/hooks", secret: secret},
+			mocks: func() {
+				mockWebhooks.EXPECT().Create(gomock.Any(), &entities.Webhook{URL: "https://example.com/hooks", Secret: secret}).
+					Return(&entities.Webhook{Id: testWebhookId, URL: "https://example.com/hooks", Secret: secret, Active: true}, nil)
+			},
+			want: &entities.Webhook{Id: testWebhookId, URL: "https://example.com/hooks", Secret: secret, Active: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &WebhookService{Webhooks: mockWebhooks}
+			if tt.mocks != nil {
+				tt.mocks()
+			}
+
+			got, err := ws.CreateWebhook(context.Background(), tt.args.url, tt.args.secret)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Errorf("CreateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateWebhook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookService_CreateWebhook_GeneratesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhooks(ctrl)
+	mockWebhooks.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, wh *entities.Webhook) (*entities.Webhook, error) {
+		return wh, nil
+	})
+
+	ws := &WebhookService{Webhooks: mockWebhooks}
+
+	got, err := ws.CreateWebhook(context.Background(), "https://example.com/hooks", "")
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+
+	if len(got.Secret) != 2*generatedSecretBytes {
+		t.Errorf("CreateWebhook() secret = %q, want %d hex symbols", got.Secret, 2*generatedSecretBytes)
+	}
+}
+
+func TestWebhookService_DeleteWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhooks(ctrl)
+
+	dbError := xerrors.New("db_error")
+
+	tests := []struct {
+		name    string
+		mocks   func()
+		want    *entities.Webhook
+		wantErr error
+	}{
+		{
+			name: "not found",
+			mocks: func() {
+				mockWebhooks.EXPECT().Deactivate(gomock.Any(), testWebhookId).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: models.NotFoundWrapper{xerrors.Errorf("webhook %s not found", testWebhookId)},
+		},
+		{
+			name: "deactivate returns error",
+			mocks: func() {
+				mockWebhooks.EXPECT().Deactivate(gomock.Any(), testWebhookId).Return(nil, dbError)
+			},
+			wantErr: models.DBErrorWrapper{dbError},
+		},
+		{
+			name: "works fine",
+			mocks: func() {
+				mockWebhooks.EXPECT().Deactivate(gomock.Any(), testWebhookId).Return(&entities.Webhook{Id: testWebhookId}, nil)
+			},
+			want: &entities.Webhook{Id: testWebhookId},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &WebhookService{Webhooks: mockWebhooks}
+			tt.mocks()
+
+			got, err := ws.DeleteWebhook(context.Background(), testWebhookId)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Errorf("DeleteWebhook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeleteWebhook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookService_WebhookDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhooks(ctrl)
+
+	dbError := xerrors.New("db_error")
+	deliveries := entities.Deliveries{{Status: entities.DeliveryDead, Attempts: 10}}
+
+	tests := []struct {
+		name    string
+		mocks   func()
+		want    entities.Deliveries
+		wantErr error
+	}{
+		{
+			name: "deliveries returns error",
+			mocks: func() {
+				mockWebhooks.EXPECT().Deliveries(gomock.Any(), testWebhookId, entities.DeliveryDead, maxWebhookDeliveries).Return(nil, dbError)
+			},
+			wantErr: models.DBErrorWrapper{dbError},
+		},
+		{
+			name: "works fine",
+			mocks: func() {
+				mockWebhooks.EXPECT().Deliveries(gomock.Any(), testWebhookId, entities.DeliveryDead, maxWebhookDeliveries).Return(deliveries, nil)
+			},
+			want: deliveries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &WebhookService{Webhooks: mockWebhooks}
+			tt.mocks()
+
+			got, err := ws.WebhookDeliveries(context.Background(), testWebhookId, entities.DeliveryDead)
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Errorf("WebhookDeliveries() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WebhookDeliveries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
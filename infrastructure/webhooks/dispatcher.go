@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	"github.com/NickRI/wallets-task/infrastructure/gateways"
+	"golang.org/x/xerrors"
+)
+
+const (
+	//EventHeader carries type of the delivered event
+	EventHeader = "X-Wallet-Event"
+	//EventIdHeader carries id of the delivered event, receivers use it to drop duplicates
+	EventIdHeader = "X-Wallet-Event-Id"
+	//SignatureHeader carries sha256=<hex HMAC-SHA256 of the body keyed with webhook secret>
+	SignatureHeader = "X-Wallet-Signature"
+
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 10
+	defaultBackoff     = 10 * time.Second
+	maxBackoff         = 6 * time.Hour
+)
+
+type Option func(*Dispatcher)
+
+func WithBatchSize(n int) Option {
+	return func(d *Dispatcher) {
+		d.batchSize = n
+	}
+}
+
+func WithMaxAttempts(n int64) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = n
+	}
+}
+
+//WithBackoff sets delay before the second attempt, every next one waits twice longer
+func WithBackoff(b time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.backoff = b
+	}
+}
+
+//WithLease sets how long claimed deliveries are hidden from other dispatchers, it should be longer than
+//timeout of webhook requests, otherwise slow delivery is claimed and posted again while it's still in flight.
+//Zero lease keeps the default one, twice the timeout
+func WithLease(lease time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.lease = lease
+	}
+}
+
+//Dispatcher delivers outbox events to webhooks, failed deliveries are retried with
+//exponential backoff and are moved to dead-letter state after maxAttempts
+type Dispatcher struct {
+	outbox      repositories.Outbox
+	client      *http.Client
+	batchSize   int
+	maxAttempts int64
+	backoff     time.Duration
+	lease       time.Duration
+}
+
+func NewDispatcher(d *sql.DB, timeout time.Duration, options ...Option) (*Dispatcher, error) {
+	OutboxTable, err := gateways.NewOutbox(d)
+	if err != nil {
+		return nil, xerrors.Errorf("Error in outbox table: %w", err)
+	}
+
+	dispatcher := newDispatcher(OutboxTable, timeout, options...)
+	if dispatcher.lease <= timeout {
+		return nil, xerrors.Errorf("lease %s should be longer than webhook timeout %s", dispatcher.lease, timeout)
+	}
+
+	return dispatcher, nil
+}
+
+func newDispatcher(outbox repositories.Outbox, timeout time.Duration, options ...Option) *Dispatcher {
+	d := &Dispatcher{
+		outbox:      outbox,
+		client:      &http.Client{Timeout: timeout},
+		batchSize:   defaultBatchSize,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	if d.lease == 0 {
+		d.lease = 2 * timeout
+	}
+
+	return d
+}
+
+//Dispatch fans new events out to active webhooks and makes one attempt for every due delivery,
+//claimed deliveries are hidden from other dispatchers until the lease is over. Results of all the attempts
+//are stored even when some of them fail to be stored, those deliveries are attempted again after the lease
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	if _, err := d.outbox.FanOut(ctx, d.batchSize); err != nil {
+		return xerrors.Errorf("fan out events error: %w", err)
+	}
+
+	deliveries, err := d.outbox.Claim(ctx, d.batchSize, time.Now().Add(d.lease))
+	if err != nil {
+		return xerrors.Errorf("claim deliveries error: %w", err)
+	}
+
+	errs := make(chan error, len(deliveries))
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *entities.Delivery) {
+			defer wg.Done()
+
+			if err := d.deliver(ctx, delivery); err != nil {
+				delivery.Failed(err.Error(), d.maxAttempts, time.Now().Add(d.retryIn(delivery.Attempts)))
+			} else {
+				delivery.Delivered()
+			}
+
+			errs <- d.outbox.UpdateDelivery(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	close(errs)
+
+	var failed updateErrors
+	for err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return xerrors.Errorf("update %d of %d deliveries error: %w", len(failed), len(deliveries), failed)
+	}
+
+	return nil
+}
+
+//updateErrors keeps errors of every delivery which result isn't stored
+type updateErrors []error
+
+func (ue updateErrors) Error() string {
+	msgs := make([]string, len(ue))
+	for i, err := range ue {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *entities.Delivery) error {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return xerrors.Errorf("event encoding error: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("webhook request error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.Event.Type))
+	req.Header.Set(EventIdHeader, delivery.Event.Id)
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, body))
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return xerrors.Errorf("webhook request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return xerrors.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+//retryIn doubles backoff for every failed attempt made before, up to maxBackoff
+func (d *Dispatcher) retryIn(attempts int64) time.Duration {
+	delay := d.backoff
+	for i := int64(0); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+
+	return delay
+}
+
+//Sign returns the value of SignatureHeader for body signed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
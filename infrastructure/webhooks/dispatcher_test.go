@@ -0,0 +1,216 @@
+// +build !integration
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/xerrors"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign(testSecret, body) || r.Header.Get(EventHeader) != string(entities.PaymentCompleted) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mock.NewMockOutbox(ctrl)
+
+	delivery := func(path, secret string, attempts int64) *entities.Delivery {
+		return &entities.Delivery{
+			Event:    &entities.Event{Id: "e9b0c72f-c08f-4e00-b158-42ae88f0c18e", Type: entities.PaymentCompleted, Data: json.RawMessage(`{"id":"1"}`)},
+			Webhook:  &entities.Webhook{URL: server.URL + path, Secret: secret},
+			Status:   entities.DeliveryPending,
+			Attempts: attempts,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		mocks        func()
+		delivery     *entities.Delivery
+		wantStatus   entities.DeliveryStatus
+		wantAttempts int64
+		wantErr      error
+	}{
+		{
+			name: "fan out returns error",
+			mocks: func() {
+				mockOutbox.EXPECT().FanOut(gomock.Any(), defaultBatchSize).Return(int64(0), xerrors.New("fan out error"))
+			},
+			wantErr: xerrors.New("fan out events error: fan out error"),
+		},
+		{
+			name: "claim returns error",
+			mocks: func() {
+				mockOutbox.EXPECT().FanOut(gomock.Any(), defaultBatchSize).Return(int64(1), nil)
+				mockOutbox.EXPECT().Claim(gomock.Any(), defaultBatchSize, gomock.Any()).Return(nil, xerrors.New("claim error"))
+			},
+			wantErr: xerrors.New("claim deliveries error: claim error"),
+		},
+		{
+			name:     "update returns error",
+			delivery: delivery("/ok", testSecret, 0),
+			mocks: func() {
+				mockOutbox.EXPECT().FanOut(gomock.Any(), defaultBatchSize).Return(int64(1), nil)
+				mockOutbox.EXPECT().Claim(gomock.Any(), defaultBatchSize, gomock.Any()).Return(entities.Deliveries{delivery("/ok", testSecret, 0)}, nil)
+				mockOutbox.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(xerrors.New("update error"))
+			},
+			wantErr: xerrors.New("update 1 of 1 deliveries error: update error"),
+		},
+		{
+			name:         "delivered",
+			delivery:     delivery("/ok", testSecret, 0),
+			wantStatus:   entities.DeliveryDelivered,
+			wantAttempts: 1,
+		},
+		{
+			name:         "wrong signature is retried",
+			delivery:     delivery("/ok", "another-secret-of-webhook", 0),
+			wantStatus:   entities.DeliveryPending,
+			wantAttempts: 1,
+		},
+		{
+			name:         "failed is retried",
+			delivery:     delivery("/fail", testSecret, 3),
+			wantStatus:   entities.DeliveryPending,
+			wantAttempts: 4,
+		},
+		{
+			name:         "failed the last time is dead",
+			delivery:     delivery("/fail", testSecret, defaultMaxAttempts-1),
+			wantStatus:   entities.DeliveryDead,
+			wantAttempts: defaultMaxAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *entities.Delivery
+			if tt.mocks != nil {
+				tt.mocks()
+			} else {
+				mockOutbox.EXPECT().FanOut(gomock.Any(), defaultBatchSize).Return(int64(1), nil)
+				mockOutbox.EXPECT().Claim(gomock.Any(), defaultBatchSize, gomock.Any()).Return(entities.Deliveries{tt.delivery}, nil)
+				mockOutbox.EXPECT().UpdateDelivery(gomock.Any(), tt.delivery).DoAndReturn(func(_ context.Context, d *entities.Delivery) error {
+					updated = d
+					return nil
+				})
+			}
+
+			err := newDispatcher(mockOutbox, time.Second).Dispatch(context.Background())
+			if err != nil && err.Error() != tt.wantErr.Error() || tt.wantErr != nil && err == nil {
+				t.Errorf("Dispatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if updated.Status != tt.wantStatus || updated.Attempts != tt.wantAttempts {
+				t.Errorf("Dispatch() status = %v attempts = %v, want %v %v", updated.Status, updated.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+
+			if tt.wantStatus == entities.DeliveryPending && (updated.LastError == "" || !updated.NextAttemptAt.After(time.Now())) {
+				t.Errorf("Dispatch() last error = %q next attempt at = %v, want retry scheduled", updated.LastError, updated.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DispatchUpdateFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mock.NewMockOutbox(ctrl)
+
+	deliveries := entities.Deliveries{}
+	for _, id := range []string{"e9b0c72f-c08f-4e00-b158-42ae88f0c18e", "0b3e1c52-8a8e-4e8c-9d4f-5b1e7c8e1f11", "5d0f9b4e-2c1a-4f6e-8b7d-3a2e1f0c9d22"} {
+		deliveries = append(deliveries, &entities.Delivery{
+			Event:   &entities.Event{Id: id, Type: entities.PaymentCompleted, Data: json.RawMessage(`{"id":"1"}`)},
+			Webhook: &entities.Webhook{URL: server.URL, Secret: testSecret},
+			Status:  entities.DeliveryPending,
+		})
+	}
+
+	var (
+		mu      sync.Mutex
+		updated = map[string]entities.DeliveryStatus{}
+	)
+
+	mockOutbox.EXPECT().FanOut(gomock.Any(), defaultBatchSize).Return(int64(3), nil)
+	mockOutbox.EXPECT().Claim(gomock.Any(), defaultBatchSize, gomock.Any()).Return(deliveries, nil)
+	mockOutbox.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Times(len(deliveries)).DoAndReturn(func(_ context.Context, d *entities.Delivery) error {
+		if d.Event.Id == deliveries[1].Event.Id {
+			return xerrors.New("update error")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		updated[d.Event.Id] = d.Status
+		return nil
+	})
+
+	err := newDispatcher(mockOutbox, time.Second).Dispatch(context.Background())
+	if want := "update 1 of 3 deliveries error: update error"; err == nil || err.Error() != want {
+		t.Fatalf("Dispatch() error = %v, want %s", err, want)
+	}
+
+	for _, d := range []*entities.Delivery{deliveries[0], deliveries[2]} {
+		if updated[d.Event.Id] != entities.DeliveryDelivered {
+			t.Errorf("Dispatch() stored %s with status %q, want %q", d.Event.Id, updated[d.Event.Id], entities.DeliveryDelivered)
+		}
+	}
+}
+
+func TestDispatcher_retryIn(t *testing.T) {
+	d := newDispatcher(nil, time.Second, WithBackoff(time.Minute))
+
+	tests := []struct {
+		name     string
+		attempts int64
+		want     time.Duration
+	}{
+		{name: "first retry", attempts: 0, want: time.Minute},
+		{name: "third retry", attempts: 2, want: 4 * time.Minute},
+		{name: "capped", attempts: 100, want: maxBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.retryIn(tt.attempts); got != tt.want {
+				t.Errorf("retryIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package wallets_task
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -13,19 +14,26 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/NickRI/wallets-task/db"
 	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/repositories"
+	domain "github.com/NickRI/wallets-task/domain/services"
 	"github.com/NickRI/wallets-task/infrastructure/services"
 	"github.com/NickRI/wallets-task/transport/restapi"
 	"github.com/go-kit/kit/log"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/viper"
 )
 
-var hosts []string
+var (
+	hosts  []string
+	wallet domain.Wallet
+)
 
 func getFreePort() (int, error) {
 	ln, err := net.Listen("tcp", ":0")
@@ -58,8 +66,19 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		panic(err)
 	}
+	wallet = wSvc
 
-	routes := restapi.MakeRoutes(wSvc, logger)
+	reconciler, err := services.NewReconciliationService(dbConn)
+	if err != nil {
+		panic(err)
+	}
+
+	webhookSvc, err := services.NewWebhookService(dbConn)
+	if err != nil {
+		panic(err)
+	}
+
+	routes := restapi.MakeRoutes(wSvc, webhookSvc, reconciler, nil, nil, nil, logger)
 
 	portA, err := getFreePort()
 	if err != nil {
@@ -78,14 +97,14 @@ func TestMain(m *testing.M) {
 	go serverA.Run()
 	go serverB.Run()
 	m.Run()
-	serverA.Shutdown()
-	serverB.Shutdown()
+	serverA.Shutdown(0)
+	serverB.Shutdown(0)
 }
 
 func tearUpDB(d *sql.DB) (err error) {
-	_, err = d.Exec(`INSERT INTO accounts (id, user_name, balance, currency, created_at, updated_at) VALUES
-		(DEFAULT, 'test1', 100, 'USD', DEFAULT, DEFAULT),
-		(DEFAULT, 'test2', 100, 'USD', DEFAULT, DEFAULT)`)
+	_, err = d.Exec(`INSERT INTO accounts (id, user_name, balance, opening_balance, currency, created_at, updated_at) VALUES
+		(DEFAULT, 'test1', 100, 100, 'USD', DEFAULT, DEFAULT),
+		(DEFAULT, 'test2', 100, 100, 'USD', DEFAULT, DEFAULT)`)
 	return
 }
 
@@ -241,3 +260,89 @@ func TestIntegrationSameDirection(t *testing.T) {
 		t.Error("expect status StatusPaymentRequired " + strconv.Itoa(nobalance) + " times with message 'test1: don't have enough balance'")
 	}
 }
+
+func tearUpBenchDB(d *sql.DB, pairs int) (err error) {
+	_, err = d.Exec(`INSERT INTO accounts (id, user_name, balance, opening_balance, currency, created_at, updated_at)
+		SELECT DEFAULT, 'bench' || n || side, 1000000, 1000000, 'USD', NOW(), NOW()
+		FROM generate_series(1, $1) n, (VALUES ('a'), ('b')) s(side)`, pairs)
+	return
+}
+
+func tearDownBenchDB(d *sql.DB) (err error) {
+	_, err = d.Exec(`DELETE FROM payments WHERE account_id IN (SELECT id FROM accounts WHERE user_name LIKE 'bench%')`)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.Exec(`DELETE FROM accounts WHERE user_name LIKE 'bench%'`)
+	return
+}
+
+//tableLockAccounts locks the whole accounts table the way transfers did before the row locks,
+//it's the baseline of BenchmarkIntegrationTransfers
+type tableLockAccounts struct {
+	repositories.Accounts
+}
+
+func (t tableLockAccounts) LockTx(tx *sql.Tx, ctx context.Context, _ ...entities.AccountId) (err error) {
+	_, err = tx.ExecContext(ctx, `LOCK TABLE accounts IN SHARE UPDATE EXCLUSIVE MODE`)
+	return
+}
+
+//BenchmarkIntegrationTransfers sends payments between unrelated pairs of accounts, one pair per goroutine,
+//with row locks of the transfer accounts and with the table lock baseline side by side
+func BenchmarkIntegrationTransfers(b *testing.B) {
+	db, err := db.Init()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	wSvc, err := services.NewWalletService(db)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tableLocked := wSvc.(*services.WalletService)
+	tableLocked.Accounts = tableLockAccounts{tableLocked.Accounts}
+
+	for _, concurrency := range []int{1, 4, 16, 64} {
+		for _, lock := range []struct {
+			name   string
+			wallet domain.Wallet
+		}{{"rows", wallet}, {"table", tableLocked}} {
+			b.Run(lock.name+"/concurrency-"+strconv.Itoa(concurrency), func(b *testing.B) {
+				benchTransfers(b, db, lock.wallet, concurrency)
+			})
+		}
+	}
+}
+
+func benchTransfers(b *testing.B, db *sql.DB, w domain.Wallet, concurrency int) {
+	if err := tearUpBenchDB(db, concurrency); err != nil {
+		b.Fatal(err)
+	}
+	defer tearDownBenchDB(db)
+
+	var (
+		ops int64
+		wg  sync.WaitGroup
+	)
+
+	b.ResetTimer()
+	for i := 1; i <= concurrency; i++ {
+		wg.Add(1)
+		go func(credit, debit string) {
+			defer wg.Done()
+			for op := atomic.AddInt64(&ops, 1); op <= int64(b.N); op = atomic.AddInt64(&ops, 1) {
+				from, to := credit, debit
+				if op%2 == 0 {
+					from, to = to, from
+				}
+				if _, err := w.Send(context.Background(), "", "", from, to, entities.NewMoney(decimal.New(1, 0), "")); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		}(fmt.Sprintf("bench%da", i), fmt.Sprintf("bench%db", i))
+	}
+	wg.Wait()
+}
@@ -8,10 +8,10 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	entities "github.com/NickRI/wallets-task/domain/entities"
 	gomock "github.com/golang/mock/gomock"
-	decimal "github.com/shopspring/decimal"
 )
 
 // MockAccounts is a mock of Accounts interface
@@ -37,6 +37,57 @@ func (m *MockAccounts) EXPECT() *MockAccountsMockRecorder {
 	return m.recorder
 }
 
+// BalanceAt mocks base method
+func (m *MockAccounts) BalanceAt(arg0 context.Context, arg1 *entities.Account, arg2 time.Time) (entities.Money, error) {
+	ret := m.ctrl.Call(m, "BalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BalanceAt indicates an expected call of BalanceAt
+func (mr *MockAccountsMockRecorder) BalanceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceAt", reflect.TypeOf((*MockAccounts)(nil).BalanceAt), arg0, arg1, arg2)
+}
+
+// Create mocks base method
+func (m *MockAccounts) Create(arg0 context.Context, arg1 *entities.Account) (*entities.Account, error) {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockAccountsMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccounts)(nil).Create), arg0, arg1)
+}
+
+// CreateSettlement mocks base method
+func (m *MockAccounts) CreateSettlement(arg0 context.Context, arg1 entities.Currency) error {
+	ret := m.ctrl.Call(m, "CreateSettlement", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSettlement indicates an expected call of CreateSettlement
+func (mr *MockAccountsMockRecorder) CreateSettlement(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSettlement", reflect.TypeOf((*MockAccounts)(nil).CreateSettlement), arg0, arg1)
+}
+
+// GetByName mocks base method
+func (m *MockAccounts) GetByName(arg0 context.Context, arg1 entities.AccountId) (*entities.Account, error) {
+	ret := m.ctrl.Call(m, "GetByName", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName
+func (mr *MockAccountsMockRecorder) GetByName(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockAccounts)(nil).GetByName), arg0, arg1)
+}
+
 // GetByNameTx mocks base method
 func (m *MockAccounts) GetByNameTx(arg0 *sql.Tx, arg1 context.Context, arg2 entities.AccountId) (*entities.Account, error) {
 	ret := m.ctrl.Call(m, "GetByNameTx", arg0, arg1, arg2)
@@ -64,19 +115,24 @@ func (mr *MockAccountsMockRecorder) List(arg0 interface{}) *gomock.Call {
 }
 
 // LockTx mocks base method
-func (m *MockAccounts) LockTx(arg0 *sql.Tx, arg1 context.Context) error {
-	ret := m.ctrl.Call(m, "LockTx", arg0, arg1)
+func (m *MockAccounts) LockTx(arg0 *sql.Tx, arg1 context.Context, arg2 ...entities.AccountId) error {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LockTx", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // LockTx indicates an expected call of LockTx
-func (mr *MockAccountsMockRecorder) LockTx(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTx", reflect.TypeOf((*MockAccounts)(nil).LockTx), arg0, arg1)
+func (mr *MockAccountsMockRecorder) LockTx(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTx", reflect.TypeOf((*MockAccounts)(nil).LockTx), varargs...)
 }
 
 // UpdateBalanceTx mocks base method
-func (m *MockAccounts) UpdateBalanceTx(arg0 *sql.Tx, arg1 context.Context, arg2 *entities.Account, arg3 decimal.Decimal) error {
+func (m *MockAccounts) UpdateBalanceTx(arg0 *sql.Tx, arg1 context.Context, arg2 *entities.Account, arg3 entities.Money) error {
 	ret := m.ctrl.Call(m, "UpdateBalanceTx", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
@@ -86,3 +142,15 @@ func (m *MockAccounts) UpdateBalanceTx(arg0 *sql.Tx, arg1 context.Context, arg2
 func (mr *MockAccountsMockRecorder) UpdateBalanceTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceTx", reflect.TypeOf((*MockAccounts)(nil).UpdateBalanceTx), arg0, arg1, arg2, arg3)
 }
+
+// UpdateStatusTx mocks base method
+func (m *MockAccounts) UpdateStatusTx(arg0 *sql.Tx, arg1 context.Context, arg2 *entities.Account, arg3 entities.AccountStatus) error {
+	ret := m.ctrl.Call(m, "UpdateStatusTx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatusTx indicates an expected call of UpdateStatusTx
+func (mr *MockAccountsMockRecorder) UpdateStatusTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusTx", reflect.TypeOf((*MockAccounts)(nil).UpdateStatusTx), arg0, arg1, arg2, arg3)
+}
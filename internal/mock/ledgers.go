@@ -11,7 +11,6 @@ import (
 
 	entities "github.com/NickRI/wallets-task/domain/entities"
 	gomock "github.com/golang/mock/gomock"
-	decimal "github.com/shopspring/decimal"
 )
 
 // MockLedgers is a mock of Ledgers interface
@@ -37,28 +36,119 @@ func (m *MockLedgers) EXPECT() *MockLedgersMockRecorder {
 	return m.recorder
 }
 
+// AddBatchTx mocks base method
+func (m *MockLedgers) AddBatchTx(arg0 *sql.Tx, arg1 context.Context, arg2 string, arg3, arg4 *entities.Account, arg5 entities.Money, arg6 *entities.Conversion) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "AddBatchTx", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBatchTx indicates an expected call of AddBatchTx
+func (mr *MockLedgersMockRecorder) AddBatchTx(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatchTx", reflect.TypeOf((*MockLedgers)(nil).AddBatchTx), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// AddRefundTx mocks base method
+func (m *MockLedgers) AddRefundTx(arg0 *sql.Tx, arg1 context.Context, arg2 string, arg3, arg4 *entities.Account, arg5 entities.Money, arg6 *entities.Conversion) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "AddRefundTx", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRefundTx indicates an expected call of AddRefundTx
+func (mr *MockLedgersMockRecorder) AddRefundTx(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRefundTx", reflect.TypeOf((*MockLedgers)(nil).AddRefundTx), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
 // AddTx mocks base method
-func (m *MockLedgers) AddTx(arg0 *sql.Tx, arg1 context.Context, arg2, arg3 *entities.Account, arg4 decimal.Decimal) (*entities.Ledger, error) {
-	ret := m.ctrl.Call(m, "AddTx", arg0, arg1, arg2, arg3, arg4)
+func (m *MockLedgers) AddTx(arg0 *sql.Tx, arg1 context.Context, arg2, arg3 *entities.Account, arg4 entities.Money, arg5 entities.PaymentType, arg6 *entities.Conversion) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "AddTx", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 	ret0, _ := ret[0].(*entities.Ledger)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddTx indicates an expected call of AddTx
-func (mr *MockLedgersMockRecorder) AddTx(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTx", reflect.TypeOf((*MockLedgers)(nil).AddTx), arg0, arg1, arg2, arg3, arg4)
+func (mr *MockLedgersMockRecorder) AddTx(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTx", reflect.TypeOf((*MockLedgers)(nil).AddTx), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// GetByGuid mocks base method
+func (m *MockLedgers) GetByGuid(arg0 context.Context, arg1 string) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "GetByGuid", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByGuid indicates an expected call of GetByGuid
+func (mr *MockLedgersMockRecorder) GetByGuid(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByGuid", reflect.TypeOf((*MockLedgers)(nil).GetByGuid), arg0, arg1)
+}
+
+// GetByGuidTx mocks base method
+func (m *MockLedgers) GetByGuidTx(arg0 *sql.Tx, arg1 context.Context, arg2 string) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "GetByGuidTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByGuidTx indicates an expected call of GetByGuidTx
+func (mr *MockLedgersMockRecorder) GetByGuidTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByGuidTx", reflect.TypeOf((*MockLedgers)(nil).GetByGuidTx), arg0, arg1, arg2)
 }
 
 // List mocks base method
-func (m *MockLedgers) List(arg0 context.Context) (entities.Ledgers, error) {
-	ret := m.ctrl.Call(m, "List", arg0)
-	ret0, _ := ret[0].(entities.Ledgers)
+func (m *MockLedgers) List(arg0 context.Context, arg1 *entities.LedgerFilter) (*entities.LedgersPage, error) {
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].(*entities.LedgersPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List
-func (mr *MockLedgersMockRecorder) List(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLedgers)(nil).List), arg0)
+func (mr *MockLedgersMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLedgers)(nil).List), arg0, arg1)
+}
+
+// ListByAccount mocks base method
+func (m *MockLedgers) ListByAccount(arg0 context.Context, arg1 *entities.Account, arg2 *entities.Pagination) (*entities.PaymentsPage, error) {
+	ret := m.ctrl.Call(m, "ListByAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.PaymentsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByAccount indicates an expected call of ListByAccount
+func (mr *MockLedgersMockRecorder) ListByAccount(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByAccount", reflect.TypeOf((*MockLedgers)(nil).ListByAccount), arg0, arg1, arg2)
+}
+
+// RefundedTx mocks base method
+func (m *MockLedgers) RefundedTx(arg0 *sql.Tx, arg1 context.Context, arg2 *entities.Payment) (entities.Money, error) {
+	ret := m.ctrl.Call(m, "RefundedTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundedTx indicates an expected call of RefundedTx
+func (mr *MockLedgersMockRecorder) RefundedTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundedTx", reflect.TypeOf((*MockLedgers)(nil).RefundedTx), arg0, arg1, arg2)
+}
+
+// TransferTx mocks base method
+func (m *MockLedgers) TransferTx(arg0 *sql.Tx, arg1 context.Context, arg2, arg3 entities.AccountId, arg4 entities.Money) (*entities.TransferAttempt, error) {
+	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*entities.TransferAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferTx indicates an expected call of TransferTx
+func (mr *MockLedgersMockRecorder) TransferTx(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockLedgers)(nil).TransferTx), arg0, arg1, arg2, arg3, arg4)
 }
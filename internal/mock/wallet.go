@@ -6,10 +6,11 @@ package mock
 
 import (
 	context "context"
+	reflect "reflect"
+	time "time"
+
 	entities "github.com/NickRI/wallets-task/domain/entities"
 	gomock "github.com/golang/mock/gomock"
-	decimal "github.com/shopspring/decimal"
-	reflect "reflect"
 )
 
 // MockWallet is a mock of Wallet interface
@@ -35,6 +36,19 @@ func (m *MockWallet) EXPECT() *MockWalletMockRecorder {
 	return m.recorder
 }
 
+// AccountLedgers mocks base method
+func (m *MockWallet) AccountLedgers(arg0 context.Context, arg1 string, arg2 *entities.Pagination) (*entities.PaymentsPage, error) {
+	ret := m.ctrl.Call(m, "AccountLedgers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.PaymentsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccountLedgers indicates an expected call of AccountLedgers
+func (mr *MockWalletMockRecorder) AccountLedgers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountLedgers", reflect.TypeOf((*MockWallet)(nil).AccountLedgers), arg0, arg1, arg2)
+}
+
 // AccountsList mocks base method
 func (m *MockWallet) AccountsList(arg0 context.Context) (entities.Accounts, error) {
 	ret := m.ctrl.Call(m, "AccountsList", arg0)
@@ -48,28 +62,223 @@ func (mr *MockWalletMockRecorder) AccountsList(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountsList", reflect.TypeOf((*MockWallet)(nil).AccountsList), arg0)
 }
 
+// Allowance mocks base method
+func (m *MockWallet) Allowance(arg0 context.Context, arg1 string) (*entities.Allowance, error) {
+	ret := m.ctrl.Call(m, "Allowance", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Allowance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Allowance indicates an expected call of Allowance
+func (mr *MockWalletMockRecorder) Allowance(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allowance", reflect.TypeOf((*MockWallet)(nil).Allowance), arg0, arg1)
+}
+
+// Authorize mocks base method
+func (m *MockWallet) Authorize(arg0 context.Context, arg1, arg2 string, arg3 entities.Money) (*entities.Hold, error) {
+	ret := m.ctrl.Call(m, "Authorize", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*entities.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize
+func (mr *MockWalletMockRecorder) Authorize(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockWallet)(nil).Authorize), arg0, arg1, arg2, arg3)
+}
+
+// BalanceAt mocks base method
+func (m *MockWallet) BalanceAt(arg0 context.Context, arg1 string, arg2 time.Time) (*entities.AccountBalance, error) {
+	ret := m.ctrl.Call(m, "BalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BalanceAt indicates an expected call of BalanceAt
+func (mr *MockWalletMockRecorder) BalanceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceAt", reflect.TypeOf((*MockWallet)(nil).BalanceAt), arg0, arg1, arg2)
+}
+
+// Batch mocks base method
+func (m *MockWallet) Batch(arg0 context.Context, arg1 []*entities.TransferLeg) (*entities.Batch, error) {
+	ret := m.ctrl.Call(m, "Batch", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Batch indicates an expected call of Batch
+func (mr *MockWalletMockRecorder) Batch(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Batch", reflect.TypeOf((*MockWallet)(nil).Batch), arg0, arg1)
+}
+
+// Capture mocks base method
+func (m *MockWallet) Capture(arg0 context.Context, arg1 string, arg2 entities.Money) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "Capture", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture
+func (mr *MockWalletMockRecorder) Capture(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockWallet)(nil).Capture), arg0, arg1, arg2)
+}
+
+// ChangeAccountStatus mocks base method
+func (m *MockWallet) ChangeAccountStatus(arg0 context.Context, arg1 string, arg2 entities.AccountStatus) (*entities.Account, error) {
+	ret := m.ctrl.Call(m, "ChangeAccountStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeAccountStatus indicates an expected call of ChangeAccountStatus
+func (mr *MockWalletMockRecorder) ChangeAccountStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAccountStatus", reflect.TypeOf((*MockWallet)(nil).ChangeAccountStatus), arg0, arg1, arg2)
+}
+
+// CreateAccount mocks base method
+func (m *MockWallet) CreateAccount(arg0 context.Context, arg1, arg2 string) (*entities.Account, error) {
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount
+func (mr *MockWalletMockRecorder) CreateAccount(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockWallet)(nil).CreateAccount), arg0, arg1, arg2)
+}
+
+// Deposit mocks base method
+func (m *MockWallet) Deposit(arg0 context.Context, arg1, arg2 string, arg3 entities.Money) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "Deposit", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deposit indicates an expected call of Deposit
+func (mr *MockWalletMockRecorder) Deposit(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWallet)(nil).Deposit), arg0, arg1, arg2, arg3)
+}
+
+// GetAccount mocks base method
+func (m *MockWallet) GetAccount(arg0 context.Context, arg1 string) (*entities.Account, error) {
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount
+func (mr *MockWalletMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockWallet)(nil).GetAccount), arg0, arg1)
+}
+
+// GetHold mocks base method
+func (m *MockWallet) GetHold(arg0 context.Context, arg1 string) (*entities.Hold, error) {
+	ret := m.ctrl.Call(m, "GetHold", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold
+func (mr *MockWalletMockRecorder) GetHold(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockWallet)(nil).GetHold), arg0, arg1)
+}
+
+// GetLedger mocks base method
+func (m *MockWallet) GetLedger(arg0 context.Context, arg1 string) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "GetLedger", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedger indicates an expected call of GetLedger
+func (mr *MockWalletMockRecorder) GetLedger(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedger", reflect.TypeOf((*MockWallet)(nil).GetLedger), arg0, arg1)
+}
+
 // LedgersList mocks base method
-func (m *MockWallet) LedgersList(arg0 context.Context) (entities.Ledgers, error) {
-	ret := m.ctrl.Call(m, "LedgersList", arg0)
-	ret0, _ := ret[0].(entities.Ledgers)
+func (m *MockWallet) LedgersList(arg0 context.Context, arg1 *entities.LedgerFilter) (*entities.LedgersPage, error) {
+	ret := m.ctrl.Call(m, "LedgersList", arg0, arg1)
+	ret0, _ := ret[0].(*entities.LedgersPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LedgersList indicates an expected call of LedgersList
-func (mr *MockWalletMockRecorder) LedgersList(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LedgersList", reflect.TypeOf((*MockWallet)(nil).LedgersList), arg0)
+func (mr *MockWalletMockRecorder) LedgersList(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LedgersList", reflect.TypeOf((*MockWallet)(nil).LedgersList), arg0, arg1)
+}
+
+// Quote mocks base method
+func (m *MockWallet) Quote(arg0 context.Context, arg1, arg2 string) (*entities.FXQuote, error) {
+	ret := m.ctrl.Call(m, "Quote", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.FXQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote
+func (mr *MockWalletMockRecorder) Quote(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockWallet)(nil).Quote), arg0, arg1, arg2)
+}
+
+// Refund mocks base method
+func (m *MockWallet) Refund(arg0 context.Context, arg1 string, arg2 entities.Money) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "Refund", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund
+func (mr *MockWalletMockRecorder) Refund(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockWallet)(nil).Refund), arg0, arg1, arg2)
 }
 
 // Send mocks base method
-func (m *MockWallet) Send(arg0 context.Context, arg1, arg2 string, arg3 decimal.Decimal) (*entities.Ledger, error) {
-	ret := m.ctrl.Call(m, "Send", arg0, arg1, arg2, arg3)
+func (m *MockWallet) Send(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 entities.Money) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "Send", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(*entities.Ledger)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Send indicates an expected call of Send
-func (mr *MockWalletMockRecorder) Send(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWallet)(nil).Send), arg0, arg1, arg2, arg3)
+func (mr *MockWalletMockRecorder) Send(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWallet)(nil).Send), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// Void mocks base method
+func (m *MockWallet) Void(arg0 context.Context, arg1 string) (*entities.Hold, error) {
+	ret := m.ctrl.Call(m, "Void", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Void indicates an expected call of Void
+func (mr *MockWalletMockRecorder) Void(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockWallet)(nil).Void), arg0, arg1)
+}
+
+// Withdraw mocks base method
+func (m *MockWallet) Withdraw(arg0 context.Context, arg1, arg2 string, arg3 entities.Money) (*entities.Ledger, error) {
+	ret := m.ctrl.Call(m, "Withdraw", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*entities.Ledger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Withdraw indicates an expected call of Withdraw
+func (mr *MockWalletMockRecorder) Withdraw(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWallet)(nil).Withdraw), arg0, arg1, arg2, arg3)
 }
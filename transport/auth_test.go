@@ -0,0 +1,184 @@
+// +build !integration
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/NickRI/wallets-task/transport/restapi"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func Test_AuthRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+	mockReconciler := mock.NewMockReconciler(ctrl)
+	mockAPIKeys := mock.NewMockAuthenticator(ctrl)
+	mockTokens := mock.NewMockAuthenticator(ctrl)
+
+	alice := &entities.Principal{Subject: "alice"}
+	admin := &entities.Principal{Subject: "ops", Scopes: []string{entities.AdminScope}}
+	invalid := models.UnauthenticatedError{xerrors.New("invalid api key")}
+
+	routes := restapi.MakeRoutes(mockWallet, nil, mockReconciler, restapi.NewHealth(0), nil,
+		&restapi.Auth{APIKeys: mockAPIKeys, Tokens: mockTokens}, log.NewNopLogger())
+
+	tests := []struct {
+		name     string
+		path     string
+		header   map[string]string
+		before   func()
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "no credentials",
+			path:     "/wallet/accounts/alice456",
+			before:   func() {},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "authentication required",
+		},
+		{
+			name:   "invalid api key",
+			path:   "/wallet/accounts/alice456",
+			header: map[string]string{restapi.APIKeyHeader: "guess"},
+			before: func() {
+				mockAPIKeys.EXPECT().Authenticate(gomock.Any(), "guess").Return(nil, invalid)
+			},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  "invalid api key",
+		},
+		{
+			name:   "api key principal is put into context",
+			path:   "/wallet/accounts/alice456",
+			header: map[string]string{restapi.APIKeyHeader: "alice-key"},
+			before: func() {
+				mockAPIKeys.EXPECT().Authenticate(gomock.Any(), "alice-key").Return(alice, nil)
+				mockWallet.EXPECT().GetAccount(gomock.Any(), "alice456").DoAndReturn(func(ctx context.Context, id string) (*entities.Account, error) {
+					if p, ok := entities.PrincipalFromContext(ctx); !ok || p != alice {
+						t.Errorf("GetAccount() principal = %v, want %v", p, alice)
+					}
+					return &entities.Account{AccountId: "alice456", Balance: decimal.Zero, Currency: "USD", Owner: "alice"}, nil
+				})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "forbidden by service",
+			path:   "/wallet/accounts/bob123",
+			header: map[string]string{"Authorization": "Bearer alice-token"},
+			before: func() {
+				mockTokens.EXPECT().Authenticate(gomock.Any(), "alice-token").Return(alice, nil)
+				mockWallet.EXPECT().GetAccount(gomock.Any(), "bob123").
+					Return(nil, models.ForbiddenError{xerrors.New("bob123: access denied for alice")})
+			},
+			wantCode: http.StatusForbidden,
+			wantErr:  "bob123: access denied for alice",
+		},
+		{
+			name:   "admin route without admin scope",
+			path:   "/admin/reconciliation",
+			header: map[string]string{"Authorization": "Bearer alice-token"},
+			before: func() {
+				mockTokens.EXPECT().Authenticate(gomock.Any(), "alice-token").Return(alice, nil)
+			},
+			wantCode: http.StatusForbidden,
+			wantErr:  "admin scope is required",
+		},
+		{
+			name:   "admin route",
+			path:   "/admin/reconciliation",
+			header: map[string]string{"Authorization": "Bearer ops-token"},
+			before: func() {
+				mockTokens.EXPECT().Authenticate(gomock.Any(), "ops-token").Return(admin, nil)
+				mockReconciler.EXPECT().LastReport(gomock.Any()).Return(&entities.ReconciliationReport{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "probes are public",
+			path:     "/healthz",
+			before:   func() {},
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+
+			rr := httptest.NewRecorder()
+			routes.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantCode)
+			}
+
+			if rr.Code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("handler returned no WWW-Authenticate header")
+			}
+
+			var resp struct {
+				Err string `json:"error"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if resp.Err != tt.wantErr {
+				t.Errorf("handler returned error %v, want %v", resp.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_AuthDisabledRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	routes := restapi.MakeRoutes(mockWallet, mock.NewMockWebhookService(ctrl), mock.NewMockReconciler(ctrl), restapi.NewHealth(0), nil, nil, log.NewNopLogger())
+
+	mockWallet.EXPECT().GetAccount(gomock.Any(), "alice456").
+		Return(&entities.Account{AccountId: "alice456", Balance: decimal.Zero, Currency: "USD"}, nil)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+	}{
+		{name: "wallet route is open", method: "GET", path: "/wallet/accounts/alice456", wantCode: http.StatusOK},
+		{name: "webhooks aren't listed", method: "GET", path: "/wallet/webhooks", wantCode: http.StatusNotFound},
+		{name: "webhook isn't created", method: "POST", path: "/wallet/webhooks", wantCode: http.StatusNotFound},
+		{name: "deliveries aren't listed", method: "GET", path: "/wallet/webhooks/1/deliveries", wantCode: http.StatusNotFound},
+		{name: "reconciliation report isn't served", method: "GET", path: "/admin/reconciliation", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			routes.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantCode)
+			}
+		})
+	}
+}
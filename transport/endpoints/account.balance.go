@@ -0,0 +1,35 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type BalanceRequest struct {
+	AccountId string
+	At        time.Time
+}
+
+//BalanceDecoder reads account id from the path and optional at moment from the query string
+func BalanceDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	at, err := parseTime(r.URL.Query().Get("at"))
+	if err != nil {
+		return nil, models.ValidationError{xerrors.Errorf("wrong at: %w", err)}
+	}
+
+	return BalanceRequest{AccountId: chi.URLParam(r, "id"), At: at}, nil
+}
+
+func AccountBalance(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(BalanceRequest)
+		return ws.BalanceAt(ctx, req.AccountId, req.At)
+	}
+}
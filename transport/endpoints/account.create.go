@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+const (
+	maxAccountIdLength = 64
+	maxCurrencyLength  = 4
+)
+
+type CreateAccountRequest struct {
+	AccountId string `json:"id"`
+	Currency  string `json:"currency"`
+}
+
+func CreateAccountDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CreateAccountRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	if req.AccountId == "" || len(req.AccountId) > maxAccountIdLength {
+		return nil, models.ValidationError{xerrors.Errorf("id should be from 1 to %d symbols", maxAccountIdLength)}
+	}
+
+	if req.Currency == "" || len(req.Currency) > maxCurrencyLength {
+		return nil, models.ValidationError{xerrors.Errorf("currency should be from 1 to %d symbols", maxCurrencyLength)}
+	}
+
+	if !entities.Currency(req.Currency).Valid() {
+		return nil, models.ValidationError{xerrors.Errorf("currency %s is unknown", req.Currency)}
+	}
+
+	return req, nil
+}
+
+func AccountCreate(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateAccountRequest)
+		return ws.CreateAccount(ctx, req.AccountId, req.Currency)
+	}
+}
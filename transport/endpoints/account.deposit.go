@@ -0,0 +1,48 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type FundsRequest struct {
+	AccountId      string         `json:"-"`
+	IdempotencyKey string         `json:"-"`
+	Amount         entities.Money `json:"amount"`
+}
+
+//FundsDecoder reads deposit or withdrawal of the account from path and body
+func FundsDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req FundsRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	req.AccountId = chi.URLParam(r, "id")
+
+	if err := ValidateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.IdempotencyKey, err = idempotencyKey(r); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func AccountDeposit(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(FundsRequest)
+		return ws.Deposit(ctx, req.IdempotencyKey, req.AccountId, req.Amount)
+	}
+}
@@ -0,0 +1,25 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+)
+
+type AccountRequest struct {
+	AccountId string
+}
+
+func AccountDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return AccountRequest{AccountId: chi.URLParam(r, "id")}, nil
+}
+
+func AccountGet(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AccountRequest)
+		return ws.GetAccount(ctx, req.AccountId)
+	}
+}
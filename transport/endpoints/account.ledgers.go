@@ -0,0 +1,33 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+)
+
+type AccountLedgersRequest struct {
+	AccountId string
+	Page      entities.Pagination
+}
+
+//AccountLedgersDecoder reads account id from the path and page from the query string
+func AccountLedgersDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	page, err := parsePagination(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	return AccountLedgersRequest{AccountId: chi.URLParam(r, "id"), Page: page}, nil
+}
+
+func AccountLedgers(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AccountLedgersRequest)
+		return ws.AccountLedgers(ctx, req.AccountId, &req.Page)
+	}
+}
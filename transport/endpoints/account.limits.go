@@ -0,0 +1,15 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func AccountLimits(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AccountRequest)
+		return ws.Allowance(ctx, req.AccountId)
+	}
+}
@@ -0,0 +1,41 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type AccountStatusRequest struct {
+	AccountId string                 `json:"-"`
+	Status    entities.AccountStatus `json:"status"`
+}
+
+func AccountStatusDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req AccountStatusRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	if !req.Status.Valid() {
+		return nil, models.ValidationError{xerrors.Errorf("wrong status %q only active/frozen/closed values allowed", req.Status)}
+	}
+
+	req.AccountId = chi.URLParam(r, "id")
+
+	return req, nil
+}
+
+func AccountStatusChange(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AccountStatusRequest)
+		return ws.ChangeAccountStatus(ctx, req.AccountId, req.Status)
+	}
+}
@@ -0,0 +1,15 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func AccountWithdraw(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(FundsRequest)
+		return ws.Withdraw(ctx, req.IdempotencyKey, req.AccountId, req.Amount)
+	}
+}
@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type BatchRequest struct {
+	Legs []*entities.TransferLeg `json:"legs"`
+}
+
+func BatchDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req BatchRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	for i, leg := range req.Legs {
+		if leg == nil || leg.From == "" || leg.To == "" {
+			return nil, models.ValidationError{xerrors.Errorf("leg %d: from and to accounts are required", i)}
+		}
+	}
+
+	return req, nil
+}
+
+func BatchCreate(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(BatchRequest)
+		return ws.Batch(ctx, req.Legs)
+	}
+}
@@ -35,33 +35,45 @@ func EncodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	return json.NewEncoder(w).Encode(&Response{Data: response})
 }
 
-func ErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+//errorClasses tells errors of the service apart, first matching class names the error and its http status
+var errorClasses = []struct {
+	kind   string
+	status int
+	match  func(error) bool
+}{
+	{"not_found", http.StatusNotFound, func(err error) bool { return xerrors.As(err, &models.NotFoundWrapper{}) }},
+	{"conflict", http.StatusConflict, func(err error) bool { return xerrors.As(err, &models.ConflictError{}) }},
+	{"db", http.StatusInternalServerError, func(err error) bool { return xerrors.As(err, &models.DBErrorWrapper{}) }},
+	{"low_balance", http.StatusPaymentRequired, func(err error) bool { return xerrors.As(err, &models.LowBalanceWrapper{}) }},
+	{"account_state", http.StatusConflict, func(err error) bool { return xerrors.As(err, &models.AccountStateError{}) }},
+	{"idempotency_conflict", http.StatusUnprocessableEntity, func(err error) bool { return xerrors.As(err, &models.IdempotencyConflictError{}) }},
+	{"limit_exceeded", http.StatusUnprocessableEntity, func(err error) bool { return xerrors.As(err, &models.LimitExceededError{}) }},
+	{"quote", http.StatusUnprocessableEntity, func(err error) bool { return xerrors.As(err, &models.QuoteError{}) }},
+	{"validation", http.StatusBadRequest, func(err error) bool { return xerrors.As(err, &models.ValidationError{}) }},
+	{"unauthenticated", http.StatusUnauthorized, func(err error) bool { return xerrors.As(err, &models.UnauthenticatedError{}) }},
+	{"forbidden", http.StatusForbidden, func(err error) bool { return xerrors.As(err, &models.ForbiddenError{}) }},
+	{"unavailable", http.StatusServiceUnavailable, func(err error) bool { return xerrors.As(err, &models.UnavailableError{}) }},
+}
 
-	if xerrors.As(err, &models.NotFoundWrapper{}) {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(&Response{Error: errorWrapper{err}})
-		return
+//classifyError returns kind and http status of err, unknown errors are internal
+func classifyError(err error) (string, int) {
+	for _, c := range errorClasses {
+		if c.match(err) {
+			return c.kind, c.status
+		}
 	}
 
-	if xerrors.As(err, &models.DBErrorWrapper{}) {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(&Response{Error: errorWrapper{err}})
-		return
-	}
+	return "internal", http.StatusInternalServerError
+}
 
-	if xerrors.As(err, &models.LowBalanceWrapper{}) {
-		w.WriteHeader(http.StatusPaymentRequired)
-		json.NewEncoder(w).Encode(&Response{Error: errorWrapper{err}})
-		return
-	}
+func ErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-	if xerrors.As(err, &models.ValidationError{}) {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(&Response{Error: errorWrapper{err}})
-		return
+	kind, status := classifyError(err)
+	if kind == "unauthenticated" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
 	}
 
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(&Response{Error: errorWrapper{err}})
 }
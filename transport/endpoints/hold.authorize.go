@@ -0,0 +1,43 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type AuthorizeRequest struct {
+	Sender   string         `json:"from"`
+	Receiver string         `json:"to"`
+	Amount   entities.Money `json:"amount"`
+}
+
+func AuthorizeDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req AuthorizeRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	if req.Sender == "" || req.Receiver == "" {
+		return nil, models.ValidationError{xerrors.New("from and to accounts are required")}
+	}
+
+	if err := ValidateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func HoldAuthorize(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthorizeRequest)
+		return ws.Authorize(ctx, req.Sender, req.Receiver, req.Amount)
+	}
+}
@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type CaptureRequest struct {
+	HoldId string         `json:"-"`
+	Amount entities.Money `json:"amount"`
+}
+
+//CaptureDecoder reads hold from path, empty body or omitted amount captures the whole hold
+func CaptureDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CaptureRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil && e != io.EOF {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	req.HoldId = chi.URLParam(r, "id")
+
+	return req, nil
+}
+
+func HoldCapture(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CaptureRequest)
+		return ws.Capture(ctx, req.HoldId, req.Amount)
+	}
+}
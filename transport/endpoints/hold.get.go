@@ -0,0 +1,25 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+)
+
+type HoldRequest struct {
+	HoldId string
+}
+
+func HoldDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return HoldRequest{HoldId: chi.URLParam(r, "id")}, nil
+}
+
+func HoldGet(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(HoldRequest)
+		return ws.GetHold(ctx, req.HoldId)
+	}
+}
@@ -0,0 +1,15 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func HoldVoid(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(HoldRequest)
+		return ws.Void(ctx, req.HoldId)
+	}
+}
@@ -0,0 +1,45 @@
+package endpoints
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+)
+
+//Instrument wraps endpoint of the named method, transports apply it to every endpoint they serve
+type Instrument func(method string, e endpoint.Endpoint) endpoint.Endpoint
+
+//Wrap keeps endpoint as is when there is no instrument
+func (i Instrument) Wrap(method string, e endpoint.Endpoint) endpoint.Endpoint {
+	if i == nil {
+		return e
+	}
+
+	return i(method, e)
+}
+
+//Instrumenting observes duration of calls labeled by method and success,
+//failed calls are also counted in errs labeled by method and kind of the error
+func Instrumenting(duration metrics.Histogram, errs metrics.Counter) Instrument {
+	return func(method string, next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				duration.With("method", method, "success", strconv.FormatBool(err == nil)).Observe(time.Since(begin).Seconds())
+				if err != nil {
+					errs.With("method", method, "kind", ErrorKind(err)).Add(1)
+				}
+			}(time.Now())
+
+			return next(ctx, request)
+		}
+	}
+}
+
+//ErrorKind names error the same way ErrorEncoder tells them apart, unknown errors are internal
+func ErrorKind(err error) string {
+	kind, _ := classifyError(err)
+	return kind
+}
@@ -0,0 +1,25 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+)
+
+type LedgerRequest struct {
+	LedgerId string
+}
+
+func LedgerDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return LedgerRequest{LedgerId: chi.URLParam(r, "guid")}, nil
+}
+
+func LedgerGet(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(LedgerRequest)
+		return ws.GetLedger(ctx, req.LedgerId)
+	}
+}
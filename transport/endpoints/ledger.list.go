@@ -2,13 +2,113 @@ package endpoints
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/services"
 	"github.com/go-kit/kit/endpoint"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
 )
 
+//LedgerListDecoder reads ledgers filter from the query string
+func LedgerListDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return ParseLedgerFilter(r.URL.Query())
+}
+
+//ParseLedgerFilter reads ledgers filter from query values, transports other than http map their requests to them
+func ParseLedgerFilter(q url.Values) (*entities.LedgerFilter, error) {
+	var (
+		err    error
+		filter = &entities.LedgerFilter{
+			Account:      entities.AccountId(q.Get("account")),
+			Counterparty: entities.AccountId(q.Get("counterparty")),
+		}
+	)
+
+	if v := q.Get("direction"); v != "" {
+		direction, err := entities.ParseDirection(v)
+		if err != nil {
+			return nil, models.ValidationError{err}
+		}
+		filter.Direction = &direction
+	}
+
+	if v := q.Get("type"); v != "" {
+		paymentType, err := entities.ParsePaymentType(v)
+		if err != nil {
+			return nil, models.ValidationError{err}
+		}
+		filter.Type = &paymentType
+	}
+
+	if filter.MinAmount, err = parseNullDecimal(q.Get("min_amount")); err != nil {
+		return nil, models.ValidationError{xerrors.Errorf("wrong min_amount: %w", err)}
+	}
+
+	if filter.MaxAmount, err = parseNullDecimal(q.Get("max_amount")); err != nil {
+		return nil, models.ValidationError{xerrors.Errorf("wrong max_amount: %w", err)}
+	}
+
+	if filter.From, err = parseTime(q.Get("from")); err != nil {
+		return nil, models.ValidationError{xerrors.Errorf("wrong from: %w", err)}
+	}
+
+	if filter.To, err = parseTime(q.Get("to")); err != nil {
+		return nil, models.ValidationError{xerrors.Errorf("wrong to: %w", err)}
+	}
+
+	if filter.Pagination, err = parsePagination(q); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
 func LedgerList(ws services.Wallet) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		return ws.LedgersList(ctx)
+		return ws.LedgersList(ctx, request.(*entities.LedgerFilter))
 	}
 }
+
+//parsePagination reads cursor and limit query parameters
+func parsePagination(q url.Values) (p entities.Pagination, err error) {
+	if v := q.Get("cursor"); v != "" {
+		if p.Cursor, err = entities.ParseLedgerCursor(v); err != nil {
+			return p, models.ValidationError{err}
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if p.Limit, err = strconv.Atoi(v); err != nil || p.Limit == 0 {
+			return p, models.ValidationError{xerrors.Errorf("limit should be from 1 to %d", entities.MaxPageSize)}
+		}
+	}
+
+	return p, nil
+}
+
+func parseNullDecimal(v string) (decimal.NullDecimal, error) {
+	if v == "" {
+		return decimal.NullDecimal{}, nil
+	}
+
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return decimal.NullDecimal{}, err
+	}
+
+	return decimal.NullDecimal{Decimal: d, Valid: true}, nil
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, v)
+}
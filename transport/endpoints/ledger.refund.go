@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type RefundRequest struct {
+	LedgerId string         `json:"-"`
+	Amount   entities.Money `json:"amount"`
+}
+
+//RefundDecoder reads ledger from path, empty body or omitted amount refunds the rest of the ledger
+func RefundDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req RefundRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil && e != io.EOF {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	req.LedgerId = chi.URLParam(r, "guid")
+
+	return req, nil
+}
+
+func LedgerRefund(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RefundRequest)
+		return ws.Refund(ctx, req.LedgerId, req.Amount)
+	}
+}
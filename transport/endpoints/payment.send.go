@@ -6,17 +6,24 @@ import (
 	"net/http"
 
 	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/services"
 	"github.com/go-chi/chi"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/shopspring/decimal"
 	"golang.org/x/xerrors"
 )
 
+//IdempotencyKeyHeader holds client's key that makes payment retries safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLength = 255
+
 type SendRequest struct {
-	Sender   string          `json:"-"`
-	Receiver string          `json:"-"`
-	Amount   decimal.Decimal `json:"amount"`
+	Sender         string         `json:"-"`
+	Receiver       string         `json:"-"`
+	IdempotencyKey string         `json:"-"`
+	QuoteId        string         `json:"quote_id"`
+	Amount         entities.Money `json:"amount"`
 }
 
 func PaymentDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -28,12 +35,53 @@ func PaymentDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
 	req.Sender = chi.URLParam(r, "sender")
 	req.Receiver = chi.URLParam(r, "receiver")
 
+	if err := ValidateAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.IdempotencyKey, err = idempotencyKey(r); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
+func idempotencyKey(r *http.Request) (string, error) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if err := ValidateIdempotencyKey(key); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+//ValidateIdempotencyKey checks length of client's key
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLength {
+		return models.ValidationError{xerrors.Errorf("%s header is longer than %d symbols", IdempotencyKeyHeader, maxIdempotencyKeyLength)}
+	}
+
+	return nil
+}
+
+//ValidateAmount rejects zero and negative amounts and unknown currency, amount without currency
+//is in currency of the account and its precision is checked by service
+func ValidateAmount(amount entities.Money) error {
+	if !amount.IsPositive() {
+		return models.ValidationError{xerrors.New("amount should be positive")}
+	}
+
+	if amount.Currency != "" && !amount.Currency.Valid() {
+		return models.ValidationError{xerrors.Errorf("currency %s is unknown", amount.Currency)}
+	}
+
+	return nil
+}
+
 func PaymentSend(ws services.Wallet) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(SendRequest)
-		return ws.Send(ctx, req.Sender, req.Receiver, req.Amount)
+		return ws.Send(ctx, req.IdempotencyKey, req.QuoteId, req.Sender, req.Receiver, req.Amount)
 	}
 }
@@ -0,0 +1,41 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+type CreateQuoteRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func CreateQuoteDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CreateQuoteRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	if req.From == "" || len(req.From) > maxCurrencyLength {
+		return nil, models.ValidationError{xerrors.Errorf("from should be from 1 to %d symbols", maxCurrencyLength)}
+	}
+
+	if req.To == "" || len(req.To) > maxCurrencyLength {
+		return nil, models.ValidationError{xerrors.Errorf("to should be from 1 to %d symbols", maxCurrencyLength)}
+	}
+
+	return req, nil
+}
+
+func QuoteCreate(ws services.Wallet) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateQuoteRequest)
+		return ws.Quote(ctx, req.From, req.To)
+	}
+}
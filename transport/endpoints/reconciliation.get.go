@@ -0,0 +1,14 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func ReconciliationGet(rc services.Reconciler) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return rc.LastReport(ctx)
+	}
+}
@@ -0,0 +1,34 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/xerrors"
+)
+
+//CreateWebhookRequest leaves secret empty to have it generated
+type CreateWebhookRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+func CreateWebhookDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CreateWebhookRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		return nil, models.ValidationError{xerrors.Errorf("error while json decoding: %w", e)}
+	}
+
+	return req, nil
+}
+
+func WebhookCreate(ws services.Webhooks) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateWebhookRequest)
+		return ws.CreateWebhook(ctx, req.URL, req.Secret)
+	}
+}
@@ -0,0 +1,25 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+)
+
+type WebhookRequest struct {
+	WebhookId string
+}
+
+func WebhookDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	return WebhookRequest{WebhookId: chi.URLParam(r, "id")}, nil
+}
+
+func WebhookDelete(ws services.Webhooks) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookRequest)
+		return ws.DeleteWebhook(ctx, req.WebhookId)
+	}
+}
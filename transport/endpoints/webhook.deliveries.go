@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/endpoint"
+)
+
+type WebhookDeliveriesRequest struct {
+	WebhookId string
+	Status    entities.DeliveryStatus
+}
+
+//WebhookDeliveriesDecoder reads optional status, dead one lists the dead-letter deliveries
+func WebhookDeliveriesDecoder(ctx context.Context, r *http.Request) (interface{}, error) {
+	req := WebhookDeliveriesRequest{WebhookId: chi.URLParam(r, "id")}
+
+	if v := r.URL.Query().Get("status"); v != "" {
+		status, err := entities.ParseDeliveryStatus(v)
+		if err != nil {
+			return nil, models.ValidationError{err}
+		}
+		req.Status = status
+	}
+
+	return req, nil
+}
+
+func WebhookDeliveries(ws services.Webhooks) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookDeliveriesRequest)
+		return ws.WebhookDeliveries(ctx, req.WebhookId, req.Status)
+	}
+}
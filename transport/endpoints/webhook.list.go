@@ -0,0 +1,14 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func WebhookList(ws services.Webhooks) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return ws.ListWebhooks(ctx)
+	}
+}
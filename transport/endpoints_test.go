@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/NickRI/wallets-task/db/models"
 	"github.com/NickRI/wallets-task/domain/entities"
@@ -66,14 +68,16 @@ func Test_AccountListHandler(t *testing.T) {
 			wantCode: http.StatusOK,
 			want: entities.Accounts{
 				&entities.Account{
-					AccountId: "alice123",
-					Balance:   decimal.NewFromFloat(2.54),
-					Currency:  "USD",
+					AccountId:        "alice123",
+					Balance:          decimal.NewFromFloat(2.54),
+					AvailableBalance: decimal.NewFromFloat(2.54),
+					Currency:         "USD",
 				},
 				&entities.Account{
-					AccountId: "bob456",
-					Balance:   decimal.NewFromFloat(10.54),
-					Currency:  "USD",
+					AccountId:        "bob456",
+					Balance:          decimal.NewFromFloat(10.54),
+					AvailableBalance: decimal.NewFromFloat(10.54),
+					Currency:         "USD",
 				},
 			},
 		},
@@ -133,18 +137,65 @@ func Test_LedgerListHandler(t *testing.T) {
 	mockWallet := mock.NewMockWallet(ctrl)
 
 	testError := xerrors.New("some_error")
+	testValidationError := xerrors.New("some_validation_error")
+	testDirection := entities.Incoming
+	testType := entities.Deposit
+	testCursor := &entities.LedgerCursor{CreatedAt: time.Unix(1563580800, 0), Id: 42}
 
 	tests := []struct {
 		name     string
-		before   func(entities.Ledgers)
-		want     entities.Ledgers
+		query    string
+		before   func(*entities.LedgersPage)
+		want     *entities.LedgersPage
 		wantCode int
 		wantErr  string
 	}{
+		{
+			name:     "wrong direction",
+			query:    "account=alice123&direction=inside",
+			before:   func(want *entities.LedgersPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "wrong type of Direction only outgoing/incoming values allowed",
+		},
+		{
+			name:     "wrong type",
+			query:    "type=chargeback",
+			before:   func(want *entities.LedgersPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "wrong type of payment only transfer/deposit/withdrawal/refund values allowed",
+		},
+		{
+			name:     "wrong amount",
+			query:    "min_amount=ten",
+			before:   func(want *entities.LedgersPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "wrong min_amount: can't convert ten to decimal: exponent is not numeric",
+		},
+		{
+			name:     "wrong date",
+			query:    "from=yesterday",
+			before:   func(want *entities.LedgersPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  `wrong from: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`,
+		},
+		{
+			name:     "wrong cursor",
+			query:    "cursor=bm90LWEtY3Vyc29y",
+			before:   func(want *entities.LedgersPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "malformed cursor",
+		},
+		{
+			name:     "wrong limit",
+			query:    "limit=0",
+			before:   func(want *entities.LedgersPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "limit should be from 1 to 500",
+		},
 		{
 			name: "wallet returns error",
-			before: func(want entities.Ledgers) {
-				mockWallet.EXPECT().LedgersList(gomock.Any()).
+			before: func(want *entities.LedgersPage) {
+				mockWallet.EXPECT().LedgersList(gomock.Any(), &entities.LedgerFilter{}).
 					Return(want, testError)
 			},
 			wantCode: http.StatusInternalServerError,
@@ -152,49 +203,69 @@ func Test_LedgerListHandler(t *testing.T) {
 		},
 		{
 			name: "wallet returns db-error",
-			before: func(want entities.Ledgers) {
-				mockWallet.EXPECT().LedgersList(gomock.Any()).
+			before: func(want *entities.LedgersPage) {
+				mockWallet.EXPECT().LedgersList(gomock.Any(), &entities.LedgerFilter{}).
 					Return(want, models.DBErrorWrapper{testError})
 			},
 			wantCode: http.StatusInternalServerError,
 			wantErr:  testError.Error(),
 		},
 		{
-			name: "wallet returns ledgers normally",
-			before: func(want entities.Ledgers) {
-				mockWallet.EXPECT().LedgersList(gomock.Any()).
-					Return(want, nil)
+			name: "wallet returns validation error",
+			before: func(want *entities.LedgersPage) {
+				mockWallet.EXPECT().LedgersList(gomock.Any(), &entities.LedgerFilter{}).
+					Return(want, models.ValidationError{testValidationError})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  testValidationError.Error(),
+		},
+		{
+			name:  "wallet returns filtered ledgers page",
+			query: "account=bob456&counterparty=alice123&direction=incoming&type=deposit&max_amount=10.5&to=2019-07-20T00:00:00Z&limit=2&cursor=" + testCursor.String(),
+			before: func(want *entities.LedgersPage) {
+				mockWallet.EXPECT().LedgersList(gomock.Any(), &entities.LedgerFilter{
+					Account:      "bob456",
+					Counterparty: "alice123",
+					Direction:    &testDirection,
+					Type:         &testType,
+					MaxAmount:    decimal.NullDecimal{Decimal: decimal.RequireFromString("10.5"), Valid: true},
+					To:           time.Date(2019, 7, 20, 0, 0, 0, 0, time.UTC),
+					Pagination:   entities.Pagination{Cursor: testCursor, Limit: 2},
+				}).Return(want, nil)
 			},
 			wantCode: http.StatusOK,
-			want: entities.Ledgers{
-				&entities.Ledger{
-					&entities.Payment{
-						Account:   "alice123",
-						Amount:    decimal.NewFromFloat(2.54),
-						ToAccount: "bob456",
-						Direction: entities.Outgoing,
-					},
-					&entities.Payment{
-						Account:     "bob456",
-						Amount:      decimal.NewFromFloat(2.54),
-						FromAccount: "alice123",
-						Direction:   entities.Incoming,
-					},
-				},
-				&entities.Ledger{
-					&entities.Payment{
-						Account:   "bob456",
-						Amount:    decimal.NewFromFloat(22.54),
-						ToAccount: "alice123",
-						Direction: entities.Outgoing,
-					},
-					&entities.Payment{
-						Account:     "alice123",
-						Amount:      decimal.NewFromFloat(22.54),
-						FromAccount: "bob456",
-						Direction:   entities.Incoming,
-					},
+			want: &entities.LedgersPage{
+				Ledgers: entities.Ledgers{
+					&entities.Ledger{Payments: [2]*entities.Payment{
+						&entities.Payment{
+							Account:   "alice123",
+							Amount:    entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+							ToAccount: "bob456",
+							Direction: entities.Outgoing,
+						},
+						&entities.Payment{
+							Account:     "bob456",
+							Amount:      entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+							FromAccount: "alice123",
+							Direction:   entities.Incoming,
+						},
+					}},
+					&entities.Ledger{Payments: [2]*entities.Payment{
+						&entities.Payment{
+							Account:   "alice123",
+							Amount:    entities.NewMoney(decimal.NewFromFloat(5.54), "USD"),
+							ToAccount: "bob456",
+							Direction: entities.Outgoing,
+						},
+						&entities.Payment{
+							Account:     "bob456",
+							Amount:      entities.NewMoney(decimal.NewFromFloat(5.54), "USD"),
+							FromAccount: "alice123",
+							Direction:   entities.Incoming,
+						},
+					}},
 				},
+				NextCursor: "next_cursor",
 			},
 		},
 	}
@@ -208,7 +279,7 @@ func Test_LedgerListHandler(t *testing.T) {
 			tt.before(tt.want)
 			h := restapi.MakeHandlers(mockWallet, options...)
 
-			req := httptest.NewRequest("GET", "restapi://localhost/wallet/ledgers", nil)
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/ledgers?"+tt.query, nil)
 			w := httptest.NewRecorder()
 
 			h.ListLedgers.ServeHTTP(w, req)
@@ -220,8 +291,8 @@ func Test_LedgerListHandler(t *testing.T) {
 			}
 
 			respBody := struct {
-				Err  string           `json:"error"`
-				Data entities.Ledgers `json:"data"`
+				Err  string                `json:"error"`
+				Data *entities.LedgersPage `json:"data"`
 			}{}
 
 			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
@@ -232,15 +303,8 @@ func Test_LedgerListHandler(t *testing.T) {
 				t.Fatalf("LedgerListHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
 			}
 
-			if len(respBody.Data) != len(tt.want) {
-				t.Fatalf("LedgerListHandler() got = %v, want %v length", len(respBody.Data), len(tt.want))
-
-			}
-
-			for i := range respBody.Data {
-				if !reflect.DeepEqual(respBody.Data[i], tt.want[i]) {
-					t.Fatalf("LedgerListHandler() got = %v, want %v", respBody.Data[i], tt.want[i])
-				}
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("LedgerListHandler() got = %v, want %v", respBody.Data, tt.want)
 			}
 		})
 	}
@@ -256,10 +320,14 @@ func Test_PaymentSendHandler(t *testing.T) {
 	testNotFoundError := xerrors.New("some_error_not_found")
 	testLowBalanceError := xerrors.New("some_low_balance_error")
 	testSomeError := xerrors.New("some_error")
+	testConflictError := xerrors.New("some_idempotency_conflict_error")
+	testQuoteError := xerrors.New("some_quote_error")
 
 	type args struct {
 		from   string
 		to     string
+		key    string
+		quote  string
 		amount string
 	}
 
@@ -290,12 +358,26 @@ func Test_PaymentSendHandler(t *testing.T) {
 				amount: "13.5455",
 			},
 			before: func(a args, l *entities.Ledger) {
-				mockWallet.EXPECT().Send(gomock.Any(), a.from, a.to, decimal.RequireFromString(a.amount)).
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
 					Return(l, models.DBErrorWrapper{testDbError})
 			},
 			wantCode: http.StatusInternalServerError,
 			wantErr:  testDbError.Error(),
 		},
+		{
+			name: "wallet returns conflict",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				amount: "13.5455",
+			},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(l, models.ConflictError{models.DBErrorWrapper{testDbError}})
+			},
+			wantCode: http.StatusConflict,
+			wantErr:  testDbError.Error(),
+		},
 		{
 			name: "wallet returns not-found",
 			args: args{
@@ -304,7 +386,7 @@ func Test_PaymentSendHandler(t *testing.T) {
 				amount: "7.395",
 			},
 			before: func(a args, l *entities.Ledger) {
-				mockWallet.EXPECT().Send(gomock.Any(), a.from, a.to, decimal.RequireFromString(a.amount)).
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
 					Return(l, models.NotFoundWrapper{testNotFoundError})
 			},
 			wantCode: http.StatusNotFound,
@@ -318,12 +400,103 @@ func Test_PaymentSendHandler(t *testing.T) {
 				amount: "7.395",
 			},
 			before: func(a args, l *entities.Ledger) {
-				mockWallet.EXPECT().Send(gomock.Any(), a.from, a.to, decimal.RequireFromString(a.amount)).
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
 					Return(l, models.LowBalanceWrapper{testLowBalanceError})
 			},
 			wantCode: http.StatusPaymentRequired,
 			wantErr:  testLowBalanceError.Error(),
 		},
+		{
+			name: "wallet returns limit exceeded",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				amount: "7.395",
+			},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(l, models.LimitExceededError{xerrors.New("alice123: amount 7.395 exceeds daily limit, 5 is left")})
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantErr:  "alice123: amount 7.395 exceeds daily limit, 5 is left",
+		},
+		{
+			name: "negative amount",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				amount: "-7.39",
+			},
+			before:   func(a args, l *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "amount should be positive",
+		},
+		{
+			name: "unknown currency",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				amount: `{"amount": 7.39, "currency": "XYZ"}`,
+			},
+			before:   func(a args, l *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "currency XYZ is unknown",
+		},
+		{
+			name: "amount with currency",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				amount: `{"amount": "7.39", "currency": "USD"}`,
+			},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString("7.39"), "USD")).
+					Return(l, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "too long idempotency key",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				key:    strings.Repeat("k", 256),
+				amount: "7.395",
+			},
+			before:   func(a args, l *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "Idempotency-Key header is longer than 255 symbols",
+		},
+		{
+			name: "wallet returns idempotency conflict",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				key:    "a3c1d2b8-key",
+				amount: "7.395",
+			},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(l, models.IdempotencyConflictError{testConflictError})
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantErr:  testConflictError.Error(),
+		},
+		{
+			name: "wallet returns quote error",
+			args: args{
+				from:   "alice123",
+				to:     "bob456",
+				quote:  "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				amount: "7.395",
+			},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(l, models.QuoteError{testQuoteError})
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantErr:  testQuoteError.Error(),
+		},
 		{
 			name: "wallet returns some error",
 			args: args{
@@ -332,7 +505,7 @@ func Test_PaymentSendHandler(t *testing.T) {
 				amount: "7.395",
 			},
 			before: func(a args, l *entities.Ledger) {
-				mockWallet.EXPECT().Send(gomock.Any(), a.from, a.to, decimal.RequireFromString(a.amount)).
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
 					Return(l, testSomeError)
 			},
 			wantCode: http.StatusInternalServerError,
@@ -346,24 +519,24 @@ func Test_PaymentSendHandler(t *testing.T) {
 				amount: "17.395",
 			},
 			before: func(a args, l *entities.Ledger) {
-				mockWallet.EXPECT().Send(gomock.Any(), a.from, a.to, decimal.RequireFromString(a.amount)).
+				mockWallet.EXPECT().Send(gomock.Any(), a.key, a.quote, a.from, a.to, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
 					Return(l, nil)
 			},
 			wantCode: http.StatusOK,
-			want: entities.Ledger{
+			want: entities.Ledger{Payments: [2]*entities.Payment{
 				&entities.Payment{
 					Account:   "alice123",
-					Amount:    decimal.NewFromFloat(2.54),
+					Amount:    entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
 					ToAccount: "bob456",
 					Direction: entities.Outgoing,
 				},
 				&entities.Payment{
 					Account:     "bob456",
-					Amount:      decimal.NewFromFloat(2.54),
+					Amount:      entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
 					FromAccount: "alice123",
 					Direction:   entities.Incoming,
 				},
-			},
+			}},
 		},
 	}
 
@@ -376,9 +549,12 @@ func Test_PaymentSendHandler(t *testing.T) {
 			tt.before(tt.args, &tt.want)
 			h := restapi.MakeHandlers(mockWallet, options...)
 
-			body := bytes.NewBufferString(`{"amount": ` + tt.args.amount + `}`)
+			body := bytes.NewBufferString(`{"quote_id": "` + tt.args.quote + `", "amount": ` + tt.args.amount + `}`)
 
 			req := httptest.NewRequest("POST", "restapi://localhost/"+tt.args.from+"/"+tt.args.to, body)
+			if tt.args.key != "" {
+				req.Header.Set(endpoints.IdempotencyKeyHeader, tt.args.key)
+			}
 			w := httptest.NewRecorder()
 
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
@@ -409,14 +585,1893 @@ func Test_PaymentSendHandler(t *testing.T) {
 				t.Fatalf("PaymentSendHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
 			}
 
-			if len(respBody.Data) != len(tt.want) {
-				t.Fatalf("PaymentSendHandler() got = %v, want %v length", len(respBody.Data), len(tt.want))
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("PaymentSendHandler() got = %v, want %v", respBody.Data, tt.want)
 			}
+		})
+	}
+}
 
-			for i := range respBody.Data {
-				if !reflect.DeepEqual(respBody.Data[i], tt.want[i]) {
-					t.Fatalf("PaymentSendHandler() got = %v, want %v", respBody.Data[i], tt.want[i])
-				}
+func Test_FundsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+	testLowBalanceError := xerrors.New("some_low_balance_error")
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+	h := restapi.MakeHandlers(mockWallet, options...)
+
+	type args struct {
+		id     string
+		key    string
+		amount string
+	}
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		args     args
+		before   func(args, *entities.Ledger)
+		want     entities.Ledger
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			handler:  h.Deposit,
+			args:     args{id: "bob456", amount: `"ten"`},
+			before:   func(a args, l *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: Error decoding string 'ten': can't convert ten to decimal: exponent is not numeric",
+		},
+		{
+			name:     "zero amount",
+			handler:  h.Deposit,
+			args:     args{id: "bob456", amount: "0"},
+			before:   func(a args, l *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "amount should be positive",
+		},
+		{
+			name:     "too long idempotency key",
+			handler:  h.Withdraw,
+			args:     args{id: "bob456", amount: "1", key: strings.Repeat("k", 256)},
+			before:   func(a args, l *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "Idempotency-Key header is longer than 255 symbols",
+		},
+		{
+			name:    "deposit to unknown account",
+			handler: h.Deposit,
+			args:    args{id: "bob4567", amount: "1"},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Deposit(gomock.Any(), a.key, a.id, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(nil, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name:    "withdraw more than balance",
+			handler: h.Withdraw,
+			args:    args{id: "bob456", amount: "1000"},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Withdraw(gomock.Any(), a.key, a.id, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(nil, models.LowBalanceWrapper{testLowBalanceError})
+			},
+			wantCode: http.StatusPaymentRequired,
+			wantErr:  testLowBalanceError.Error(),
+		},
+		{
+			name:    "deposit works fine",
+			handler: h.Deposit,
+			args:    args{id: "bob456", amount: "2.54", key: "deposit-key"},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Deposit(gomock.Any(), a.key, a.id, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(l, nil)
+			},
+			wantCode: http.StatusOK,
+			want: entities.Ledger{Payments: [2]*entities.Payment{
+				&entities.Payment{
+					Account:   "settlement:USD",
+					Amount:    entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+					ToAccount: "bob456",
+					Direction: entities.Outgoing,
+					Type:      entities.Deposit,
+				},
+				&entities.Payment{
+					Account:     "bob456",
+					Amount:      entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+					FromAccount: "settlement:USD",
+					Direction:   entities.Incoming,
+					Type:        entities.Deposit,
+				},
+			}},
+		},
+		{
+			name:    "withdraw works fine",
+			handler: h.Withdraw,
+			args:    args{id: "bob456", amount: "2.54"},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Withdraw(gomock.Any(), a.key, a.id, entities.NewMoney(decimal.RequireFromString(a.amount), "")).
+					Return(l, nil)
+			},
+			wantCode: http.StatusOK,
+			want: entities.Ledger{Payments: [2]*entities.Payment{
+				&entities.Payment{
+					Account:   "bob456",
+					Amount:    entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+					ToAccount: "settlement:USD",
+					Direction: entities.Outgoing,
+					Type:      entities.Withdrawal,
+				},
+				&entities.Payment{
+					Account:     "settlement:USD",
+					Amount:      entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+					FromAccount: "bob456",
+					Direction:   entities.Incoming,
+					Type:        entities.Withdrawal,
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.args, &tt.want)
+
+			body := bytes.NewBufferString(`{"amount": ` + tt.args.amount + `}`)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/accounts/"+tt.args.id, body)
+			if tt.args.key != "" {
+				req.Header.Set(endpoints.IdempotencyKeyHeader, tt.args.key)
+			}
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.args.id},
+				},
+			}))
+
+			tt.handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("FundsHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string          `json:"error"`
+				Data entities.Ledger `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("FundsHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("FundsHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateAccountHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testDbError := xerrors.New("some_db_error")
+	testExistsError := xerrors.New("some_already_exists_error")
+
+	tests := []struct {
+		name     string
+		body     string
+		before   func(*entities.Account)
+		want     *entities.Account
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			body:     `{"id": 123}`,
+			before:   func(*entities.Account) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: json: cannot unmarshal number into Go struct field CreateAccountRequest.id of type string",
+		},
+		{
+			name:     "empty account id",
+			body:     `{"currency": "USD"}`,
+			before:   func(*entities.Account) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "id should be from 1 to 64 symbols",
+		},
+		{
+			name:     "too long currency",
+			body:     `{"id": "carol789", "currency": "USDUSD"}`,
+			before:   func(*entities.Account) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "currency should be from 1 to 4 symbols",
+		},
+		{
+			name:     "unknown currency",
+			body:     `{"id": "carol789", "currency": "XYZ"}`,
+			before:   func(*entities.Account) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "currency XYZ is unknown",
+		},
+		{
+			name: "wallet returns db-error",
+			body: `{"id": "carol789", "currency": "USD"}`,
+			before: func(want *entities.Account) {
+				mockWallet.EXPECT().CreateAccount(gomock.Any(), "carol789", "USD").
+					Return(want, models.DBErrorWrapper{testDbError})
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  testDbError.Error(),
+		},
+		{
+			name: "wallet returns already exists",
+			body: `{"id": "alice123", "currency": "USD"}`,
+			before: func(want *entities.Account) {
+				mockWallet.EXPECT().CreateAccount(gomock.Any(), "alice123", "USD").
+					Return(want, models.AccountStateError{testExistsError})
+			},
+			wantCode: http.StatusConflict,
+			wantErr:  testExistsError.Error(),
+		},
+		{
+			name: "wallet creates account normally",
+			body: `{"id": "carol789", "currency": "USD"}`,
+			before: func(want *entities.Account) {
+				mockWallet.EXPECT().CreateAccount(gomock.Any(), "carol789", "USD").
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Account{
+				AccountId:        "carol789",
+				Balance:          decimal.New(0, 0),
+				AvailableBalance: decimal.New(0, 0),
+				Currency:         "USD",
+				Status:           entities.AccountActive,
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/accounts", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.CreateAccount.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("CreateAccountHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string            `json:"error"`
+				Data *entities.Account `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("CreateAccountHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("CreateAccountHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateQuoteHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_rate_not_found")
+	testValidationError := xerrors.New("some_validation_error")
+
+	tests := []struct {
+		name     string
+		body     string
+		before   func(*entities.FXQuote)
+		want     *entities.FXQuote
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			body:     `{"from": 1}`,
+			before:   func(*entities.FXQuote) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: json: cannot unmarshal number into Go struct field CreateQuoteRequest.from of type string",
+		},
+		{
+			name:     "empty target currency",
+			body:     `{"from": "USD"}`,
+			before:   func(*entities.FXQuote) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "to should be from 1 to 4 symbols",
+		},
+		{
+			name: "wallet returns validation error",
+			body: `{"from": "USD", "to": "USD"}`,
+			before: func(want *entities.FXQuote) {
+				mockWallet.EXPECT().Quote(gomock.Any(), "USD", "USD").
+					Return(want, models.ValidationError{testValidationError})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  testValidationError.Error(),
+		},
+		{
+			name: "wallet returns not-found",
+			body: `{"from": "USD", "to": "XXX"}`,
+			before: func(want *entities.FXQuote) {
+				mockWallet.EXPECT().Quote(gomock.Any(), "USD", "XXX").
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name: "wallet creates quote normally",
+			body: `{"from": "USD", "to": "EUR"}`,
+			before: func(want *entities.FXQuote) {
+				mockWallet.EXPECT().Quote(gomock.Any(), "USD", "EUR").
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.FXQuote{
+				Id:        "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				FXRate:    entities.FXRate{From: "USD", To: "EUR", Rate: decimal.RequireFromString("0.9")},
+				ExpiresAt: time.Date(2019, 7, 19, 0, 1, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/quotes", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.CreateQuote.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("CreateQuoteHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string            `json:"error"`
+				Data *entities.FXQuote `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("CreateQuoteHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("CreateQuoteHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLedgerHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+
+	tests := []struct {
+		name     string
+		guid     string
+		before   func(string, *entities.Ledger)
+		want     *entities.Ledger
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "wallet returns not-found",
+			guid: "123",
+			before: func(guid string, want *entities.Ledger) {
+				mockWallet.EXPECT().GetLedger(gomock.Any(), guid).
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name: "wallet returns ledger normally",
+			guid: "e9b0c72f-c08f-4e00-b158-42ae88f0c18e",
+			before: func(guid string, want *entities.Ledger) {
+				mockWallet.EXPECT().GetLedger(gomock.Any(), guid).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Ledger{
+				Id:        "e9b0c72f-c08f-4e00-b158-42ae88f0c18e",
+				CreatedAt: time.Date(2019, 8, 9, 10, 0, 0, 0, time.UTC),
+				Payments: [2]*entities.Payment{
+					&entities.Payment{Ledger: "e9b0c72f-c08f-4e00-b158-42ae88f0c18e", Account: "alice123", Amount: entities.NewMoney(decimal.NewFromFloat(-2.54), "USD"), ToAccount: "bob456", Direction: entities.Outgoing, Type: entities.Transfer},
+					&entities.Payment{Ledger: "e9b0c72f-c08f-4e00-b158-42ae88f0c18e", Account: "bob456", Amount: entities.NewMoney(decimal.NewFromFloat(2.54), "USD"), FromAccount: "alice123", Direction: entities.Incoming, Type: entities.Transfer},
+				},
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.guid, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/ledgers/"+tt.guid, nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"guid"},
+					Values: []string{tt.guid},
+				},
+			}))
+
+			h.GetLedger.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("GetLedgerHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string           `json:"error"`
+				Data *entities.Ledger `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("GetLedgerHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("GetLedgerHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetAccountHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+
+	tests := []struct {
+		name     string
+		id       string
+		before   func(string, *entities.Account)
+		want     *entities.Account
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "wallet returns not-found",
+			id:   "alice1234",
+			before: func(id string, want *entities.Account) {
+				mockWallet.EXPECT().GetAccount(gomock.Any(), id).
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name: "wallet returns account normally",
+			id:   "alice123",
+			before: func(id string, want *entities.Account) {
+				mockWallet.EXPECT().GetAccount(gomock.Any(), id).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Account{
+				AccountId:        "alice123",
+				Balance:          decimal.NewFromFloat(2.54),
+				AvailableBalance: decimal.NewFromFloat(2.54),
+				Currency:         "USD",
+				Status:           entities.AccountFrozen,
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.id, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/accounts/"+tt.id, nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.id},
+				},
+			}))
+
+			h.GetAccount.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("GetAccountHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string            `json:"error"`
+				Data *entities.Account `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("GetAccountHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("GetAccountHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AccountLimitsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+	testDaily, testRemaining := decimal.RequireFromString("100"), decimal.RequireFromString("25.5")
+
+	tests := []struct {
+		name     string
+		id       string
+		before   func(string, *entities.Allowance)
+		want     *entities.Allowance
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "wallet returns not-found",
+			id:   "alice1234",
+			before: func(id string, want *entities.Allowance) {
+				mockWallet.EXPECT().Allowance(gomock.Any(), id).
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name: "wallet returns allowance normally",
+			id:   "alice123",
+			before: func(id string, want *entities.Allowance) {
+				mockWallet.EXPECT().Allowance(gomock.Any(), id).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Allowance{
+				AccountId:      "alice123",
+				Currency:       "USD",
+				Limits:         entities.Limits{DailyAmount: &testDaily},
+				DailyRemaining: &testRemaining,
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.id, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/accounts/"+tt.id+"/limits", nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.id},
+				},
+			}))
+
+			h.AccountLimits.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("AccountLimitsHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string              `json:"error"`
+				Data *entities.Allowance `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("AccountLimitsHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("AccountLimitsHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AccountBalanceHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+	testAt := time.Date(2019, 7, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		id       string
+		query    string
+		before   func(string, *entities.AccountBalance)
+		want     *entities.AccountBalance
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong at",
+			id:       "bob456",
+			query:    "?at=yesterday",
+			before:   func(string, *entities.AccountBalance) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  `wrong at: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`,
+		},
+		{
+			name:  "wallet returns not-found",
+			id:    "bob4567",
+			query: "?at=2019-07-31T23:59:59Z",
+			before: func(id string, want *entities.AccountBalance) {
+				mockWallet.EXPECT().BalanceAt(gomock.Any(), id, testAt).
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name: "at is omitted",
+			id:   "bob456",
+			before: func(id string, want *entities.AccountBalance) {
+				mockWallet.EXPECT().BalanceAt(gomock.Any(), id, time.Time{}).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want:     &entities.AccountBalance{AccountId: "bob456", Balance: decimal.NewFromFloat(1000.5), Currency: "USD", At: testAt},
+		},
+		{
+			name:  "wallet returns balance normally",
+			id:    "bob456",
+			query: "?at=2019-07-31T23:59:59Z",
+			before: func(id string, want *entities.AccountBalance) {
+				mockWallet.EXPECT().BalanceAt(gomock.Any(), id, testAt).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want:     &entities.AccountBalance{AccountId: "bob456", Balance: decimal.NewFromFloat(995.5), Currency: "USD", At: testAt},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.id, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/accounts/"+tt.id+"/balance"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.id},
+				},
+			}))
+
+			h.AccountBalance.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("AccountBalanceHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string                   `json:"error"`
+				Data *entities.AccountBalance `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("AccountBalanceHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("AccountBalanceHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AccountLedgersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+	testCursor := &entities.LedgerCursor{CreatedAt: time.Unix(1563494400, 0), Id: 3}
+	testBalance := entities.NewMoney(decimal.NewFromFloat(2.54), "USD")
+
+	tests := []struct {
+		name     string
+		id       string
+		query    string
+		before   func(string, *entities.PaymentsPage)
+		want     *entities.PaymentsPage
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong limit",
+			id:       "bob456",
+			query:    "limit=abc",
+			before:   func(id string, want *entities.PaymentsPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "limit should be from 1 to 500",
+		},
+		{
+			name:     "wrong cursor",
+			id:       "bob456",
+			query:    "cursor=abc",
+			before:   func(id string, want *entities.PaymentsPage) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "malformed cursor",
+		},
+		{
+			name: "wallet returns not-found",
+			id:   "bob4567",
+			before: func(id string, want *entities.PaymentsPage) {
+				mockWallet.EXPECT().AccountLedgers(gomock.Any(), id, &entities.Pagination{}).
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name:  "wallet returns payments page",
+			id:    "bob456",
+			query: "limit=1&cursor=" + testCursor.String(),
+			before: func(id string, want *entities.PaymentsPage) {
+				mockWallet.EXPECT().AccountLedgers(gomock.Any(), id, &entities.Pagination{Cursor: testCursor, Limit: 1}).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.PaymentsPage{
+				Payments: entities.Payments{
+					&entities.Payment{
+						Account:      "bob456",
+						Amount:       entities.NewMoney(decimal.NewFromFloat(1.5), "USD"),
+						FromAccount:  "alice123",
+						Direction:    entities.Incoming,
+						BalanceAfter: &testBalance,
+					},
+				},
+				NextCursor: "next_cursor",
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.id, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/accounts/"+tt.id+"/ledgers?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.id},
+				},
+			}))
+
+			h.AccountLedgers.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("AccountLedgersHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string                 `json:"error"`
+				Data *entities.PaymentsPage `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("AccountLedgersHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("AccountLedgersHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ChangeAccountStatusHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testStateError := xerrors.New("some_account_state_error")
+
+	type args struct {
+		id   string
+		body string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		before   func(args, *entities.Account)
+		want     *entities.Account
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "unknown status",
+			args:     args{id: "alice123", body: `{"status": "deleted"}`},
+			before:   func(args, *entities.Account) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  `wrong status "deleted" only active/frozen/closed values allowed`,
+		},
+		{
+			name: "wallet returns state error",
+			args: args{id: "alice123", body: `{"status": "closed"}`},
+			before: func(a args, want *entities.Account) {
+				mockWallet.EXPECT().ChangeAccountStatus(gomock.Any(), a.id, entities.AccountClosed).
+					Return(want, models.AccountStateError{testStateError})
+			},
+			wantCode: http.StatusConflict,
+			wantErr:  testStateError.Error(),
+		},
+		{
+			name: "wallet changes status normally",
+			args: args{id: "alice123", body: `{"status": "frozen"}`},
+			before: func(a args, want *entities.Account) {
+				mockWallet.EXPECT().ChangeAccountStatus(gomock.Any(), a.id, entities.AccountFrozen).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Account{
+				AccountId:        "alice123",
+				Balance:          decimal.NewFromFloat(2.54),
+				AvailableBalance: decimal.NewFromFloat(2.54),
+				Currency:         "USD",
+				Status:           entities.AccountFrozen,
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.args, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("PUT", "restapi://localhost/wallet/accounts/"+tt.args.id+"/status", bytes.NewBufferString(tt.args.body))
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.args.id},
+				},
+			}))
+
+			h.ChangeAccountStatus.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("ChangeAccountStatusHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string            `json:"error"`
+				Data *entities.Account `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("ChangeAccountStatusHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("ChangeAccountStatusHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AuthorizeHoldHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testLowBalanceError := xerrors.New("some_low_balance_error")
+
+	tests := []struct {
+		name     string
+		body     string
+		before   func(*entities.Hold)
+		want     *entities.Hold
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			body:     `{"from": 1}`,
+			before:   func(*entities.Hold) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: json: cannot unmarshal number into Go struct field AuthorizeRequest.from of type string",
+		},
+		{
+			name:     "empty receiver",
+			body:     `{"from": "alice123", "amount": 1}`,
+			before:   func(*entities.Hold) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "from and to accounts are required",
+		},
+		{
+			name:     "negative amount",
+			body:     `{"from": "alice123", "to": "bob456", "amount": -1}`,
+			before:   func(*entities.Hold) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "amount should be positive",
+		},
+		{
+			name: "wallet returns low balance",
+			body: `{"from": "alice123", "to": "bob456", "amount": 100}`,
+			before: func(want *entities.Hold) {
+				mockWallet.EXPECT().Authorize(gomock.Any(), "alice123", "bob456", entities.NewMoney(decimal.RequireFromString("100"), "")).
+					Return(want, models.LowBalanceWrapper{testLowBalanceError})
+			},
+			wantCode: http.StatusPaymentRequired,
+			wantErr:  testLowBalanceError.Error(),
+		},
+		{
+			name: "wallet authorizes hold normally",
+			body: `{"from": "alice123", "to": "bob456", "amount": 2.54}`,
+			before: func(want *entities.Hold) {
+				mockWallet.EXPECT().Authorize(gomock.Any(), "alice123", "bob456", entities.NewMoney(decimal.RequireFromString("2.54"), "")).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Hold{
+				Id:        "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				Account:   "alice123",
+				ToAccount: "bob456",
+				Amount:    entities.NewMoney(decimal.RequireFromString("2.54"), "USD"),
+				Status:    entities.HoldActive,
+				ExpiresAt: time.Date(2019, 7, 26, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/holds", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.AuthorizeHold.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("AuthorizeHoldHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string         `json:"error"`
+				Data *entities.Hold `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("AuthorizeHoldHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("AuthorizeHoldHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CaptureHoldHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testStateError := xerrors.New("some_hold_state_error")
+
+	type args struct {
+		id   string
+		body string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		before   func(args, *entities.Ledger)
+		want     entities.Ledger
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			args:     args{id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", body: `{"amount": "abc"}`},
+			before:   func(args, *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: Error decoding string 'abc': can't convert abc to decimal",
+		},
+		{
+			name: "wallet returns state error",
+			args: args{id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665"},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Capture(gomock.Any(), a.id, entities.Money{}).
+					Return(l, models.AccountStateError{testStateError})
+			},
+			wantCode: http.StatusConflict,
+			wantErr:  testStateError.Error(),
+		},
+		{
+			name: "wallet captures hold normally",
+			args: args{id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", body: `{"amount": 2.54}`},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Capture(gomock.Any(), a.id, entities.NewMoney(decimal.RequireFromString("2.54"), "")).
+					Return(l, nil)
+			},
+			wantCode: http.StatusOK,
+			want: entities.Ledger{Payments: [2]*entities.Payment{
+				&entities.Payment{
+					Account:   "alice123",
+					Amount:    entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+					ToAccount: "bob456",
+					Direction: entities.Outgoing,
+				},
+				&entities.Payment{
+					Account:     "bob456",
+					Amount:      entities.NewMoney(decimal.NewFromFloat(2.54), "USD"),
+					FromAccount: "alice123",
+					Direction:   entities.Incoming,
+				},
+			}},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.args, &tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/holds/"+tt.args.id+"/capture", bytes.NewBufferString(tt.args.body))
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.args.id},
+				},
+			}))
+
+			h.CaptureHold.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("CaptureHoldHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string          `json:"error"`
+				Data entities.Ledger `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("CaptureHoldHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("CaptureHoldHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_VoidHoldHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testNotFoundError := xerrors.New("some_error_not_found")
+
+	tests := []struct {
+		name     string
+		id       string
+		before   func(string, *entities.Hold)
+		want     *entities.Hold
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "wallet returns not-found",
+			id:   "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+			before: func(id string, want *entities.Hold) {
+				mockWallet.EXPECT().Void(gomock.Any(), id).
+					Return(want, models.NotFoundWrapper{testNotFoundError})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  testNotFoundError.Error(),
+		},
+		{
+			name: "wallet voids hold normally",
+			id:   "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+			before: func(id string, want *entities.Hold) {
+				mockWallet.EXPECT().Void(gomock.Any(), id).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Hold{
+				Id:        "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				Account:   "alice123",
+				ToAccount: "bob456",
+				Amount:    entities.NewMoney(decimal.RequireFromString("2.54"), "USD"),
+				Status:    entities.HoldVoided,
+				ExpiresAt: time.Date(2019, 7, 26, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.id, tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/holds/"+tt.id+"/void", nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.id},
+				},
+			}))
+
+			h.VoidHold.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("VoidHoldHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string         `json:"error"`
+				Data *entities.Hold `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("VoidHoldHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("VoidHoldHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RefundLedgerHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testLowBalanceError := xerrors.New("some_low_balance_error")
+
+	type args struct {
+		guid string
+		body string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		before   func(args, *entities.Ledger)
+		want     entities.Ledger
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			args:     args{guid: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", body: `{"amount": "abc"}`},
+			before:   func(args, *entities.Ledger) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: Error decoding string 'abc': can't convert abc to decimal",
+		},
+		{
+			name: "wallet returns low balance",
+			args: args{guid: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", body: `{"amount": 2.54}`},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Refund(gomock.Any(), a.guid, entities.NewMoney(decimal.RequireFromString("2.54"), "")).
+					Return(l, models.LowBalanceWrapper{testLowBalanceError})
+			},
+			wantCode: http.StatusPaymentRequired,
+			wantErr:  testLowBalanceError.Error(),
+		},
+		{
+			name: "wallet refunds the whole ledger",
+			args: args{guid: "c5417ca1-c06b-4a45-9cd9-85936d4b9665"},
+			before: func(a args, l *entities.Ledger) {
+				mockWallet.EXPECT().Refund(gomock.Any(), a.guid, entities.Money{}).
+					Return(l, nil)
+			},
+			wantCode: http.StatusOK,
+			want: entities.Ledger{Payments: [2]*entities.Payment{
+				&entities.Payment{
+					Ledger:    "a1b2c3d4-c06b-4a45-9cd9-85936d4b9665",
+					Account:   "bob456",
+					Amount:    entities.NewMoney(decimal.RequireFromString("-2.54"), "USD"),
+					ToAccount: "alice123",
+					Direction: entities.Outgoing,
+					Type:      entities.Refund,
+					RefundOf:  "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				},
+				&entities.Payment{
+					Ledger:      "a1b2c3d4-c06b-4a45-9cd9-85936d4b9665",
+					Account:     "alice123",
+					Amount:      entities.NewMoney(decimal.RequireFromString("2.54"), "USD"),
+					FromAccount: "bob456",
+					Direction:   entities.Incoming,
+					Type:        entities.Refund,
+					RefundOf:    "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				},
+			}},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.args, &tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/ledgers/"+tt.args.guid+"/refund", bytes.NewBufferString(tt.args.body))
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"guid"},
+					Values: []string{tt.args.guid},
+				},
+			}))
+
+			h.RefundLedger.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("RefundLedgerHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string          `json:"error"`
+				Data entities.Ledger `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("RefundLedgerHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("RefundLedgerHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateBatchHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	testLowBalanceError := xerrors.New("leg 1: some_low_balance_error")
+
+	legs := []*entities.TransferLeg{
+		{From: "alice123", To: "bob456", Amount: entities.NewMoney(decimal.RequireFromString("2.54"), "")},
+		{From: "alice123", To: "carl789", Amount: entities.NewMoney(decimal.RequireFromString("1"), "")},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		before   func(*entities.Batch)
+		want     *entities.Batch
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong body",
+			body:     `{"legs": {}}`,
+			before:   func(*entities.Batch) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: json: cannot unmarshal object into Go struct field BatchRequest.legs of type []*entities.TransferLeg",
+		},
+		{
+			name:     "leg without receiver",
+			body:     `{"legs": [{"from": "alice123", "to": "bob456", "amount": 1}, {"from": "alice123", "amount": 1}]}`,
+			before:   func(*entities.Batch) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "leg 1: from and to accounts are required",
+		},
+		{
+			name: "wallet returns low balance of leg",
+			body: `{"legs": [{"from": "alice123", "to": "bob456", "amount": 2.54}, {"from": "alice123", "to": "carl789", "amount": 1}]}`,
+			before: func(want *entities.Batch) {
+				mockWallet.EXPECT().Batch(gomock.Any(), legs).
+					Return(want, models.LowBalanceWrapper{testLowBalanceError})
+			},
+			wantCode: http.StatusPaymentRequired,
+			wantErr:  testLowBalanceError.Error(),
+		},
+		{
+			name: "wallet makes batch normally",
+			body: `{"legs": [{"from": "alice123", "to": "bob456", "amount": 2.54}, {"from": "alice123", "to": "carl789", "amount": 1}]}`,
+			before: func(want *entities.Batch) {
+				mockWallet.EXPECT().Batch(gomock.Any(), legs).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Batch{
+				Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				Ledgers: entities.Ledgers{
+					&entities.Ledger{Payments: [2]*entities.Payment{
+						&entities.Payment{Account: "alice123", Amount: entities.NewMoney(decimal.RequireFromString("-2.54"), "USD"), ToAccount: "bob456",
+							Direction: entities.Outgoing, Type: entities.Transfer, Batch: "c5417ca1-c06b-4a45-9cd9-85936d4b9665"},
+						&entities.Payment{Account: "bob456", Amount: entities.NewMoney(decimal.RequireFromString("2.54"), "USD"), FromAccount: "alice123",
+							Direction: entities.Incoming, Type: entities.Transfer, Batch: "c5417ca1-c06b-4a45-9cd9-85936d4b9665"},
+					}},
+				},
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeHandlers(mockWallet, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/batches", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.CreateBatch.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("CreateBatchHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string          `json:"error"`
+				Data *entities.Batch `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("CreateBatchHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("CreateBatchHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ReconciliationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReconciler := mock.NewMockReconciler(ctrl)
+
+	testStartedAt := time.Date(2019, 8, 9, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		before   func(*entities.ReconciliationReport)
+		want     *entities.ReconciliationReport
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "reconciliation has not run yet",
+			before: func(want *entities.ReconciliationReport) {
+				mockReconciler.EXPECT().LastReport(gomock.Any()).
+					Return(want, models.NotFoundWrapper{xerrors.New("reconciliation has not run yet")})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  "reconciliation has not run yet",
+		},
+		{
+			name: "returns latest report",
+			before: func(want *entities.ReconciliationReport) {
+				mockReconciler.EXPECT().LastReport(gomock.Any()).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.ReconciliationReport{
+				StartedAt:  testStartedAt,
+				FinishedAt: testStartedAt.Add(time.Second),
+				Discrepancies: entities.Discrepancies{
+					{Kind: entities.BalanceMismatch, Account: "bob456", Currency: "USD", Expected: decimal.NewFromFloat(995.5), Actual: decimal.NewFromFloat(996.5)},
+				},
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeAdminHandlers(mockReconciler, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/admin/reconciliation", nil)
+			w := httptest.NewRecorder()
+
+			h.Reconciliation.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("ReconciliationHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string                         `json:"error"`
+				Data *entities.ReconciliationReport `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("ReconciliationHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("ReconciliationHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CreateWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhookService(ctrl)
+
+	tests := []struct {
+		name     string
+		body     string
+		before   func(*entities.Webhook)
+		want     *entities.Webhook
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong json",
+			body:     `{"url":`,
+			before:   func(*entities.Webhook) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "error while json decoding: unexpected EOF",
+		},
+		{
+			name: "service returns validation error",
+			body: `{"url":"/hooks"}`,
+			before: func(want *entities.Webhook) {
+				mockWebhooks.EXPECT().CreateWebhook(gomock.Any(), "/hooks", "").
+					Return(want, models.ValidationError{xerrors.New(`url "/hooks" should be absolute http or https one`)})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  `url "/hooks" should be absolute http or https one`,
+		},
+		{
+			name: "service creates webhook normally",
+			body: `{"url":"https://example.com/hooks","secret":"0123456789abcdef"}`,
+			before: func(want *entities.Webhook) {
+				mockWebhooks.EXPECT().CreateWebhook(gomock.Any(), "https://example.com/hooks", "0123456789abcdef").
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Webhook{
+				Id:        "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				URL:       "https://example.com/hooks",
+				Secret:    "0123456789abcdef",
+				Active:    true,
+				CreatedAt: time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeWebhookHandlers(mockWebhooks, options...)
+
+			req := httptest.NewRequest("POST", "restapi://localhost/wallet/webhooks", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.CreateWebhook.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("CreateWebhookHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string            `json:"error"`
+				Data *entities.Webhook `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("CreateWebhookHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("CreateWebhookHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DeleteWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhookService(ctrl)
+
+	tests := []struct {
+		name     string
+		id       string
+		before   func(string, *entities.Webhook)
+		want     *entities.Webhook
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name: "service returns not-found",
+			id:   "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+			before: func(id string, want *entities.Webhook) {
+				mockWebhooks.EXPECT().DeleteWebhook(gomock.Any(), id).
+					Return(want, models.NotFoundWrapper{xerrors.Errorf("webhook %s not found", id)})
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  "webhook c5417ca1-c06b-4a45-9cd9-85936d4b9665 not found",
+		},
+		{
+			name: "service deletes webhook normally",
+			id:   "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+			before: func(id string, want *entities.Webhook) {
+				mockWebhooks.EXPECT().DeleteWebhook(gomock.Any(), id).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: &entities.Webhook{
+				Id:        "c5417ca1-c06b-4a45-9cd9-85936d4b9665",
+				URL:       "https://example.com/hooks",
+				CreatedAt: time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.id, tt.want)
+			h := restapi.MakeWebhookHandlers(mockWebhooks, options...)
+
+			req := httptest.NewRequest("DELETE", "restapi://localhost/wallet/webhooks/"+tt.id, nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{tt.id},
+				},
+			}))
+
+			h.DeleteWebhook.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("DeleteWebhookHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string            `json:"error"`
+				Data *entities.Webhook `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("DeleteWebhookHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("DeleteWebhookHandler() got = %v, want %v", respBody.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_WebhookDeliveriesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhooks := mock.NewMockWebhookService(ctrl)
+
+	testId := "c5417ca1-c06b-4a45-9cd9-85936d4b9665"
+	testTime := time.Date(2019, 8, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    string
+		before   func(entities.Deliveries)
+		want     entities.Deliveries
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "wrong status",
+			query:    "?status=failed",
+			before:   func(entities.Deliveries) {},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "wrong status of delivery only pending/delivered/dead values allowed",
+		},
+		{
+			name:  "service returns dead deliveries",
+			query: "?status=dead",
+			before: func(want entities.Deliveries) {
+				mockWebhooks.EXPECT().WebhookDeliveries(gomock.Any(), testId, entities.DeliveryDead).
+					Return(want, nil)
+			},
+			wantCode: http.StatusOK,
+			want: entities.Deliveries{
+				{
+					Event:         &entities.Event{Id: testId, Type: entities.PaymentCompleted, CreatedAt: testTime, Data: json.RawMessage(`{"id":"e9b0c72f-c08f-4e00-b158-42ae88f0c18e"}`)},
+					Status:        entities.DeliveryDead,
+					Attempts:      10,
+					NextAttemptAt: testTime,
+					LastError:     "webhook responded with 500 Internal Server Error",
+				},
+			},
+		},
+	}
+
+	options := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before(tt.want)
+			h := restapi.MakeWebhookHandlers(mockWebhooks, options...)
+
+			req := httptest.NewRequest("GET", "restapi://localhost/wallet/webhooks/"+testId+"/deliveries"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{
+					Keys:   []string{"id"},
+					Values: []string{testId},
+				},
+			}))
+
+			h.WebhookDeliveries.ServeHTTP(w, req)
+
+			resp := w.Result()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("WebhookDeliveriesHandler() StatusCode = %v, wantCode = %v", resp.StatusCode, tt.wantCode)
+			}
+
+			respBody := struct {
+				Err  string              `json:"error"`
+				Data entities.Deliveries `json:"data"`
+			}{}
+
+			if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+				t.Fatal(err)
+			}
+
+			if respBody.Err != tt.wantErr {
+				t.Fatalf("WebhookDeliveriesHandler() error = %v, wantErr = %v", respBody.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(respBody.Data, tt.want) {
+				t.Fatalf("WebhookDeliveriesHandler() got = %v, want %v", respBody.Data, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"github.com/NickRI/wallets-task/transport/grpc/pb"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func decodeSendRequest(ctx context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.SendRequest)
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, models.ValidationError{xerrors.Errorf("wrong amount: %w", err)}
+	}
+
+	if err := endpoints.ValidateAmount(entities.NewMoney(amount, entities.Currency(req.Currency))); err != nil {
+		return nil, err
+	}
+
+	if err := endpoints.ValidateIdempotencyKey(req.IdempotencyKey); err != nil {
+		return nil, err
+	}
+
+	return endpoints.SendRequest{
+		Sender:         req.Sender,
+		Receiver:       req.Receiver,
+		IdempotencyKey: req.IdempotencyKey,
+		QuoteId:        req.QuoteId,
+		Amount:         entities.NewMoney(amount, entities.Currency(req.Currency)),
+	}, nil
+}
+
+//decodeListLedgersRequest maps request to the query of GET /wallet/ledgers, so both transports validate filter alike
+func decodeListLedgersRequest(ctx context.Context, request interface{}) (interface{}, error) {
+	req := request.(*pb.ListLedgersRequest)
+
+	q := url.Values{}
+	for k, v := range map[string]string{
+		"account":      req.Account,
+		"counterparty": req.Counterparty,
+		"direction":    req.Direction,
+		"type":         req.Type,
+		"min_amount":   req.MinAmount,
+		"max_amount":   req.MaxAmount,
+		"from":         req.From,
+		"to":           req.To,
+		"cursor":       req.Cursor,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+
+	if req.Limit != 0 {
+		q.Set("limit", strconv.Itoa(int(req.Limit)))
+	}
+
+	return endpoints.ParseLedgerFilter(q)
+}
+
+func decodeListAccountsRequest(ctx context.Context, request interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func encodeLedger(ctx context.Context, response interface{}) (interface{}, error) {
+	return ledger(response.(*entities.Ledger)), nil
+}
+
+func encodeLedgersPage(ctx context.Context, response interface{}) (interface{}, error) {
+	page := response.(*entities.LedgersPage)
+
+	resp := &pb.LedgersPage{NextCursor: page.NextCursor}
+	for _, l := range page.Ledgers {
+		resp.Ledgers = append(resp.Ledgers, ledger(l))
+	}
+
+	return resp, nil
+}
+
+func encodeAccounts(ctx context.Context, response interface{}) (interface{}, error) {
+	resp := &pb.Accounts{}
+	for _, a := range response.(entities.Accounts) {
+		resp.Accounts = append(resp.Accounts, &pb.Account{
+			Id:               string(a.AccountId),
+			Balance:          a.Balance.String(),
+			AvailableBalance: a.AvailableBalance.String(),
+			Currency:         string(a.Currency),
+			Status:           string(a.Status),
+		})
+	}
+
+	return resp, nil
+}
+
+func ledger(l *entities.Ledger) *pb.Ledger {
+	resp := &pb.Ledger{Id: l.Id}
+	if !l.CreatedAt.IsZero() {
+		resp.CreatedAt = l.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	for _, p := range l.Payments {
+		if p != nil {
+			resp.Payments = append(resp.Payments, payment(p))
+		}
+	}
+
+	return resp
+}
+
+func payment(p *entities.Payment) *pb.Payment {
+	resp := &pb.Payment{
+		Ledger:      p.Ledger,
+		Account:     string(p.Account),
+		Amount:      p.Amount.Amount.String(),
+		Currency:    string(p.Amount.Currency),
+		ToAccount:   string(p.ToAccount),
+		FromAccount: string(p.FromAccount),
+		Direction:   p.Direction.String(),
+		Type:        string(p.Type),
+		RefundOf:    p.RefundOf,
+		Batch:       p.Batch,
+	}
+
+	if p.FX != nil {
+		resp.Fx = &pb.Conversion{
+			Rate:         p.FX.Rate.String(),
+			SourceAmount: p.FX.SourceAmount.String(),
+			TargetAmount: p.FX.TargetAmount.String(),
+		}
+	}
+
+	if p.BalanceAfter != nil {
+		resp.BalanceAfter = p.BalanceAfter.Amount.String()
+	}
+
+	return resp
+}
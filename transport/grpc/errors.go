@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//kindCodes maps kinds of endpoints.ErrorKind to gRPC codes, unknown kinds are codes.Unknown
+var kindCodes = map[string]codes.Code{
+	"not_found":            codes.NotFound,
+	"conflict":             codes.Aborted,
+	"db":                   codes.Internal,
+	"low_balance":          codes.FailedPrecondition,
+	"account_state":        codes.FailedPrecondition,
+	"idempotency_conflict": codes.AlreadyExists,
+	"limit_exceeded":       codes.ResourceExhausted,
+	"quote":                codes.FailedPrecondition,
+	"validation":           codes.InvalidArgument,
+	"unauthenticated":      codes.Unauthenticated,
+	"forbidden":            codes.PermissionDenied,
+	"unavailable":          codes.Unavailable,
+}
+
+//StatusError maps service errors to gRPC status by the kind endpoints.ErrorEncoder tells them apart with
+func StatusError(err error) error {
+	return status.Error(code(err), err.Error())
+}
+
+func code(err error) codes.Code {
+	if c, ok := kindCodes[endpoints.ErrorKind(err)]; ok {
+		return c
+	}
+
+	return codes.Unknown
+}
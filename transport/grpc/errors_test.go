@@ -0,0 +1,45 @@
+// +build !integration
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	testError := xerrors.New("some_error")
+
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "not found", err: models.NotFoundWrapper{testError}, want: codes.NotFound},
+		{name: "low balance", err: xerrors.Errorf("send: %w", models.LowBalanceWrapper{testError}), want: codes.FailedPrecondition},
+		{name: "limit exceeded", err: xerrors.Errorf("send: %w", models.LimitExceededError{testError}), want: codes.ResourceExhausted},
+		{name: "validation", err: models.ValidationError{testError}, want: codes.InvalidArgument},
+		{name: "unauthenticated", err: models.UnauthenticatedError{testError}, want: codes.Unauthenticated},
+		{name: "forbidden", err: xerrors.Errorf("send: %w", models.ForbiddenError{testError}), want: codes.PermissionDenied},
+		{name: "unavailable", err: models.UnavailableError{testError}, want: codes.Unavailable},
+		{name: "conflict", err: models.ConflictError{models.DBErrorWrapper{testError}}, want: codes.Aborted},
+		{name: "database", err: models.DBErrorWrapper{testError}, want: codes.Internal},
+		{name: "unknown", err: testError, want: codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(StatusError(tt.err))
+			if !ok {
+				t.Fatalf("StatusError() is not a status")
+			}
+
+			if st.Code() != tt.want || st.Message() != tt.err.Error() {
+				t.Errorf("StatusError() = %v %q, want %v %q", st.Code(), st.Message(), tt.want, tt.err.Error())
+			}
+		})
+	}
+}
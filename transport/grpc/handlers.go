@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"github.com/NickRI/wallets-task/transport/grpc/pb"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+)
+
+// Handlers holds go-kit gRPC handlers of the service, they serve the same endpoints as REST ones.
+type Handlers struct {
+	send         kitgrpc.Handler
+	listLedgers  kitgrpc.Handler
+	listAccounts kitgrpc.Handler
+}
+
+// MakeHandlers initializes go-kit gRPC handlers for the service.
+func MakeHandlers(ws services.Wallet, options ...kitgrpc.ServerOption) pb.WalletServer {
+	return &Handlers{
+		send:         kitgrpc.NewServer(endpoints.PaymentSend(ws), decodeSendRequest, encodeLedger, options...),
+		listLedgers:  kitgrpc.NewServer(endpoints.LedgerList(ws), decodeListLedgersRequest, encodeLedgersPage, options...),
+		listAccounts: kitgrpc.NewServer(endpoints.AccountList(ws), decodeListAccountsRequest, encodeAccounts, options...),
+	}
+}
+
+func (h *Handlers) Send(ctx context.Context, req *pb.SendRequest) (*pb.Ledger, error) {
+	_, resp, err := h.send.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, StatusError(err)
+	}
+
+	return resp.(*pb.Ledger), nil
+}
+
+func (h *Handlers) ListLedgers(ctx context.Context, req *pb.ListLedgersRequest) (*pb.LedgersPage, error) {
+	_, resp, err := h.listLedgers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, StatusError(err)
+	}
+
+	return resp.(*pb.LedgersPage), nil
+}
+
+func (h *Handlers) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.Accounts, error) {
+	_, resp, err := h.listAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, StatusError(err)
+	}
+
+	return resp.(*pb.Accounts), nil
+}
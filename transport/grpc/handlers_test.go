@@ -0,0 +1,157 @@
+// +build !integration
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/NickRI/wallets-task/transport/grpc/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+//dialHandlers serves handlers of ws on in-memory listener and returns client connected to them
+func dialHandlers(t *testing.T, ws *mock.MockWallet) (pb.WalletClient, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	server := googlegrpc.NewServer()
+	pb.RegisterWalletServer(server, MakeHandlers(ws))
+	go server.Serve(lis)
+
+	conn, err := googlegrpc.Dial("bufnet", googlegrpc.WithInsecure(),
+		googlegrpc.WithDialer(func(string, time.Duration) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		t.Fatalf("grpc.Dial error: %+v", err)
+	}
+
+	return pb.NewWalletClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestHandlers_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ws := mock.NewMockWallet(ctrl)
+	client, stop := dialHandlers(t, ws)
+	defer stop()
+
+	testCreatedAt := time.Date(2019, 8, 1, 10, 0, 0, 0, time.UTC)
+	testLedger := &entities.Ledger{Id: "c5417ca1-c06b-4a45-9cd9-85936d4b9665", CreatedAt: testCreatedAt, Payments: [2]*entities.Payment{
+		{Account: "alice123", Amount: entities.NewMoney(decimal.RequireFromString("-3.21"), "USD"), ToAccount: "bob456", Direction: entities.Outgoing, Type: entities.Transfer},
+		{Account: "bob456", Amount: entities.NewMoney(decimal.RequireFromString("3.21"), "USD"), FromAccount: "alice123", Direction: entities.Incoming, Type: entities.Transfer},
+	}}
+
+	tests := []struct {
+		name     string
+		req      *pb.SendRequest
+		before   func()
+		want     *pb.Ledger
+		wantCode codes.Code
+	}{
+		{
+			name:     "malformed amount",
+			req:      &pb.SendRequest{Sender: "alice123", Receiver: "bob456", Amount: "abc"},
+			before:   func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "low balance",
+			req:  &pb.SendRequest{Sender: "alice123", Receiver: "bob456", Amount: "3.21", Currency: "USD"},
+			before: func() {
+				ws.EXPECT().Send(gomock.Any(), "", "", "alice123", "bob456", entities.NewMoney(decimal.RequireFromString("3.21"), "USD")).
+					Return(nil, models.LowBalanceWrapper{xerrors.New("alice123: don't have enough balance")})
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "works well",
+			req:  &pb.SendRequest{Sender: "alice123", Receiver: "bob456", Amount: "3.21", IdempotencyKey: "key"},
+			before: func() {
+				ws.EXPECT().Send(gomock.Any(), "key", "", "alice123", "bob456", entities.NewMoney(decimal.RequireFromString("3.21"), "")).
+					Return(testLedger, nil)
+			},
+			want: &pb.Ledger{Id: testLedger.Id, CreatedAt: "2019-08-01T10:00:00Z", Payments: []*pb.Payment{
+				{Account: "alice123", Amount: "-3.21", Currency: "USD", ToAccount: "bob456", Direction: "outgoing", Type: "transfer"},
+				{Account: "bob456", Amount: "3.21", Currency: "USD", FromAccount: "alice123", Direction: "incoming", Type: "transfer"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.before()
+
+			got, err := client.Send(context.Background(), tt.req)
+			if st, _ := status.FromError(err); st.Code() != tt.wantCode {
+				t.Fatalf("Send() error = %v, want code %s", err, tt.wantCode)
+			}
+
+			if tt.want == nil {
+				return
+			}
+
+			if got.String() != tt.want.String() {
+				t.Fatalf("Send() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlers_ListAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ws := mock.NewMockWallet(ctrl)
+	client, stop := dialHandlers(t, ws)
+	defer stop()
+
+	ws.EXPECT().AccountsList(gomock.Any()).Return(entities.Accounts{
+		{AccountId: "alice123", Balance: decimal.RequireFromString("10.5"), AvailableBalance: decimal.RequireFromString("7.5"), Currency: "USD", Status: entities.AccountActive},
+	}, nil)
+
+	got, err := client.ListAccounts(context.Background(), &pb.ListAccountsRequest{})
+	if err != nil {
+		t.Fatalf("ListAccounts() error = %v", err)
+	}
+
+	want := &pb.Accounts{Accounts: []*pb.Account{{Id: "alice123", Balance: "10.5", AvailableBalance: "7.5", Currency: "USD", Status: "active"}}}
+	if got.String() != want.String() {
+		t.Fatalf("ListAccounts() got = %v, want %v", got, want)
+	}
+}
+
+func TestHandlers_ListLedgers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ws := mock.NewMockWallet(ctrl)
+	client, stop := dialHandlers(t, ws)
+	defer stop()
+
+	if _, err := client.ListLedgers(context.Background(), &pb.ListLedgersRequest{Direction: "sideways"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("ListLedgers() error = %v, want code %s", err, codes.InvalidArgument)
+	}
+
+	ws.EXPECT().LedgersList(gomock.Any(), gomock.Any()).Return(&entities.LedgersPage{NextCursor: "next"}, nil)
+
+	got, err := client.ListLedgers(context.Background(), &pb.ListLedgersRequest{Account: "alice123", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListLedgers() error = %v", err)
+	}
+
+	if got.NextCursor != "next" || len(got.Ledgers) != 0 {
+		t.Fatalf("ListLedgers() got = %v", got)
+	}
+}
@@ -0,0 +1,4 @@
+//Package pb holds protobuf messages and service of the wallet gRPC transport
+package pb
+
+//go:generate protoc --go_out=plugins=grpc:. wallet.proto
@@ -0,0 +1,857 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: wallet.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type SendRequest struct {
+	Sender               string   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receiver             string   `protobuf:"bytes,2,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Amount               string   `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	QuoteId              string   `protobuf:"bytes,4,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	IdempotencyKey       string   `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Currency             string   `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SendRequest) Reset()         { *m = SendRequest{} }
+func (m *SendRequest) String() string { return proto.CompactTextString(m) }
+func (*SendRequest) ProtoMessage()    {}
+func (*SendRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{0}
+}
+
+func (m *SendRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SendRequest.Unmarshal(m, b)
+}
+func (m *SendRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SendRequest.Marshal(b, m, deterministic)
+}
+func (m *SendRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SendRequest.Merge(m, src)
+}
+func (m *SendRequest) XXX_Size() int {
+	return xxx_messageInfo_SendRequest.Size(m)
+}
+func (m *SendRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SendRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SendRequest proto.InternalMessageInfo
+
+func (m *SendRequest) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *SendRequest) GetReceiver() string {
+	if m != nil {
+		return m.Receiver
+	}
+	return ""
+}
+
+func (m *SendRequest) GetAmount() string {
+	if m != nil {
+		return m.Amount
+	}
+	return ""
+}
+
+func (m *SendRequest) GetQuoteId() string {
+	if m != nil {
+		return m.QuoteId
+	}
+	return ""
+}
+
+func (m *SendRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *SendRequest) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+type Conversion struct {
+	Rate                 string   `protobuf:"bytes,1,opt,name=rate,proto3" json:"rate,omitempty"`
+	SourceAmount         string   `protobuf:"bytes,2,opt,name=source_amount,json=sourceAmount,proto3" json:"source_amount,omitempty"`
+	TargetAmount         string   `protobuf:"bytes,3,opt,name=target_amount,json=targetAmount,proto3" json:"target_amount,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Conversion) Reset()         { *m = Conversion{} }
+func (m *Conversion) String() string { return proto.CompactTextString(m) }
+func (*Conversion) ProtoMessage()    {}
+func (*Conversion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{1}
+}
+
+func (m *Conversion) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Conversion.Unmarshal(m, b)
+}
+func (m *Conversion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Conversion.Marshal(b, m, deterministic)
+}
+func (m *Conversion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Conversion.Merge(m, src)
+}
+func (m *Conversion) XXX_Size() int {
+	return xxx_messageInfo_Conversion.Size(m)
+}
+func (m *Conversion) XXX_DiscardUnknown() {
+	xxx_messageInfo_Conversion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Conversion proto.InternalMessageInfo
+
+func (m *Conversion) GetRate() string {
+	if m != nil {
+		return m.Rate
+	}
+	return ""
+}
+
+func (m *Conversion) GetSourceAmount() string {
+	if m != nil {
+		return m.SourceAmount
+	}
+	return ""
+}
+
+func (m *Conversion) GetTargetAmount() string {
+	if m != nil {
+		return m.TargetAmount
+	}
+	return ""
+}
+
+type Payment struct {
+	Ledger               string      `protobuf:"bytes,1,opt,name=ledger,proto3" json:"ledger,omitempty"`
+	Account              string      `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	Amount               string      `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	ToAccount            string      `protobuf:"bytes,4,opt,name=to_account,json=toAccount,proto3" json:"to_account,omitempty"`
+	FromAccount          string      `protobuf:"bytes,5,opt,name=from_account,json=fromAccount,proto3" json:"from_account,omitempty"`
+	Direction            string      `protobuf:"bytes,6,opt,name=direction,proto3" json:"direction,omitempty"`
+	Type                 string      `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	Fx                   *Conversion `protobuf:"bytes,8,opt,name=fx,proto3" json:"fx,omitempty"`
+	RefundOf             string      `protobuf:"bytes,9,opt,name=refund_of,json=refundOf,proto3" json:"refund_of,omitempty"`
+	Batch                string      `protobuf:"bytes,10,opt,name=batch,proto3" json:"batch,omitempty"`
+	BalanceAfter         string      `protobuf:"bytes,11,opt,name=balance_after,json=balanceAfter,proto3" json:"balance_after,omitempty"`
+	Currency             string      `protobuf:"bytes,12,opt,name=currency,proto3" json:"currency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Payment) Reset()         { *m = Payment{} }
+func (m *Payment) String() string { return proto.CompactTextString(m) }
+func (*Payment) ProtoMessage()    {}
+func (*Payment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{2}
+}
+
+func (m *Payment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Payment.Unmarshal(m, b)
+}
+func (m *Payment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Payment.Marshal(b, m, deterministic)
+}
+func (m *Payment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Payment.Merge(m, src)
+}
+func (m *Payment) XXX_Size() int {
+	return xxx_messageInfo_Payment.Size(m)
+}
+func (m *Payment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Payment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Payment proto.InternalMessageInfo
+
+func (m *Payment) GetLedger() string {
+	if m != nil {
+		return m.Ledger
+	}
+	return ""
+}
+
+func (m *Payment) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *Payment) GetAmount() string {
+	if m != nil {
+		return m.Amount
+	}
+	return ""
+}
+
+func (m *Payment) GetToAccount() string {
+	if m != nil {
+		return m.ToAccount
+	}
+	return ""
+}
+
+func (m *Payment) GetFromAccount() string {
+	if m != nil {
+		return m.FromAccount
+	}
+	return ""
+}
+
+func (m *Payment) GetDirection() string {
+	if m != nil {
+		return m.Direction
+	}
+	return ""
+}
+
+func (m *Payment) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Payment) GetFx() *Conversion {
+	if m != nil {
+		return m.Fx
+	}
+	return nil
+}
+
+func (m *Payment) GetRefundOf() string {
+	if m != nil {
+		return m.RefundOf
+	}
+	return ""
+}
+
+func (m *Payment) GetBatch() string {
+	if m != nil {
+		return m.Batch
+	}
+	return ""
+}
+
+func (m *Payment) GetBalanceAfter() string {
+	if m != nil {
+		return m.BalanceAfter
+	}
+	return ""
+}
+
+func (m *Payment) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+type Ledger struct {
+	Id                   string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt            string     `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Payments             []*Payment `protobuf:"bytes,3,rep,name=payments,proto3" json:"payments,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Ledger) Reset()         { *m = Ledger{} }
+func (m *Ledger) String() string { return proto.CompactTextString(m) }
+func (*Ledger) ProtoMessage()    {}
+func (*Ledger) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{3}
+}
+
+func (m *Ledger) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ledger.Unmarshal(m, b)
+}
+func (m *Ledger) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ledger.Marshal(b, m, deterministic)
+}
+func (m *Ledger) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ledger.Merge(m, src)
+}
+func (m *Ledger) XXX_Size() int {
+	return xxx_messageInfo_Ledger.Size(m)
+}
+func (m *Ledger) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ledger.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ledger proto.InternalMessageInfo
+
+func (m *Ledger) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Ledger) GetCreatedAt() string {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return ""
+}
+
+func (m *Ledger) GetPayments() []*Payment {
+	if m != nil {
+		return m.Payments
+	}
+	return nil
+}
+
+// ListLedgersRequest fields have the meaning of GET /wallet/ledgers query parameters, empty field means no restriction.
+type ListLedgersRequest struct {
+	Account              string   `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Counterparty         string   `protobuf:"bytes,2,opt,name=counterparty,proto3" json:"counterparty,omitempty"`
+	Direction            string   `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"`
+	Type                 string   `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	MinAmount            string   `protobuf:"bytes,5,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	MaxAmount            string   `protobuf:"bytes,6,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`
+	From                 string   `protobuf:"bytes,7,opt,name=from,proto3" json:"from,omitempty"`
+	To                   string   `protobuf:"bytes,8,opt,name=to,proto3" json:"to,omitempty"`
+	Cursor               string   `protobuf:"bytes,9,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLedgersRequest) Reset()         { *m = ListLedgersRequest{} }
+func (m *ListLedgersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListLedgersRequest) ProtoMessage()    {}
+func (*ListLedgersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{4}
+}
+
+func (m *ListLedgersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLedgersRequest.Unmarshal(m, b)
+}
+func (m *ListLedgersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLedgersRequest.Marshal(b, m, deterministic)
+}
+func (m *ListLedgersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLedgersRequest.Merge(m, src)
+}
+func (m *ListLedgersRequest) XXX_Size() int {
+	return xxx_messageInfo_ListLedgersRequest.Size(m)
+}
+func (m *ListLedgersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLedgersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLedgersRequest proto.InternalMessageInfo
+
+func (m *ListLedgersRequest) GetAccount() string {
+	if m != nil {
+		return m.Account
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetCounterparty() string {
+	if m != nil {
+		return m.Counterparty
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetDirection() string {
+	if m != nil {
+		return m.Direction
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetMinAmount() string {
+	if m != nil {
+		return m.MinAmount
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetMaxAmount() string {
+	if m != nil {
+		return m.MaxAmount
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListLedgersRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type LedgersPage struct {
+	Ledgers              []*Ledger `protobuf:"bytes,1,rep,name=ledgers,proto3" json:"ledgers,omitempty"`
+	NextCursor           string    `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *LedgersPage) Reset()         { *m = LedgersPage{} }
+func (m *LedgersPage) String() string { return proto.CompactTextString(m) }
+func (*LedgersPage) ProtoMessage()    {}
+func (*LedgersPage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{5}
+}
+
+func (m *LedgersPage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LedgersPage.Unmarshal(m, b)
+}
+func (m *LedgersPage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LedgersPage.Marshal(b, m, deterministic)
+}
+func (m *LedgersPage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LedgersPage.Merge(m, src)
+}
+func (m *LedgersPage) XXX_Size() int {
+	return xxx_messageInfo_LedgersPage.Size(m)
+}
+func (m *LedgersPage) XXX_DiscardUnknown() {
+	xxx_messageInfo_LedgersPage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LedgersPage proto.InternalMessageInfo
+
+func (m *LedgersPage) GetLedgers() []*Ledger {
+	if m != nil {
+		return m.Ledgers
+	}
+	return nil
+}
+
+func (m *LedgersPage) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+type ListAccountsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListAccountsRequest) Reset()         { *m = ListAccountsRequest{} }
+func (m *ListAccountsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAccountsRequest) ProtoMessage()    {}
+func (*ListAccountsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{6}
+}
+
+func (m *ListAccountsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAccountsRequest.Unmarshal(m, b)
+}
+func (m *ListAccountsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAccountsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListAccountsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAccountsRequest.Merge(m, src)
+}
+func (m *ListAccountsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListAccountsRequest.Size(m)
+}
+func (m *ListAccountsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAccountsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAccountsRequest proto.InternalMessageInfo
+
+type Account struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Balance              string   `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	AvailableBalance     string   `protobuf:"bytes,3,opt,name=available_balance,json=availableBalance,proto3" json:"available_balance,omitempty"`
+	Currency             string   `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status               string   `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Account) Reset()         { *m = Account{} }
+func (m *Account) String() string { return proto.CompactTextString(m) }
+func (*Account) ProtoMessage()    {}
+func (*Account) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{7}
+}
+
+func (m *Account) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Account.Unmarshal(m, b)
+}
+func (m *Account) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Account.Marshal(b, m, deterministic)
+}
+func (m *Account) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Account.Merge(m, src)
+}
+func (m *Account) XXX_Size() int {
+	return xxx_messageInfo_Account.Size(m)
+}
+func (m *Account) XXX_DiscardUnknown() {
+	xxx_messageInfo_Account.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Account proto.InternalMessageInfo
+
+func (m *Account) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Account) GetBalance() string {
+	if m != nil {
+		return m.Balance
+	}
+	return ""
+}
+
+func (m *Account) GetAvailableBalance() string {
+	if m != nil {
+		return m.AvailableBalance
+	}
+	return ""
+}
+
+func (m *Account) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+func (m *Account) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+type Accounts struct {
+	Accounts             []*Account `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Accounts) Reset()         { *m = Accounts{} }
+func (m *Accounts) String() string { return proto.CompactTextString(m) }
+func (*Accounts) ProtoMessage()    {}
+func (*Accounts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b88fd140af4deb6f, []int{8}
+}
+
+func (m *Accounts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Accounts.Unmarshal(m, b)
+}
+func (m *Accounts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Accounts.Marshal(b, m, deterministic)
+}
+func (m *Accounts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Accounts.Merge(m, src)
+}
+func (m *Accounts) XXX_Size() int {
+	return xxx_messageInfo_Accounts.Size(m)
+}
+func (m *Accounts) XXX_DiscardUnknown() {
+	xxx_messageInfo_Accounts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Accounts proto.InternalMessageInfo
+
+func (m *Accounts) GetAccounts() []*Account {
+	if m != nil {
+		return m.Accounts
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SendRequest)(nil), "wallet.SendRequest")
+	proto.RegisterType((*Conversion)(nil), "wallet.Conversion")
+	proto.RegisterType((*Payment)(nil), "wallet.Payment")
+	proto.RegisterType((*Ledger)(nil), "wallet.Ledger")
+	proto.RegisterType((*ListLedgersRequest)(nil), "wallet.ListLedgersRequest")
+	proto.RegisterType((*LedgersPage)(nil), "wallet.LedgersPage")
+	proto.RegisterType((*ListAccountsRequest)(nil), "wallet.ListAccountsRequest")
+	proto.RegisterType((*Account)(nil), "wallet.Account")
+	proto.RegisterType((*Accounts)(nil), "wallet.Accounts")
+}
+
+func init() { proto.RegisterFile("wallet.proto", fileDescriptor_b88fd140af4deb6f) }
+
+var fileDescriptor_b88fd140af4deb6f = []byte{
+	// 722 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x95, 0xcf, 0x4e, 0xdb, 0x4a,
+	0x14, 0xc6, 0xb1, 0x13, 0x92, 0xf8, 0x38, 0xfc, 0xb9, 0xc3, 0xbd, 0x57, 0x73, 0xc3, 0x45, 0xa5,
+	0x66, 0xd1, 0x48, 0xa8, 0x2c, 0xe8, 0xa2, 0xcb, 0x36, 0xb0, 0xaa, 0x8a, 0x54, 0xe4, 0x2e, 0x5a,
+	0x75, 0x63, 0x4d, 0xec, 0x13, 0xea, 0x36, 0xf6, 0x84, 0xf1, 0x98, 0x26, 0xef, 0xc1, 0xcb, 0xb4,
+	0x2f, 0xd3, 0x57, 0xa9, 0xe6, 0x9f, 0x71, 0x82, 0xd8, 0xcd, 0x77, 0xce, 0x19, 0x66, 0xfc, 0xfb,
+	0x3e, 0x26, 0x30, 0xfc, 0xc1, 0xe6, 0x73, 0x94, 0x67, 0x0b, 0xc1, 0x25, 0x27, 0x3d, 0xa3, 0xa2,
+	0x9f, 0x1e, 0x84, 0x1f, 0xb1, 0xcc, 0x62, 0xbc, 0xad, 0xb1, 0x92, 0xe4, 0x5f, 0xe8, 0x55, 0x58,
+	0x66, 0x28, 0xa8, 0x77, 0xec, 0x8d, 0x83, 0xd8, 0x2a, 0x32, 0x82, 0x81, 0xc0, 0x14, 0xf3, 0x3b,
+	0x14, 0xd4, 0xd7, 0x9d, 0x46, 0xab, 0x3d, 0xac, 0xe0, 0x75, 0x29, 0x69, 0xc7, 0xec, 0x31, 0x8a,
+	0xfc, 0x07, 0x83, 0xdb, 0x9a, 0x4b, 0x4c, 0xf2, 0x8c, 0x76, 0x75, 0xa7, 0xaf, 0xf5, 0xbb, 0x8c,
+	0xbc, 0x80, 0xbd, 0x3c, 0xc3, 0x62, 0xc1, 0x25, 0x96, 0xe9, 0x2a, 0xf9, 0x8e, 0x2b, 0xba, 0xad,
+	0x27, 0x76, 0x5b, 0xe5, 0xf7, 0xb8, 0x52, 0xe7, 0xa6, 0xb5, 0x10, 0x4a, 0xd2, 0x9e, 0x39, 0xd7,
+	0xe9, 0xe8, 0x1b, 0xc0, 0x25, 0x2f, 0xef, 0x50, 0x54, 0x39, 0x2f, 0x09, 0x81, 0xae, 0x60, 0x12,
+	0xed, 0xbd, 0xf5, 0x9a, 0x9c, 0xc0, 0x4e, 0xc5, 0x6b, 0x91, 0x62, 0x62, 0x2f, 0x68, 0xae, 0x3e,
+	0x34, 0xc5, 0x89, 0xb9, 0xe6, 0x09, 0xec, 0x48, 0x26, 0x6e, 0x50, 0x26, 0x6b, 0x5f, 0x31, 0x34,
+	0x45, 0x33, 0x14, 0xfd, 0xf6, 0xa1, 0x7f, 0xcd, 0x56, 0x05, 0x96, 0x9a, 0xd1, 0x1c, 0xb3, 0x9b,
+	0x07, 0x46, 0x46, 0x11, 0x0a, 0x7d, 0x96, 0xa6, 0xad, 0x73, 0x9c, 0x7c, 0x92, 0xd0, 0x11, 0x80,
+	0xe4, 0x89, 0xdb, 0x64, 0x18, 0x05, 0x92, 0x4f, 0xec, 0xb6, 0xe7, 0x30, 0x9c, 0x09, 0x5e, 0x34,
+	0x03, 0x06, 0x51, 0xa8, 0x6a, 0x6e, 0xe4, 0x7f, 0x08, 0xb2, 0x5c, 0x60, 0x2a, 0x73, 0x5e, 0x5a,
+	0x40, 0x0f, 0x05, 0xc5, 0x44, 0xae, 0x16, 0x48, 0xfb, 0x86, 0x89, 0x5a, 0x93, 0x08, 0xfc, 0xd9,
+	0x92, 0x0e, 0x8e, 0xbd, 0x71, 0x78, 0x4e, 0xce, 0x6c, 0x28, 0x1e, 0x38, 0xc6, 0xfe, 0x6c, 0x49,
+	0x0e, 0x21, 0x10, 0x38, 0xab, 0xcb, 0x2c, 0xe1, 0x33, 0x1a, 0x38, 0xbb, 0x55, 0xe1, 0xc3, 0x8c,
+	0xfc, 0x0d, 0xdb, 0x53, 0x26, 0xd3, 0xaf, 0x14, 0x74, 0xc3, 0x08, 0x45, 0x71, 0xca, 0xe6, 0xac,
+	0x54, 0xac, 0x67, 0x12, 0x05, 0x0d, 0x0d, 0x45, 0x5b, 0x9c, 0xa8, 0xda, 0x9a, 0x9b, 0xc3, 0x0d,
+	0x37, 0x33, 0xe8, 0x5d, 0x19, 0x8e, 0xbb, 0xe0, 0xe7, 0x99, 0x65, 0xeb, 0xe7, 0x99, 0xa2, 0x94,
+	0x0a, 0x64, 0x12, 0xb3, 0x84, 0x39, 0xb4, 0x81, 0xad, 0x4c, 0x24, 0x39, 0x85, 0xc1, 0xc2, 0x38,
+	0x53, 0xd1, 0xce, 0x71, 0x67, 0x1c, 0x9e, 0xef, 0xb9, 0xcf, 0xb2, 0x8e, 0xc5, 0xcd, 0x40, 0x74,
+	0xef, 0x03, 0xb9, 0xca, 0x2b, 0x69, 0x8e, 0xaa, 0x5c, 0xec, 0x5b, 0xd6, 0x79, 0xeb, 0xd6, 0x45,
+	0x30, 0xd4, 0x0b, 0x14, 0x0b, 0x26, 0xe4, 0xca, 0x25, 0xa8, 0x5d, 0x5b, 0x37, 0xa1, 0xf3, 0x94,
+	0x09, 0xdd, 0x96, 0x09, 0x47, 0x00, 0x45, 0x5e, 0xba, 0xc0, 0x19, 0x5f, 0x83, 0x22, 0x2f, 0x27,
+	0x4d, 0x2e, 0x0a, 0xb6, 0x74, 0x6d, 0x6b, 0x6b, 0xc1, 0x96, 0xb6, 0x4d, 0xa0, 0xab, 0x32, 0xe0,
+	0x6c, 0x55, 0x6b, 0x05, 0x4d, 0x72, 0x6d, 0x6b, 0x10, 0xfb, 0x92, 0xab, 0xc8, 0xa5, 0xb5, 0xa8,
+	0xb8, 0xb0, 0xfe, 0x59, 0xa5, 0xdc, 0x9b, 0xe7, 0x45, 0x2e, 0xb5, 0x7b, 0xdb, 0xb1, 0x11, 0xd1,
+	0x67, 0x08, 0x2d, 0x91, 0x6b, 0x76, 0x83, 0x64, 0x0c, 0x7d, 0x93, 0xe9, 0x8a, 0x7a, 0x9a, 0xe8,
+	0xae, 0x23, 0x6a, 0xa6, 0x62, 0xd7, 0x26, 0xcf, 0x20, 0x2c, 0x71, 0x29, 0x13, 0x7b, 0x96, 0xa1,
+	0x03, 0xaa, 0x74, 0xa9, 0x2b, 0xd1, 0x3f, 0x70, 0xa0, 0x78, 0xdb, 0xbc, 0x3a, 0xe0, 0xd1, 0xbd,
+	0x07, 0x7d, 0x5b, 0x7b, 0xe4, 0x37, 0x85, 0xbe, 0x4d, 0x8d, 0xfb, 0x3f, 0xb2, 0x92, 0x9c, 0xc2,
+	0x5f, 0xec, 0x8e, 0xe5, 0x73, 0x36, 0x9d, 0x63, 0xe2, 0x66, 0x0c, 0xf0, 0xfd, 0xa6, 0x71, 0x61,
+	0x87, 0xdb, 0x61, 0xeb, 0xae, 0x87, 0x4d, 0x3f, 0x73, 0x92, 0xc9, 0xba, 0xb2, 0xec, 0xad, 0x8a,
+	0x5e, 0xc3, 0xc0, 0xdd, 0x54, 0xe5, 0xca, 0x86, 0xc0, 0x51, 0x68, 0x72, 0x65, 0x67, 0xe2, 0x66,
+	0xe0, 0xfc, 0x97, 0x07, 0xbd, 0x4f, 0xba, 0x49, 0x5e, 0x42, 0x57, 0xbd, 0xa8, 0xe4, 0xc0, 0x4d,
+	0xb7, 0xde, 0xd7, 0xd1, 0x06, 0xc8, 0x68, 0x8b, 0xbc, 0x85, 0xb0, 0x15, 0x48, 0x32, 0x6a, 0x06,
+	0x1e, 0xa5, 0x74, 0x74, 0xb0, 0xbe, 0x59, 0x7b, 0x15, 0x6d, 0x91, 0x37, 0x30, 0x6c, 0x23, 0x26,
+	0x87, 0xed, 0x3f, 0xb1, 0x01, 0x7e, 0xb4, 0xbf, 0xf1, 0x0d, 0x55, 0xb4, 0x75, 0xd1, 0xfd, 0xe2,
+	0x2f, 0xa6, 0xd3, 0x9e, 0xfe, 0x65, 0x78, 0xf5, 0x67, 0x00, 0xad, 0x99, 0xde, 0x0a, 0x29, 0x06,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// WalletClient is the client API for Wallet service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WalletClient interface {
+	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*Ledger, error)
+	ListLedgers(ctx context.Context, in *ListLedgersRequest, opts ...grpc.CallOption) (*LedgersPage, error)
+	ListAccounts(ctx context.Context, in *ListAccountsRequest, opts ...grpc.CallOption) (*Accounts, error)
+}
+
+type walletClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWalletClient(cc *grpc.ClientConn) WalletClient {
+	return &walletClient{cc}
+}
+
+func (c *walletClient) Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*Ledger, error) {
+	out := new(Ledger)
+	err := c.cc.Invoke(ctx, "/wallet.Wallet/Send", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletClient) ListLedgers(ctx context.Context, in *ListLedgersRequest, opts ...grpc.CallOption) (*LedgersPage, error) {
+	out := new(LedgersPage)
+	err := c.cc.Invoke(ctx, "/wallet.Wallet/ListLedgers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletClient) ListAccounts(ctx context.Context, in *ListAccountsRequest, opts ...grpc.CallOption) (*Accounts, error) {
+	out := new(Accounts)
+	err := c.cc.Invoke(ctx, "/wallet.Wallet/ListAccounts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WalletServer is the server API for Wallet service.
+type WalletServer interface {
+	Send(context.Context, *SendRequest) (*Ledger, error)
+	ListLedgers(context.Context, *ListLedgersRequest) (*LedgersPage, error)
+	ListAccounts(context.Context, *ListAccountsRequest) (*Accounts, error)
+}
+
+// UnimplementedWalletServer can be embedded to have forward compatible implementations.
+type UnimplementedWalletServer struct {
+}
+
+func (*UnimplementedWalletServer) Send(ctx context.Context, req *SendRequest) (*Ledger, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Send not implemented")
+}
+func (*UnimplementedWalletServer) ListLedgers(ctx context.Context, req *ListLedgersRequest) (*LedgersPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLedgers not implemented")
+}
+func (*UnimplementedWalletServer) ListAccounts(ctx context.Context, req *ListAccountsRequest) (*Accounts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccounts not implemented")
+}
+
+func RegisterWalletServer(s *grpc.Server, srv WalletServer) {
+	s.RegisterService(&_Wallet_serviceDesc, srv)
+}
+
+func _Wallet_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wallet.Wallet/Send",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).Send(ctx, req.(*SendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Wallet_ListLedgers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLedgersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).ListLedgers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wallet.Wallet/ListLedgers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).ListLedgers(ctx, req.(*ListLedgersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Wallet_ListAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServer).ListAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wallet.Wallet/ListAccounts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServer).ListAccounts(ctx, req.(*ListAccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Wallet_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "wallet.Wallet",
+	HandlerType: (*WalletServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _Wallet_Send_Handler,
+		},
+		{
+			MethodName: "ListLedgers",
+			Handler:    _Wallet_ListLedgers_Handler,
+		},
+		{
+			MethodName: "ListAccounts",
+			Handler:    _Wallet_ListAccounts_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "wallet.proto",
+}
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"log"
+	"net"
+
+	"github.com/NickRI/wallets-task/transport/grpc/pb"
+	googlegrpc "google.golang.org/grpc"
+)
+
+type Server struct {
+	*googlegrpc.Server
+	addr string
+}
+
+func NewServer(addr string, handlers pb.WalletServer) *Server {
+	s := &Server{Server: googlegrpc.NewServer(), addr: addr}
+	pb.RegisterWalletServer(s.Server, handlers)
+	return s
+}
+
+//Run listens on the address and serves calls in background, failure to listen is returned
+//to the caller, failure to serve is only logged as the caller is already running
+func (o *Server) Run() (*Server, error) {
+	lis, err := net.Listen("tcp", o.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := o.Serve(lis); err != nil {
+			log.Println("failed to serve gRPC on", o.addr, ":", err)
+		}
+	}()
+
+	return o, nil
+}
+
+//Shutdown waits for the running calls to finish
+func (o *Server) Shutdown() {
+	o.GracefulStop()
+}
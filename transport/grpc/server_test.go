@@ -0,0 +1,32 @@
+// +build !integration
+
+package grpc
+
+import (
+	"net"
+	"testing"
+
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/golang/mock/gomock"
+)
+
+func TestServer_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen error: %+v", err)
+	}
+	defer busy.Close()
+
+	if _, err := NewServer(busy.Addr().String(), MakeHandlers(mock.NewMockWallet(ctrl))).Run(); err == nil {
+		t.Fatalf("Run() on busy address didn't return error")
+	}
+
+	server, err := NewServer("127.0.0.1:0", MakeHandlers(mock.NewMockWallet(ctrl))).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	server.Shutdown()
+}
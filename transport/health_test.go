@@ -0,0 +1,87 @@
+// +build !integration
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/NickRI/wallets-task/transport/restapi"
+	"github.com/go-kit/kit/log"
+	"golang.org/x/xerrors"
+)
+
+func Test_HealthHandlers(t *testing.T) {
+	ok := func(context.Context) (string, error) { return "ok", nil }
+	behind := func(context.Context) (string, error) { return "", xerrors.New("schema version 1 is behind 2") }
+
+	tests := []struct {
+		name     string
+		path     string
+		health   func() *restapi.Health
+		wantCode int
+		wantErr  string
+		want     *restapi.HealthStatus
+	}{
+		{
+			name:     "alive while draining",
+			path:     "/healthz",
+			health:   func() *restapi.Health { h := restapi.NewHealth(0).AddCheck("database", behind); h.Drain(); return h },
+			wantCode: http.StatusOK,
+			want:     &restapi.HealthStatus{Status: "alive"},
+		},
+		{
+			name:     "ready",
+			path:     "/readyz",
+			health:   func() *restapi.Health { return restapi.NewHealth(0).AddCheck("database", ok).AddCheck("migrations", ok) },
+			wantCode: http.StatusOK,
+			want:     &restapi.HealthStatus{Status: "ready", Checks: map[string]string{"database": "ok", "migrations": "ok"}},
+		},
+		{
+			name:     "check fails",
+			path:     "/readyz",
+			health:   func() *restapi.Health { return restapi.NewHealth(0).AddCheck("database", ok).AddCheck("migrations", behind) },
+			wantCode: http.StatusServiceUnavailable,
+			wantErr:  "not ready: migrations: schema version 1 is behind 2",
+		},
+		{
+			name:     "draining",
+			path:     "/readyz",
+			health:   func() *restapi.Health { h := restapi.NewHealth(0).AddCheck("database", ok); h.Drain(); return h },
+			wantCode: http.StatusServiceUnavailable,
+			wantErr:  "not ready: draining",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := restapi.MakeRoutes(nil, nil, nil, tt.health(), nil, nil, log.NewNopLogger())
+
+			rr := httptest.NewRecorder()
+			routes.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.wantCode)
+			}
+
+			var resp struct {
+				Err  string                `json:"error"`
+				Data *restapi.HealthStatus `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if resp.Err != tt.wantErr {
+				t.Errorf("handler returned error %v, want %v", resp.Err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(resp.Data, tt.want) {
+				t.Errorf("handler returned %+v, want %+v", resp.Data, tt.want)
+			}
+		})
+	}
+}
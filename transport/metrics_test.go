@@ -0,0 +1,67 @@
+// +build !integration
+
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/infrastructure/prometheus"
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"github.com/NickRI/wallets-task/transport/restapi"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+func Test_MetricsRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWallet := mock.NewMockWallet(ctrl)
+
+	registry := prometheus.NewRegistry()
+	duration, err := registry.NewHistogram("test_request_duration_seconds", "Duration.", prometheus.DefBuckets, "method", "success")
+	if err != nil {
+		t.Fatalf("NewHistogram() error = %v", err)
+	}
+	errs, err := registry.NewCounter("test_endpoint_errors_total", "Errors.", "method", "kind")
+	if err != nil {
+		t.Fatalf("NewCounter() error = %v", err)
+	}
+	metrics := &restapi.Metrics{Instrument: endpoints.Instrumenting(duration, errs), Handler: registry}
+
+	routes := restapi.MakeRoutes(mockWallet, nil, nil, nil, metrics, nil, log.NewNopLogger())
+
+	mockWallet.EXPECT().GetAccount(gomock.Any(), "alice456").
+		Return(&entities.Account{AccountId: "alice456", Balance: decimal.Zero, Currency: "USD"}, nil)
+	mockWallet.EXPECT().GetAccount(gomock.Any(), "bob123").
+		Return(nil, models.NotFoundWrapper{xerrors.New("account bob123 not found")}).Times(2)
+
+	for _, id := range []string{"alice456", "bob123", "bob123"} {
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/wallet/accounts/"+id, nil))
+	}
+
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	for _, line := range []string{
+		`test_endpoint_errors_total{kind="not_found",method="GetAccount"} 2`,
+		`test_request_duration_seconds_count{method="GetAccount",success="false"} 2`,
+		`test_request_duration_seconds_count{method="GetAccount",success="true"} 1`,
+	} {
+		if !strings.Contains(rr.Body.String(), line+"\n") {
+			t.Errorf("metrics = %s, want line %s", rr.Body.String(), line)
+		}
+	}
+}
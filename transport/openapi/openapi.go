@@ -0,0 +1,192 @@
+//Package openapi builds OpenAPI 3 document from routes and go types of their json bodies
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const Version = "3.0.2"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+//PathItem maps lowercase http method to operation
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string                `json:"summary"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+//SecurityScheme is either apiKey passed In header Name or http with bearer Scheme
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+//SecurityRequirement maps name of the scheme to required scopes
+type SecurityRequirement map[string][]string
+
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	AllOf      []*Schema          `json:"allOf,omitempty"`
+}
+
+//Route describes operation served on Method and Pattern, Request and Response are
+//values of the json bodies types, nil Request means operation has no body.
+//Public route isn't secured by schemes of the builder
+type Route struct {
+	Method   string
+	Pattern  string
+	Summary  string
+	Query    []string
+	Headers  []string
+	Request  interface{}
+	Response interface{}
+	Public   bool
+}
+
+var pathParam = regexp.MustCompile(`{([^}]+)}`)
+
+//Builder collects routes into the document, every response is wrapped into Envelope
+//with the schema of route's response put into its Data field
+type Builder struct {
+	doc      *Document
+	schemas  *Schemas
+	envelope *Schema
+	data     string
+	security []SecurityRequirement
+}
+
+//NewBuilder makes builder of the document with envelope type, data is json name of its payload field
+func NewBuilder(info Info, schemas *Schemas, envelope interface{}, data string) *Builder {
+	b := &Builder{
+		doc:     &Document{OpenAPI: Version, Info: info, Paths: map[string]PathItem{}},
+		schemas: schemas,
+		data:    data,
+	}
+	b.envelope = schemas.Of(reflect.TypeOf(envelope))
+
+	return b
+}
+
+func (b *Builder) Add(r Route) {
+	op := &Operation{
+		Summary: r.Summary,
+		Responses: map[string]*Response{
+			"200": {
+				Description: "OK",
+				Content:     jsonContent(b.wrap(b.schemas.Of(reflect.TypeOf(r.Response)))),
+			},
+			"default": {
+				Description: "Error",
+				Content:     jsonContent(b.envelope),
+			},
+		},
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(r.Pattern, -1) {
+		op.Parameters = append(op.Parameters, &Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	for _, q := range r.Query {
+		op.Parameters = append(op.Parameters, &Parameter{Name: q, In: "query", Schema: &Schema{Type: "string"}})
+	}
+
+	for _, h := range r.Headers {
+		op.Parameters = append(op.Parameters, &Parameter{Name: h, In: "header", Schema: &Schema{Type: "string"}})
+	}
+
+	if !r.Public {
+		op.Security = b.security
+	}
+
+	if r.Request != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: jsonContent(b.schemas.Of(reflect.TypeOf(r.Request)))}
+	}
+
+	item, ok := b.doc.Paths[r.Pattern]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[r.Pattern] = item
+	}
+	item[strings.ToLower(r.Method)] = op
+}
+
+//Secure makes routes added afterwards require any one of the schemes
+func (b *Builder) Secure(schemes map[string]*SecurityScheme) {
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.security = make([]SecurityRequirement, 0, len(names))
+	for _, name := range names {
+		b.security = append(b.security, SecurityRequirement{name: {}})
+	}
+	b.doc.Components.SecuritySchemes = schemes
+}
+
+func (b *Builder) Document() *Document {
+	b.doc.Components.Schemas = b.schemas.Components()
+	return b.doc
+}
+
+func (b *Builder) wrap(data *Schema) *Schema {
+	return &Schema{AllOf: []*Schema{b.envelope, {Type: "object", Properties: map[string]*Schema{b.data: data}}}}
+}
+
+func jsonContent(s *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: s}}
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strings"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+//Schemas makes schemas of go types the way encoding/json marshals them,
+//named structs are put into components and referenced from the other schemas
+type Schemas struct {
+	defined    map[reflect.Type]*Schema
+	names      map[reflect.Type]string
+	components map[string]*Schema
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{
+		defined:    map[reflect.Type]*Schema{},
+		names:      map[reflect.Type]string{},
+		components: map[string]*Schema{},
+	}
+}
+
+//Define sets schema of the type of v, it is needed for types with custom json marshaling
+func (s *Schemas) Define(v interface{}, schema *Schema) {
+	s.defined[reflect.TypeOf(v)] = schema
+}
+
+//Of returns schema of t, nil t is the type of nil interface and matches any value
+func (s *Schemas) Of(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	if schema, ok := s.defined[t]; ok {
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.Of(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.Of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: componentsPrefix + s.component(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+//Components returns schemas of all the named structs met so far
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+func (s *Schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		name = strings.Title(path.Base(t.PkgPath())) + name
+	}
+
+	s.names[t] = name
+	s.components[name] = &Schema{}
+	s.components[name] = s.object(t)
+
+	return name
+}
+
+func (s *Schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, schema.Properties)
+	return schema
+}
+
+//fields puts json fields of struct t into properties, untagged embedded structs are flattened as encoding/json does
+func (s *Schemas) fields(t reflect.Type, properties map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" && f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, properties)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		properties[name] = s.Of(f.Type)
+	}
+}
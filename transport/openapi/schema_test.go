@@ -0,0 +1,122 @@
+// +build !integration
+
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type embedded struct {
+	Rate float64 `json:"rate"`
+}
+
+type item struct {
+	Name string `json:"name"`
+}
+
+type sample struct {
+	embedded
+	Id       string    `json:"id"`
+	Secret   string    `json:"-"`
+	hidden   string
+	Count    int       `json:"count,omitempty"`
+	Untagged bool
+	Items    []*item   `json:"items"`
+	At       time.Time `json:"at"`
+	Raw      []byte    `json:"raw"`
+	Any      interface{}
+}
+
+func TestSchemas_Of(t *testing.T) {
+	s := NewSchemas()
+	s.Define(time.Time{}, &Schema{Type: "string", Format: "date-time"})
+
+	got := s.Of(reflect.TypeOf(&sample{}))
+	if want := (&Schema{Ref: "#/components/schemas/sample"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Of() got = %+v, want %+v", got, want)
+	}
+
+	want := map[string]*Schema{
+		"sample": {Type: "object", Properties: map[string]*Schema{
+			"rate":     {Type: "number"},
+			"id":       {Type: "string"},
+			"count":    {Type: "integer"},
+			"Untagged": {Type: "boolean"},
+			"items":    {Type: "array", Items: &Schema{Ref: "#/components/schemas/item"}},
+			"at":       {Type: "string", Format: "date-time"},
+			"raw":      {Type: "string", Format: "byte"},
+			"Any":      {},
+		}},
+		"item": {Type: "object", Properties: map[string]*Schema{
+			"name": {Type: "string"},
+		}},
+	}
+
+	if got := s.Components(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Components() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuilder_Add(t *testing.T) {
+	type envelope struct {
+		Data interface{} `json:"data"`
+	}
+
+	b := NewBuilder(Info{Title: "test", Version: "1"}, NewSchemas(), envelope{}, "data")
+	b.Add(Route{Method: "POST", Pattern: "/items/{id}", Summary: "Update item", Query: []string{"q"},
+		Headers: []string{"X-Key"}, Request: item{}, Response: []item{}})
+
+	op := b.Document().Paths["/items/{id}"]["post"]
+	if op == nil {
+		t.Fatalf("Document() has no post /items/{id} operation")
+	}
+
+	wantParams := []*Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "q", In: "query", Schema: &Schema{Type: "string"}},
+		{Name: "X-Key", In: "header", Schema: &Schema{Type: "string"}},
+	}
+	if !reflect.DeepEqual(op.Parameters, wantParams) {
+		t.Errorf("Add() parameters = %+v, want %+v", op.Parameters, wantParams)
+	}
+
+	ref := &Schema{Ref: "#/components/schemas/item"}
+	if got := op.RequestBody.Content["application/json"].Schema; !reflect.DeepEqual(got, ref) {
+		t.Errorf("Add() request schema = %+v, want %+v", got, ref)
+	}
+
+	wantOK := &Schema{AllOf: []*Schema{
+		{Ref: "#/components/schemas/envelope"},
+		{Type: "object", Properties: map[string]*Schema{"data": {Type: "array", Items: ref}}},
+	}}
+	if got := op.Responses["200"].Content["application/json"].Schema; !reflect.DeepEqual(got, wantOK) {
+		t.Errorf("Add() response schema = %+v, want %+v", got, wantOK)
+	}
+}
+
+func TestBuilder_Secure(t *testing.T) {
+	b := NewBuilder(Info{Title: "test", Version: "1"}, NewSchemas(), item{}, "data")
+	b.Secure(map[string]*SecurityScheme{
+		"token":  {Type: "http", Scheme: "bearer"},
+		"apiKey": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+	})
+	b.Add(Route{Method: "GET", Pattern: "/items", Summary: "List items", Response: []item{}})
+	b.Add(Route{Method: "GET", Pattern: "/health", Summary: "Check health", Response: item{}, Public: true})
+
+	doc := b.Document()
+
+	want := []SecurityRequirement{{"apiKey": {}}, {"token": {}}}
+	if got := doc.Paths["/items"]["get"].Security; !reflect.DeepEqual(got, want) {
+		t.Errorf("Secure() security = %+v, want %+v", got, want)
+	}
+
+	if got := doc.Paths["/health"]["get"].Security; got != nil {
+		t.Errorf("Secure() public route security = %+v, want nil", got)
+	}
+
+	if len(doc.Components.SecuritySchemes) != 2 {
+		t.Errorf("Secure() security schemes = %+v, want 2 of them", doc.Components.SecuritySchemes)
+	}
+}
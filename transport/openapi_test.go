@@ -0,0 +1,74 @@
+// +build !integration
+
+package restapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NickRI/wallets-task/internal/mock"
+	"github.com/NickRI/wallets-task/transport/restapi"
+	"github.com/go-chi/chi"
+	"github.com/go-kit/kit/log"
+	"github.com/golang/mock/gomock"
+)
+
+func Test_OpenAPIHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	routes := restapi.MakeRoutes(mock.NewMockWallet(ctrl), mock.NewMockWebhookService(ctrl), mock.NewMockReconciler(ctrl), restapi.NewHealth(0), nil, &restapi.Auth{}, log.NewNopLogger())
+
+	want, err := ioutil.ReadFile("../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	if !bytes.Equal(rr.Body.Bytes(), want) {
+		t.Errorf("served document differs from docs/openapi.json, run go run ./cmd/docgen")
+	}
+}
+
+func Test_Spec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	routes := restapi.MakeRoutes(mock.NewMockWallet(ctrl), mock.NewMockWebhookService(ctrl), mock.NewMockReconciler(ctrl), restapi.NewHealth(0), nil, &restapi.Auth{}, log.NewNopLogger())
+
+	spec, err := restapi.Spec(routes)
+	if err != nil {
+		t.Fatalf("Spec() error = %v", err)
+	}
+
+	err = chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route == "/openapi.json" {
+			return nil
+		}
+		pattern := strings.Replace(route, "/*/", "/", -1)
+		if _, ok := spec.Paths[pattern][strings.ToLower(method)]; !ok {
+			t.Errorf("Spec() misses %s %s", method, pattern)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	undescribed := chi.NewRouter()
+	undescribed.Get("/wallet/unknown", func(http.ResponseWriter, *http.Request) {})
+
+	if _, err := restapi.Spec(undescribed); err == nil || err.Error() != "route GET /wallet/unknown is not described" {
+		t.Errorf("Spec() error = %v, want undescribed route error", err)
+	}
+}
@@ -0,0 +1,78 @@
+package restapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/domain/services"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"golang.org/x/xerrors"
+)
+
+//APIKeyHeader carries API key of the caller, tokens are given in Authorization header with Bearer scheme
+const APIKeyHeader = "X-API-Key"
+
+const bearerPrefix = "Bearer "
+
+//Auth puts principal of the request credentials into its context, authenticator
+//left nil disables its kind of credentials. Nil Auth lets every request in
+type Auth struct {
+	APIKeys services.Authenticator
+	Tokens  services.Authenticator
+}
+
+//Authenticate rejects requests without valid credentials with 401
+func (a *Auth) Authenticate(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			p   *entities.Principal
+			err = error(models.UnauthenticatedError{xerrors.New("authentication required")})
+		)
+
+		authorization := r.Header.Get("Authorization")
+
+		switch {
+		case a.APIKeys != nil && r.Header.Get(APIKeyHeader) != "":
+			p, err = a.APIKeys.Authenticate(r.Context(), r.Header.Get(APIKeyHeader))
+		case a.Tokens != nil && strings.HasPrefix(authorization, bearerPrefix):
+			p, err = a.Tokens.Authenticate(r.Context(), strings.TrimPrefix(authorization, bearerPrefix))
+		}
+
+		if err != nil {
+			endpoints.ErrorEncoder(r.Context(), err, w)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(entities.ContextWithPrincipal(r.Context(), p)))
+	})
+}
+
+//RequireScope rejects authenticated requests whose principal lacks the scope with 403
+func (a *Auth) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := entities.PrincipalFromContext(r.Context())
+			if !ok {
+				endpoints.ErrorEncoder(r.Context(), models.UnauthenticatedError{xerrors.New("authentication required")}, w)
+				return
+			}
+
+			if !p.HasScope(scope) {
+				endpoints.ErrorEncoder(r.Context(), models.ForbiddenError{xerrors.Errorf("%s scope is required", scope)}, w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -10,16 +10,90 @@ import (
 
 // Handlers holds all go-kit handlers for the service.
 type Handlers struct {
-	Send         http.Handler
-	ListLedgers  http.Handler
-	ListAccounts http.Handler
+	Send                http.Handler
+	CreateBatch         http.Handler
+	ListLedgers         http.Handler
+	GetLedger           http.Handler
+	RefundLedger        http.Handler
+	ListAccounts        http.Handler
+	CreateAccount       http.Handler
+	GetAccount          http.Handler
+	AccountBalance      http.Handler
+	AccountLimits       http.Handler
+	ChangeAccountStatus http.Handler
+	AccountLedgers      http.Handler
+	Deposit             http.Handler
+	Withdraw            http.Handler
+	CreateQuote         http.Handler
+	AuthorizeHold       http.Handler
+	GetHold             http.Handler
+	CaptureHold         http.Handler
+	VoidHold            http.Handler
 }
 
 // MakeHandlers initializes all go-kit handlers for the service.
 func MakeHandlers(ws services.Wallet, options ...kithttp.ServerOption) Handlers {
+	return makeHandlers(ws, nil, options...)
+}
+
+func makeHandlers(ws services.Wallet, i endpoints.Instrument, options ...kithttp.ServerOption) Handlers {
 	return Handlers{
-		Send:         kithttp.NewServer(endpoints.PaymentSend(ws), endpoints.PaymentDecoder, endpoints.EncodeResponse, options...),
-		ListLedgers:  kithttp.NewServer(endpoints.LedgerList(ws), endpoints.NopDecoder, endpoints.EncodeResponse, options...),
-		ListAccounts: kithttp.NewServer(endpoints.AccountList(ws), endpoints.NopDecoder, endpoints.EncodeResponse, options...),
+		Send:                kithttp.NewServer(i.Wrap("Send", endpoints.PaymentSend(ws)), endpoints.PaymentDecoder, endpoints.EncodeResponse, options...),
+		CreateBatch:         kithttp.NewServer(i.Wrap("CreateBatch", endpoints.BatchCreate(ws)), endpoints.BatchDecoder, endpoints.EncodeResponse, options...),
+		ListLedgers:         kithttp.NewServer(i.Wrap("ListLedgers", endpoints.LedgerList(ws)), endpoints.LedgerListDecoder, endpoints.EncodeResponse, options...),
+		GetLedger:           kithttp.NewServer(i.Wrap("GetLedger", endpoints.LedgerGet(ws)), endpoints.LedgerDecoder, endpoints.EncodeResponse, options...),
+		RefundLedger:        kithttp.NewServer(i.Wrap("RefundLedger", endpoints.LedgerRefund(ws)), endpoints.RefundDecoder, endpoints.EncodeResponse, options...),
+		ListAccounts:        kithttp.NewServer(i.Wrap("ListAccounts", endpoints.AccountList(ws)), endpoints.NopDecoder, endpoints.EncodeResponse, options...),
+		CreateAccount:       kithttp.NewServer(i.Wrap("CreateAccount", endpoints.AccountCreate(ws)), endpoints.CreateAccountDecoder, endpoints.EncodeResponse, options...),
+		GetAccount:          kithttp.NewServer(i.Wrap("GetAccount", endpoints.AccountGet(ws)), endpoints.AccountDecoder, endpoints.EncodeResponse, options...),
+		AccountBalance:      kithttp.NewServer(i.Wrap("AccountBalance", endpoints.AccountBalance(ws)), endpoints.BalanceDecoder, endpoints.EncodeResponse, options...),
+		AccountLimits:       kithttp.NewServer(i.Wrap("AccountLimits", endpoints.AccountLimits(ws)), endpoints.AccountDecoder, endpoints.EncodeResponse, options...),
+		ChangeAccountStatus: kithttp.NewServer(i.Wrap("ChangeAccountStatus", endpoints.AccountStatusChange(ws)), endpoints.AccountStatusDecoder, endpoints.EncodeResponse, options...),
+		AccountLedgers:      kithttp.NewServer(i.Wrap("AccountLedgers", endpoints.AccountLedgers(ws)), endpoints.AccountLedgersDecoder, endpoints.EncodeResponse, options...),
+		Deposit:             kithttp.NewServer(i.Wrap("Deposit", endpoints.AccountDeposit(ws)), endpoints.FundsDecoder, endpoints.EncodeResponse, options...),
+		Withdraw:            kithttp.NewServer(i.Wrap("Withdraw", endpoints.AccountWithdraw(ws)), endpoints.FundsDecoder, endpoints.EncodeResponse, options...),
+		CreateQuote:         kithttp.NewServer(i.Wrap("CreateQuote", endpoints.QuoteCreate(ws)), endpoints.CreateQuoteDecoder, endpoints.EncodeResponse, options...),
+		AuthorizeHold:       kithttp.NewServer(i.Wrap("AuthorizeHold", endpoints.HoldAuthorize(ws)), endpoints.AuthorizeDecoder, endpoints.EncodeResponse, options...),
+		GetHold:             kithttp.NewServer(i.Wrap("GetHold", endpoints.HoldGet(ws)), endpoints.HoldDecoder, endpoints.EncodeResponse, options...),
+		CaptureHold:         kithttp.NewServer(i.Wrap("CaptureHold", endpoints.HoldCapture(ws)), endpoints.CaptureDecoder, endpoints.EncodeResponse, options...),
+		VoidHold:            kithttp.NewServer(i.Wrap("VoidHold", endpoints.HoldVoid(ws)), endpoints.HoldDecoder, endpoints.EncodeResponse, options...),
+	}
+}
+
+// AdminHandlers holds go-kit handlers for the operators of the service.
+type AdminHandlers struct {
+	Reconciliation http.Handler
+}
+
+// MakeAdminHandlers initializes admin go-kit handlers for the service.
+func MakeAdminHandlers(rc services.Reconciler, options ...kithttp.ServerOption) AdminHandlers {
+	return makeAdminHandlers(rc, nil, options...)
+}
+
+func makeAdminHandlers(rc services.Reconciler, i endpoints.Instrument, options ...kithttp.ServerOption) AdminHandlers {
+	return AdminHandlers{
+		Reconciliation: kithttp.NewServer(i.Wrap("Reconciliation", endpoints.ReconciliationGet(rc)), endpoints.NopDecoder, endpoints.EncodeResponse, options...),
+	}
+}
+
+// WebhookHandlers holds go-kit handlers for webhook subscriptions.
+type WebhookHandlers struct {
+	CreateWebhook     http.Handler
+	ListWebhooks      http.Handler
+	DeleteWebhook     http.Handler
+	WebhookDeliveries http.Handler
+}
+
+// MakeWebhookHandlers initializes webhook subscription go-kit handlers for the service.
+func MakeWebhookHandlers(ws services.Webhooks, options ...kithttp.ServerOption) WebhookHandlers {
+	return makeWebhookHandlers(ws, nil, options...)
+}
+
+func makeWebhookHandlers(ws services.Webhooks, i endpoints.Instrument, options ...kithttp.ServerOption) WebhookHandlers {
+	return WebhookHandlers{
+		CreateWebhook:     kithttp.NewServer(i.Wrap("CreateWebhook", endpoints.WebhookCreate(ws)), endpoints.CreateWebhookDecoder, endpoints.EncodeResponse, options...),
+		ListWebhooks:      kithttp.NewServer(i.Wrap("ListWebhooks", endpoints.WebhookList(ws)), endpoints.NopDecoder, endpoints.EncodeResponse, options...),
+		DeleteWebhook:     kithttp.NewServer(i.Wrap("DeleteWebhook", endpoints.WebhookDelete(ws)), endpoints.WebhookDecoder, endpoints.EncodeResponse, options...),
+		WebhookDeliveries: kithttp.NewServer(i.Wrap("WebhookDeliveries", endpoints.WebhookDeliveries(ws)), endpoints.WebhookDeliveriesDecoder, endpoints.EncodeResponse, options...),
 	}
 }
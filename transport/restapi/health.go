@@ -0,0 +1,99 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/NickRI/wallets-task/db/models"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"golang.org/x/xerrors"
+)
+
+const (
+	livenessPattern  = "/healthz"
+	readinessPattern = "/readyz"
+)
+
+//HealthStatus reports status of every readiness check
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+//Check returns status of a dependency the service needs to serve requests, error means it isn't ready
+type Check func(ctx context.Context) (string, error)
+
+//Health serves liveness and readiness probes, instance is ready while every check
+//passes and it isn't draining before shutdown
+type Health struct {
+	draining int32
+	timeout  time.Duration
+	names    []string
+	checks   map[string]Check
+}
+
+//NewHealth makes probes which give up readiness checks after timeout, zero timeout waits for them as long as the request does
+func NewHealth(timeout time.Duration) *Health {
+	return &Health{timeout: timeout, checks: map[string]Check{}}
+}
+
+//AddCheck adds readiness check reported under name, checks are run in order they are added
+func (h *Health) AddCheck(name string, c Check) *Health {
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = c
+
+	return h
+}
+
+//Drain makes readiness fail, so load balancer stops sending new requests while the running ones are finished
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+func (h *Health) Draining() bool {
+	return atomic.LoadInt32(&h.draining) == 1
+}
+
+//Live tells the process is up and serving http
+func (h *Health) Live(w http.ResponseWriter, r *http.Request) {
+	endpoints.EncodeResponse(r.Context(), w, &HealthStatus{Status: "alive"})
+}
+
+//Ready tells the instance can serve requests, it responds with 503 listing failed checks otherwise
+func (h *Health) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.Draining() {
+		endpoints.ErrorEncoder(r.Context(), models.UnavailableError{xerrors.New("not ready: draining")}, w)
+		return
+	}
+
+	ctx := r.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	status := &HealthStatus{Status: "ready", Checks: map[string]string{}}
+
+	var failed []string
+	for _, name := range h.names {
+		result, err := h.checks[name](ctx)
+		if err != nil {
+			failed = append(failed, name+": "+err.Error())
+			continue
+		}
+		status.Checks[name] = result
+	}
+
+	if len(failed) > 0 {
+		endpoints.ErrorEncoder(r.Context(), models.UnavailableError{xerrors.Errorf("not ready: %s", strings.Join(failed, "; "))}, w)
+		return
+	}
+
+	endpoints.EncodeResponse(r.Context(), w, status)
+}
@@ -0,0 +1,15 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/NickRI/wallets-task/transport/endpoints"
+)
+
+const metricsPattern = "/metrics"
+
+//Metrics instruments endpoints of the routes, Handler serves collected metrics on /metrics
+type Metrics struct {
+	Instrument endpoints.Instrument
+	Handler    http.Handler
+}
@@ -0,0 +1,144 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NickRI/wallets-task/domain/entities"
+	"github.com/NickRI/wallets-task/transport/endpoints"
+	"github.com/NickRI/wallets-task/transport/openapi"
+	"github.com/go-chi/chi"
+	"github.com/shopspring/decimal"
+	"golang.org/x/xerrors"
+)
+
+const openAPIPattern = "/openapi.json"
+
+var ledgersQuery = []string{"account", "counterparty", "direction", "type", "min_amount", "max_amount", "from", "to", "cursor", "limit"}
+
+//apiRoutes describes every route of MakeRoutes, Spec fails on a route which is missing here
+var apiRoutes = []openapi.Route{
+	{Method: "POST", Pattern: "/wallet/pay/{sender}/{receiver}", Summary: "Send money from sender to receiver",
+		Headers: []string{endpoints.IdempotencyKeyHeader}, Request: endpoints.SendRequest{}, Response: entities.Ledger{}},
+	{Method: "POST", Pattern: "/wallet/batches", Summary: "Make all-or-nothing batch of transfers",
+		Request: endpoints.BatchRequest{}, Response: entities.Batch{}},
+	{Method: "GET", Pattern: "/wallet/ledgers", Summary: "List ledgers", Query: ledgersQuery, Response: entities.LedgersPage{}},
+	{Method: "GET", Pattern: "/wallet/ledgers/{guid}", Summary: "Get ledger", Response: entities.Ledger{}},
+	{Method: "POST", Pattern: "/wallet/ledgers/{guid}/refund", Summary: "Refund ledger fully or partially",
+		Request: endpoints.RefundRequest{}, Response: entities.Ledger{}},
+	{Method: "GET", Pattern: "/wallet/accounts", Summary: "List accounts", Response: entities.Accounts{}},
+	{Method: "POST", Pattern: "/wallet/accounts", Summary: "Create account",
+		Request: endpoints.CreateAccountRequest{}, Response: entities.Account{}},
+	{Method: "GET", Pattern: "/wallet/accounts/{id}", Summary: "Get account", Response: entities.Account{}},
+	{Method: "GET", Pattern: "/wallet/accounts/{id}/balance", Summary: "Get account balance at the moment",
+		Query: []string{"at"}, Response: entities.AccountBalance{}},
+	{Method: "GET", Pattern: "/wallet/accounts/{id}/limits", Summary: "Get what is left of account limits", Response: entities.Allowance{}},
+	{Method: "PUT", Pattern: "/wallet/accounts/{id}/status", Summary: "Change account status",
+		Request: endpoints.AccountStatusRequest{}, Response: entities.Account{}},
+	{Method: "GET", Pattern: "/wallet/accounts/{id}/ledgers", Summary: "List account payments",
+		Query: []string{"cursor", "limit"}, Response: entities.PaymentsPage{}},
+	{Method: "POST", Pattern: "/wallet/accounts/{id}/deposit", Summary: "Deposit money to account",
+		Headers: []string{endpoints.IdempotencyKeyHeader}, Request: endpoints.FundsRequest{}, Response: entities.Ledger{}},
+	{Method: "POST", Pattern: "/wallet/accounts/{id}/withdraw", Summary: "Withdraw money from account",
+		Headers: []string{endpoints.IdempotencyKeyHeader}, Request: endpoints.FundsRequest{}, Response: entities.Ledger{}},
+	{Method: "POST", Pattern: "/wallet/quotes", Summary: "Quote currency conversion",
+		Request: endpoints.CreateQuoteRequest{}, Response: entities.FXQuote{}},
+	{Method: "POST", Pattern: "/wallet/holds", Summary: "Authorize hold of funds",
+		Request: endpoints.AuthorizeRequest{}, Response: entities.Hold{}},
+	{Method: "GET", Pattern: "/wallet/holds/{id}", Summary: "Get hold", Response: entities.Hold{}},
+	{Method: "POST", Pattern: "/wallet/holds/{id}/capture", Summary: "Capture hold fully or partially",
+		Request: endpoints.CaptureRequest{}, Response: entities.Ledger{}},
+	{Method: "POST", Pattern: "/wallet/holds/{id}/void", Summary: "Void hold", Response: entities.Hold{}},
+	{Method: "POST", Pattern: "/wallet/webhooks", Summary: "Subscribe webhook to events",
+		Request: endpoints.CreateWebhookRequest{}, Response: entities.Webhook{}},
+	{Method: "GET", Pattern: "/wallet/webhooks", Summary: "List webhooks", Response: entities.Webhooks{}},
+	{Method: "DELETE", Pattern: "/wallet/webhooks/{id}", Summary: "Deactivate webhook", Response: entities.Webhook{}},
+	{Method: "GET", Pattern: "/wallet/webhooks/{id}/deliveries", Summary: "List latest deliveries of webhook",
+		Query: []string{"status"}, Response: entities.Deliveries{}},
+	{Method: "GET", Pattern: "/admin/reconciliation", Summary: "Get latest reconciliation report", Response: entities.ReconciliationReport{}},
+	{Method: "GET", Pattern: livenessPattern, Summary: "Check the process is alive", Response: HealthStatus{}, Public: true},
+	{Method: "GET", Pattern: readinessPattern, Summary: "Check the instance is ready to serve requests", Response: HealthStatus{}, Public: true},
+}
+
+//Spec builds OpenAPI document of the json routes, every one of them has to be described in apiRoutes
+func Spec(routes chi.Routes) (*openapi.Document, error) {
+	described := map[string]openapi.Route{}
+	for _, r := range apiRoutes {
+		described[r.Method+" "+r.Pattern] = r
+	}
+
+	builder := openapi.NewBuilder(openapi.Info{Title: "Wallet service", Version: "1.0.0"}, schemas(), endpoints.Response{}, "data")
+	builder.Secure(map[string]*openapi.SecurityScheme{
+		"apiKey":     {Type: "apiKey", Name: APIKeyHeader, In: "header"},
+		"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	})
+
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		pattern := strings.Replace(route, "/*/", "/", -1)
+		if pattern == openAPIPattern || pattern == metricsPattern {
+			return nil
+		}
+
+		r, ok := described[method+" "+pattern]
+		if !ok {
+			return xerrors.Errorf("route %s %s is not described", method, pattern)
+		}
+
+		builder.Add(r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.Document(), nil
+}
+
+//schemas knows the types marshaled to json by their own methods, decimals are written in the money format
+func schemas() *openapi.Schemas {
+	amount := &openapi.Schema{Type: "number"}
+	if entities.CurrentMoneyFormat() == entities.MoneyAsString {
+		amount = &openapi.Schema{Type: "string", Format: "decimal"}
+	}
+
+	s := openapi.NewSchemas()
+	s.Define(decimal.Decimal{}, amount)
+	s.Define(time.Time{}, &openapi.Schema{Type: "string", Format: "date-time"})
+	s.Define(json.RawMessage{}, &openapi.Schema{Type: "object"})
+	s.Define(endpoints.Response{}.Error, &openapi.Schema{Type: "string", Nullable: true})
+	s.Define(entities.Direction(0), &openapi.Schema{Type: "string", Enum: []string{"outgoing", "incoming"}})
+	s.Define(entities.PaymentType(""), &openapi.Schema{Type: "string", Enum: []string{"transfer", "deposit", "withdrawal", "refund"}})
+	s.Define(entities.AccountStatus(""), &openapi.Schema{Type: "string", Enum: []string{"active", "frozen", "closed"}})
+	s.Define(entities.HoldStatus(""), &openapi.Schema{Type: "string", Enum: []string{"active", "captured", "voided", "expired"}})
+	s.Define(entities.DeliveryStatus(""), &openapi.Schema{Type: "string", Enum: []string{"pending", "delivered", "dead"}})
+	return s
+}
+
+//openAPIHandler serves document of routes, it is built on the first request when all the routes are mounted
+func openAPIHandler(routes chi.Routes) http.HandlerFunc {
+	var (
+		once sync.Once
+		doc  []byte
+		err  error
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			var spec *openapi.Document
+			if spec, err = Spec(routes); err == nil {
+				doc, err = json.MarshalIndent(spec, "", "  ")
+			}
+		})
+
+		if err != nil {
+			endpoints.ErrorEncoder(r.Context(), err, w)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(doc)
+	}
+}
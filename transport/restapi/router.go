@@ -1,6 +1,7 @@
 package restapi
 
 import (
+	"github.com/NickRI/wallets-task/domain/entities"
 	"github.com/NickRI/wallets-task/domain/services"
 	"github.com/NickRI/wallets-task/transport/endpoints"
 	"github.com/go-chi/chi"
@@ -9,7 +10,11 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 )
 
-func MakeRoutes(w services.Wallet, l log.Logger) *chi.Mux {
+//MakeRoutes mounts webhook routes only when webhooks service is given, admin routes only when reconciler is given,
+//probes only when health is given and instruments endpoints only when metrics are given. Wallet routes require
+//authentication when auth is given, webhook and admin ones the admin scope too, probes and documents stay public.
+//Webhook and admin routes aren't mounted without auth, nobody could be checked for the admin scope
+func MakeRoutes(w services.Wallet, wh services.Webhooks, rc services.Reconciler, h *Health, m *Metrics, a *Auth, l log.Logger) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -21,14 +26,68 @@ func MakeRoutes(w services.Wallet, l log.Logger) *chi.Mux {
 		kithttp.ServerErrorEncoder(endpoints.ErrorEncoder),
 	}
 
-	handlers := MakeHandlers(w, options...)
+	var instrument endpoints.Instrument
+	if m != nil {
+		instrument = m.Instrument
+	}
+
+	handlers := makeHandlers(w, instrument, options...)
 
 	// RESTy routes for "wallet" resource
 	r.Route("/wallet/", func(r chi.Router) {
+		r.Use(a.Authenticate)
+
 		r.Post("/pay/{sender}/{receiver}", handlers.Send.ServeHTTP)
+		r.Post("/batches", handlers.CreateBatch.ServeHTTP)
 		r.Get("/ledgers", handlers.ListLedgers.ServeHTTP)
+		r.Get("/ledgers/{guid}", handlers.GetLedger.ServeHTTP)
+		r.Post("/ledgers/{guid}/refund", handlers.RefundLedger.ServeHTTP)
 		r.Get("/accounts", handlers.ListAccounts.ServeHTTP)
+		r.Post("/accounts", handlers.CreateAccount.ServeHTTP)
+		r.Get("/accounts/{id}", handlers.GetAccount.ServeHTTP)
+		r.Get("/accounts/{id}/balance", handlers.AccountBalance.ServeHTTP)
+		r.Get("/accounts/{id}/limits", handlers.AccountLimits.ServeHTTP)
+		r.Put("/accounts/{id}/status", handlers.ChangeAccountStatus.ServeHTTP)
+		r.Get("/accounts/{id}/ledgers", handlers.AccountLedgers.ServeHTTP)
+		r.Post("/accounts/{id}/deposit", handlers.Deposit.ServeHTTP)
+		r.Post("/accounts/{id}/withdraw", handlers.Withdraw.ServeHTTP)
+		r.Post("/quotes", handlers.CreateQuote.ServeHTTP)
+		r.Post("/holds", handlers.AuthorizeHold.ServeHTTP)
+		r.Get("/holds/{id}", handlers.GetHold.ServeHTTP)
+		r.Post("/holds/{id}/capture", handlers.CaptureHold.ServeHTTP)
+		r.Post("/holds/{id}/void", handlers.VoidHold.ServeHTTP)
+
+		if wh != nil && a != nil {
+			webhooks := makeWebhookHandlers(wh, instrument, options...)
+			r := r.With(a.RequireScope(entities.AdminScope))
+
+			r.Post("/webhooks", webhooks.CreateWebhook.ServeHTTP)
+			r.Get("/webhooks", webhooks.ListWebhooks.ServeHTTP)
+			r.Delete("/webhooks/{id}", webhooks.DeleteWebhook.ServeHTTP)
+			r.Get("/webhooks/{id}/deliveries", webhooks.WebhookDeliveries.ServeHTTP)
+		}
 	})
 
+	if rc != nil && a != nil {
+		admin := makeAdminHandlers(rc, instrument, options...)
+
+		r.Route("/admin/", func(r chi.Router) {
+			r.Use(a.Authenticate, a.RequireScope(entities.AdminScope))
+
+			r.Get("/reconciliation", admin.Reconciliation.ServeHTTP)
+		})
+	}
+
+	if h != nil {
+		r.Get(livenessPattern, h.Live)
+		r.Get(readinessPattern, h.Ready)
+	}
+
+	if m != nil {
+		r.Get(metricsPattern, m.Handler.ServeHTTP)
+	}
+
+	r.Get(openAPIPattern, openAPIHandler(r))
+
 	return r
 }
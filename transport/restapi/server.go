@@ -7,8 +7,6 @@ import (
 	"time"
 )
 
-const shutdownTimeout = 5 * time.Second
-
 type Server struct {
 	http.Server
 }
@@ -32,9 +30,14 @@ func (o *Server) Run() *Server {
 	return o
 }
 
-func (o *Server) Shutdown() {
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
+//Shutdown waits up to timeout for the running requests to finish, zero timeout waits for them as long as it takes
+func (o *Server) Shutdown(timeout time.Duration) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	if err := o.Server.Shutdown(ctx); err != nil {
 		log.Println("failed to shutdown HTTP server")